@@ -32,6 +32,10 @@ func SetDefaults_StatefulSet(obj *StatefulSet) {
 		*obj.Spec.UpdateStrategy.RollingUpdate.Partition = 0
 	}
 
+	if obj.Spec.VolumeClaimUpdateStrategy.Type == "" {
+		obj.Spec.VolumeClaimUpdateStrategy.Type = OnDeleteVolumeClaimUpdateStrategyType
+	}
+
 	if obj.Spec.Replicas == nil {
 		obj.Spec.Replicas = new(int32)
 		*obj.Spec.Replicas = 1
@@ -30,6 +30,30 @@ const (
 	// We use an annotation instead of a field in the status
 	// so that we can convert between the K8s built-in StatefulSet and ours.
 	PausedReconcileAnn = "paused-reconcile"
+
+	// RollbackToAnn is the annotation key for rolling back to a previous
+	// ControllerRevision. The value is either the name of the revision or its
+	// revision number. The controller restores the pod template from that
+	// revision, removes the annotation and starts a normal rolling update.
+	RollbackToAnn = "rollback-to"
+
+	// FrozenOrdinalsAnn is the annotation key for the frozen ordinals of a
+	// set. The value is a JSON array of ordinals, e.g. "[1,3]". The Pods of
+	// frozen ordinals are excluded from reconciliation: they are neither
+	// created, recreated, updated nor deleted by the controller and they do
+	// not block the other ordinals.
+	FrozenOrdinalsAnn = "frozen-ordinals"
+
+	// FrozenAnn is the annotation key to freeze the ordinal of a single Pod.
+	// If the value is "true", the ordinal of the Pod is frozen like the
+	// ordinals in FrozenOrdinalsAnn.
+	FrozenAnn = "frozen"
+
+	// PruneDeleteSlotsAnn is the annotation key to opt in to the pruning of
+	// the delete slots annotation. If the value is "true", the controller
+	// removes the delete slots which are ignored because they are at or above
+	// the max replica count, see GetIgnoredDeleteSlots.
+	PruneDeleteSlotsAnn = "prune-delete-slots"
 )
 
 func GetDeleteSlots(set metav1.Object) (deleteSlots sets.Int32) {
@@ -94,6 +118,20 @@ func GetMaxReplicaCountAndDeleteSlots(replicas int32, deleteSlots sets.Int32) (i
 	return replicaCount, deleteSlotsCopy
 }
 
+// GetIgnoredDeleteSlots returns the delete slots which are dropped by
+// GetMaxReplicaCountAndDeleteSlots because they are at or above the max
+// replica count. They do not affect the desired slots of the stateful set.
+func GetIgnoredDeleteSlots(replicas int32, deleteSlots sets.Int32) sets.Int32 {
+	_, effective := GetMaxReplicaCountAndDeleteSlots(replicas, deleteSlots)
+	return deleteSlots.Difference(effective)
+}
+
+// GetPruneDeleteSlots returns true if the ignored delete slots of the set
+// should be removed from its annotation.
+func GetPruneDeleteSlots(set metav1.Object) bool {
+	return set.GetAnnotations()[PruneDeleteSlotsAnn] == "true"
+}
+
 func SetPausedReconcile(set metav1.Object, paused bool) {
 	annotations := set.GetAnnotations()
 	if annotations == nil {
@@ -119,6 +157,95 @@ func GetPausedReconcile(set metav1.Object) bool {
 	return value == "true"
 }
 
+// SetRollbackTo sets the revision (name or number) the set should be rolled
+// back to.
+func SetRollbackTo(set metav1.Object, revision string) {
+	annotations := set.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[RollbackToAnn] = revision
+	set.SetAnnotations(annotations)
+}
+
+// GetRollbackTo returns the requested rollback revision and whether a
+// rollback has been requested.
+func GetRollbackTo(set metav1.Object) (string, bool) {
+	annotations := set.GetAnnotations()
+	if annotations == nil {
+		return "", false
+	}
+	value, ok := annotations[RollbackToAnn]
+	if !ok || value == "" {
+		return "", false
+	}
+	return value, true
+}
+
+// ClearRollbackTo removes the rollback request from the set.
+func ClearRollbackTo(set metav1.Object) {
+	annotations := set.GetAnnotations()
+	if annotations == nil {
+		return
+	}
+	delete(annotations, RollbackToAnn)
+	set.SetAnnotations(annotations)
+}
+
+// GetFrozenOrdinals returns the ordinals frozen by the annotation of the set.
+func GetFrozenOrdinals(set metav1.Object) sets.Int32 {
+	frozen := sets.NewInt32()
+	value, ok := set.GetAnnotations()[FrozenOrdinalsAnn]
+	if !ok {
+		return frozen
+	}
+	var slice []int32
+	if err := json.Unmarshal([]byte(value), &slice); err != nil {
+		return frozen
+	}
+	return frozen.Insert(slice...)
+}
+
+// SetFrozenOrdinals sets the frozen ordinals of the set. The annotation is
+// removed if frozen is empty.
+func SetFrozenOrdinals(set metav1.Object, frozen sets.Int32) error {
+	annotations := set.GetAnnotations()
+	if frozen.Len() == 0 {
+		delete(annotations, FrozenOrdinalsAnn)
+		set.SetAnnotations(annotations)
+		return nil
+	}
+	b, err := json.Marshal(frozen.List())
+	if err != nil {
+		return err
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[FrozenOrdinalsAnn] = string(b)
+	set.SetAnnotations(annotations)
+	return nil
+}
+
+// SetFrozen freezes or unfreezes the ordinal of the pod.
+func SetFrozen(pod metav1.Object, frozen bool) {
+	annotations := pod.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if frozen {
+		annotations[FrozenAnn] = "true"
+	} else {
+		delete(annotations, FrozenAnn)
+	}
+	pod.SetAnnotations(annotations)
+}
+
+// IsFrozen returns true if the ordinal of the pod is frozen by its annotation.
+func IsFrozen(pod metav1.Object) bool {
+	return pod.GetAnnotations()[FrozenAnn] == "true"
+}
+
 func GetPodOrdinals(replicas int32, set metav1.Object) sets.Int32 {
 	return GetPodOrdinalsFromReplicasAndDeleteSlots(replicas, GetDeleteSlots(set))
 }
@@ -302,6 +302,122 @@ func TestGetPausedReconcile(t *testing.T) {
 	}
 }
 
+func TestRollbackTo(t *testing.T) {
+	sts := asappsv1.StatefulSet{}
+	if _, ok := GetRollbackTo(&sts); ok {
+		t.Errorf("GetRollbackTo want no rollback requested")
+	}
+	SetRollbackTo(&sts, "3")
+	if got, ok := GetRollbackTo(&sts); !ok || got != "3" {
+		t.Errorf("GetRollbackTo want 3 got %q (requested: %v)", got, ok)
+	}
+	ClearRollbackTo(&sts)
+	if _, ok := GetRollbackTo(&sts); ok {
+		t.Errorf("GetRollbackTo want no rollback requested after clear")
+	}
+	want := asappsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{},
+		},
+	}
+	if diff := cmp.Diff(want, sts); diff != "" {
+		t.Errorf("unexpected result (-want, +got): %s", diff)
+	}
+}
+
+func TestFrozenOrdinals(t *testing.T) {
+	sts := asappsv1.StatefulSet{}
+	if got := GetFrozenOrdinals(&sts); got.Len() != 0 {
+		t.Errorf("GetFrozenOrdinals want no frozen ordinals got %v", got.List())
+	}
+	if err := SetFrozenOrdinals(&sts, sets.NewInt32(3, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if got := sts.Annotations[FrozenOrdinalsAnn]; got != "[1,3]" {
+		t.Errorf("want annotation [1,3] got %q", got)
+	}
+	if got := GetFrozenOrdinals(&sts); !got.Equal(sets.NewInt32(1, 3)) {
+		t.Errorf("GetFrozenOrdinals want [1 3] got %v", got.List())
+	}
+	if err := SetFrozenOrdinals(&sts, sets.NewInt32()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sts.Annotations[FrozenOrdinalsAnn]; ok {
+		t.Errorf("annotation should be removed")
+	}
+	sts.Annotations[FrozenOrdinalsAnn] = "invalid"
+	if got := GetFrozenOrdinals(&sts); got.Len() != 0 {
+		t.Errorf("GetFrozenOrdinals want no frozen ordinals for an invalid annotation got %v", got.List())
+	}
+}
+
+func TestFrozen(t *testing.T) {
+	pod := metav1.ObjectMeta{}
+	if IsFrozen(&pod) {
+		t.Errorf("IsFrozen want false")
+	}
+	SetFrozen(&pod, true)
+	if !IsFrozen(&pod) {
+		t.Errorf("IsFrozen want true")
+	}
+	SetFrozen(&pod, false)
+	if IsFrozen(&pod) {
+		t.Errorf("IsFrozen want false after unfreeze")
+	}
+}
+
+func TestGetIgnoredDeleteSlots(t *testing.T) {
+	tests := []struct {
+		name        string
+		replicas    int32
+		deleteSlots sets.Int32
+		want        sets.Int32
+	}{
+		{
+			name:        "no delete slots",
+			replicas:    3,
+			deleteSlots: sets.NewInt32(),
+			want:        sets.NewInt32(),
+		},
+		{
+			name:        "delete slots in [0, replicas)",
+			replicas:    3,
+			deleteSlots: sets.NewInt32(0, 2),
+			want:        sets.NewInt32(),
+		},
+		{
+			name:        "delete slots shifting the max replica count",
+			replicas:    3,
+			deleteSlots: sets.NewInt32(1, 3, 4, 6),
+			want:        sets.NewInt32(6),
+		},
+		{
+			name:        "delete slots not in [0, replicas)",
+			replicas:    3,
+			deleteSlots: sets.NewInt32(4, 5),
+			want:        sets.NewInt32(4, 5),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetIgnoredDeleteSlots(tt.replicas, tt.deleteSlots); !got.Equal(tt.want) {
+				t.Errorf("GetIgnoredDeleteSlots want %v got %v", tt.want.List(), got.List())
+			}
+		})
+	}
+}
+
+func TestGetPruneDeleteSlots(t *testing.T) {
+	sts := asappsv1.StatefulSet{}
+	if GetPruneDeleteSlots(&sts) {
+		t.Errorf("GetPruneDeleteSlots want false")
+	}
+	sts.Annotations = map[string]string{PruneDeleteSlotsAnn: "true"}
+	if !GetPruneDeleteSlots(&sts) {
+		t.Errorf("GetPruneDeleteSlots want true")
+	}
+}
+
 func int32ptr(i int32) *int32 {
 	return &i
 }
@@ -0,0 +1,46 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"context"
+	"encoding/json"
+
+	asv1 "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	asclientset "github.com/pingcap/advanced-statefulset/client/client/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Rollback requests the controller to roll the Advanced StatefulSet back to
+// the given ControllerRevision. revision is either the name of the revision
+// or its revision number, e.g. "web-6c5d8f7b9" or "3".
+//
+// The request is recorded in the RollbackToAnn annotation. The controller
+// restores the pod template from the revision, removes the annotation and
+// performs a normal rolling update.
+func Rollback(ctx context.Context, asc asclientset.Interface, namespace, name, revision string) (*asv1.StatefulSet, error) {
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				RollbackToAnn: revision,
+			},
+		},
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	return asc.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.MergePatchType, data, metav1.PatchOptions{})
+}
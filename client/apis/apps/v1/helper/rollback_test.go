@@ -0,0 +1,46 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helper
+
+import (
+	"context"
+	"testing"
+
+	asappsv1 "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	asfake "github.com/pingcap/advanced-statefulset/client/client/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRollback(t *testing.T) {
+	sts := &asappsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sts",
+			Namespace: "default",
+			Annotations: map[string]string{
+				DeleteSlotsAnn: "[1]",
+			},
+		},
+	}
+	asc := asfake.NewSimpleClientset(sts)
+	got, err := Rollback(context.TODO(), asc, sts.Namespace, sts.Name, "sts-5d8f7b9c6")
+	if err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if revision, ok := GetRollbackTo(got); !ok || revision != "sts-5d8f7b9c6" {
+		t.Errorf("GetRollbackTo want sts-5d8f7b9c6 got %q (requested: %v)", revision, ok)
+	}
+	if got.Annotations[DeleteSlotsAnn] != "[1]" {
+		t.Errorf("Rollback must keep other annotations, got %v", got.Annotations)
+	}
+}
@@ -30,13 +30,96 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.RollingUpdateStatefulSetStrategy": schema_client_apis_apps_v1_RollingUpdateStatefulSetStrategy(ref),
-		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSet":                      schema_client_apis_apps_v1_StatefulSet(ref),
-		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetCondition":             schema_client_apis_apps_v1_StatefulSetCondition(ref),
-		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetList":                  schema_client_apis_apps_v1_StatefulSetList(ref),
-		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetSpec":                  schema_client_apis_apps_v1_StatefulSetSpec(ref),
-		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetStatus":                schema_client_apis_apps_v1_StatefulSetStatus(ref),
-		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetUpdateStrategy":        schema_client_apis_apps_v1_StatefulSetUpdateStrategy(ref),
+		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.OrdinalOverride":                      schema_client_apis_apps_v1_OrdinalOverride(ref),
+		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.RecreateBackoff":                      schema_client_apis_apps_v1_RecreateBackoff(ref),
+		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.RollingUpdateStatefulSetStrategy":     schema_client_apis_apps_v1_RollingUpdateStatefulSetStrategy(ref),
+		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSet":                          schema_client_apis_apps_v1_StatefulSet(ref),
+		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetBlockedBy":                 schema_client_apis_apps_v1_StatefulSetBlockedBy(ref),
+		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetCondition":                 schema_client_apis_apps_v1_StatefulSetCondition(ref),
+		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetList":                      schema_client_apis_apps_v1_StatefulSetList(ref),
+		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetPodDisruptionBudget":       schema_client_apis_apps_v1_StatefulSetPodDisruptionBudget(ref),
+		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetPodSummary":                schema_client_apis_apps_v1_StatefulSetPodSummary(ref),
+		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetSpec":                      schema_client_apis_apps_v1_StatefulSetSpec(ref),
+		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetStatus":                    schema_client_apis_apps_v1_StatefulSetStatus(ref),
+		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetSurge":                     schema_client_apis_apps_v1_StatefulSetSurge(ref),
+		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetUpdateStrategy":            schema_client_apis_apps_v1_StatefulSetUpdateStrategy(ref),
+		"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetVolumeClaimUpdateStrategy": schema_client_apis_apps_v1_StatefulSetVolumeClaimUpdateStrategy(ref),
+	}
+}
+
+func schema_client_apis_apps_v1_OrdinalOverride(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OrdinalOverride is a patch of the pod template for the Pods whose ordinals are in a range.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"start": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Start is the first ordinal of the range.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"end": {
+						SchemaProps: spec.SchemaProps{
+							Description: "End is the last ordinal of the range. Defaults to Start.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"patch": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Patch is a strategic merge patch which is applied to the pod template when the Pods of the range are created.",
+							Ref:         ref("k8s.io/apimachinery/pkg/runtime.RawExtension"),
+						},
+					},
+				},
+				Required: []string{"start", "patch"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/runtime.RawExtension"},
+	}
+}
+
+func schema_client_apis_apps_v1_RecreateBackoff(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RecreateBackoff describes the recreations of the Pod of an ordinal which failed or succeeded.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"ordinal": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Ordinal of the Pod.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"attempts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Attempts is the number of times the Pod has been recreated.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"nextRecreateTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NextRecreateTime is the earliest time the Pod is recreated again if it terminates. It is not set once maxRecreateAttempts is reached.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+				Required: []string{"ordinal", "attempts"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -54,6 +137,13 @@ func schema_client_apis_apps_v1_RollingUpdateStatefulSetStrategy(ref common.Refe
 							Format:      "int32",
 						},
 					},
+					"maxSurge": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxSurge is the maximum number of temporary Pods that are created during a rolling update. If it is greater than zero, a stale Pod is only deleted once a temporary Pod at the update revision, with an ordinal beyond the largest ordinal of the StatefulSet, is Running and Ready. The temporary Pod is deleted once the stale Pod has been recreated at the update revision and is Running and Ready. Default value is 0.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
 				},
 			},
 		},
@@ -108,6 +198,44 @@ func schema_client_apis_apps_v1_StatefulSet(ref common.ReferenceCallback) common
 	}
 }
 
+func schema_client_apis_apps_v1_StatefulSetBlockedBy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "StatefulSetBlockedBy describes the Pod a StatefulSet waits for.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"pod": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Pod is the name of the Pod.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"ordinal": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Ordinal of the Pod.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is why the StatefulSet waits for the Pod, one of WaitingForReady, WaitingForTermination, WaitingForVolumeClaimResize, RecreateBackoff or EvictionBlocked.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"pod", "ordinal", "reason"},
+			},
+		},
+	}
+}
+
 func schema_client_apis_apps_v1_StatefulSetCondition(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -131,6 +259,13 @@ func schema_client_apis_apps_v1_StatefulSetCondition(ref common.ReferenceCallbac
 							Format:      "",
 						},
 					},
+					"lastUpdateTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The last time this condition was updated.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
 					"lastTransitionTime": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Last time the condition transitioned from one status to another.",
@@ -210,6 +345,77 @@ func schema_client_apis_apps_v1_StatefulSetList(ref common.ReferenceCallback) co
 	}
 }
 
+func schema_client_apis_apps_v1_StatefulSetPodDisruptionBudget(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "StatefulSetPodDisruptionBudget describes the PodDisruptionBudget of a StatefulSet. At most one of MinAvailable and MaxUnavailable may be set. If neither is set, at most one Pod may be unavailable. Percentages are resolved against the replicas of the StatefulSet, rounding up.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"minAvailable": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MinAvailable is the number or percentage of Pods which must be available after an eviction.",
+							Ref:         ref("k8s.io/apimachinery/pkg/util/intstr.IntOrString"),
+						},
+					},
+					"maxUnavailable": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxUnavailable is the number or percentage of Pods which may be unavailable after an eviction.",
+							Ref:         ref("k8s.io/apimachinery/pkg/util/intstr.IntOrString"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/util/intstr.IntOrString"},
+	}
+}
+
+func schema_client_apis_apps_v1_StatefulSetPodSummary(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "StatefulSetPodSummary summarizes the state of the Pod of an ordinal.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"ordinal": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Ordinal of the Pod.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"revision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Revision is the revision of the StatefulSet the Pod was created from.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"ready": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Ready is true if the Pod is Running and Ready.",
+							Default:     false,
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"terminating": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Terminating is true if the Pod is being deleted.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"ordinal", "ready"},
+			},
+		},
+	}
+}
+
 func schema_client_apis_apps_v1_StatefulSetSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -280,12 +486,74 @@ func schema_client_apis_apps_v1_StatefulSetSpec(ref common.ReferenceCallback) co
 							Format:      "int32",
 						},
 					},
+					"progressDeadlineSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "progressDeadlineSeconds is the maximum time in seconds for a rolling update to make progress before it is considered to be failed. The controller will continue to process failed rolling updates and a condition with a ProgressDeadlineExceeded reason will be surfaced in the statefulset status. Progress is not tracked if it is not set.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"autoRollback": {
+						SchemaProps: spec.SchemaProps{
+							Description: "autoRollback indicates that a rolling update which exceeded its progress deadline is rolled back to the current revision. It has no effect if progressDeadlineSeconds is not set.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"volumeClaimUpdateStrategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "volumeClaimUpdateStrategy indicates the StatefulSetVolumeClaimUpdateStrategy that will be employed to update the PersistentVolumeClaims of existing Pods when volumeClaimTemplates change.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetVolumeClaimUpdateStrategy"),
+						},
+					},
+					"ordinalOverrides": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ordinalOverrides patch the pod template of the Pods whose ordinals are in the given ranges. The overrides are part of the revision of the StatefulSet, changing them only rolls the Pods whose pod template changes. The patches of all overrides which contain an ordinal are applied in order.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/pingcap/advanced-statefulset/client/apis/apps/v1.OrdinalOverride"),
+									},
+								},
+							},
+						},
+					},
+					"maxRecreateAttempts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "maxRecreateAttempts is the maximum number of times the Pod of an ordinal is recreated after it failed or succeeded without becoming Running and Ready in between. Pods are recreated with an exponential backoff. Once the limit is reached, the Pod is left as it is and the ReplicaFailure condition is set. The attempts are reset when the Pod becomes Running and Ready or the update revision changes. Defaults to no limit.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"scaleBatchSize": {
+						SchemaProps: spec.SchemaProps{
+							Description: "scaleBatchSize is the maximum number of Pods which are created or deleted at once when the StatefulSet is scaled. With the OrderedReady pod management policy, the next batch of Pods is created once the Pods of the previous batch are Running and Ready, and the next batch of Pods is deleted once the Pods of the previous batch have terminated. Defaults to 1 for OrderedReady and to no limit for Parallel.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"podDisruptionBudget": {
+						SchemaProps: spec.SchemaProps{
+							Description: "podDisruptionBudget indicates that the controller creates and owns a policy/v1 PodDisruptionBudget with the name and the selector of the StatefulSet. The PodDisruptionBudget is deleted when this field is unset or the StatefulSet is deleted.",
+							Ref:         ref("github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetPodDisruptionBudget"),
+						},
+					},
+					"podDeletionPolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "podDeletionPolicy controls how the controller deletes Pods for scale in and updates. The default policy is `Delete`, where Pods are deleted directly. The alternative policy is `Evict` which deletes Pods with the Eviction API, so that PodDisruptionBudgets are respected. Failed and succeeded Pods are always deleted directly.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"selector", "template", "serviceName"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetUpdateStrategy", "k8s.io/api/core/v1.PersistentVolumeClaim", "k8s.io/api/core/v1.PodTemplateSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
+			"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.OrdinalOverride", "github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetPodDisruptionBudget", "github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetUpdateStrategy", "github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetVolumeClaimUpdateStrategy", "k8s.io/api/core/v1.PersistentVolumeClaim", "k8s.io/api/core/v1.PodTemplateSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
 	}
 }
 
@@ -373,12 +641,157 @@ func schema_client_apis_apps_v1_StatefulSetStatus(ref common.ReferenceCallback)
 							},
 						},
 					},
+					"updatedVolumeClaimReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "updatedVolumeClaimReplicas is the number of Pods created by the StatefulSet controller whose PersistentVolumeClaims provide the storage requested by volumeClaimTemplates.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"frozenOrdinals": {
+						SchemaProps: spec.SchemaProps{
+							Description: "frozenOrdinals are the ordinals which are excluded from reconciliation because they are listed in the frozen-ordinals annotation of the StatefulSet or their Pod has the frozen annotation.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: 0,
+										Type:    []string{"integer"},
+										Format:  "int32",
+									},
+								},
+							},
+						},
+					},
+					"recreateBackoffs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "recreateBackoffs are the ordinals whose Pods have been recreated after they failed or succeeded, and have not become Running and Ready since.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/pingcap/advanced-statefulset/client/apis/apps/v1.RecreateBackoff"),
+									},
+								},
+							},
+						},
+					},
+					"surges": {
+						SchemaProps: spec.SchemaProps{
+							Description: "surges are the temporary Pods of a rolling update with maxSurge.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetSurge"),
+									},
+								},
+							},
+						},
+					},
+					"blockedBy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "blockedBy is the Pod the controller waits for before it makes further progress. It is not set while the StatefulSet makes progress or once it is reconciled.",
+							Ref:         ref("github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetBlockedBy"),
+						},
+					},
+					"pods": {
+						SchemaProps: spec.SchemaProps{
+							Description: "pods summarizes the state of the Pods of the StatefulSet, sorted by ordinal.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetPodSummary"),
+									},
+								},
+							},
+						},
+					},
+					"ordinals": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ordinals are the desired ordinals of the StatefulSet, the first replicas ordinals which are not in its effective delete slots.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: 0,
+										Type:    []string{"integer"},
+										Format:  "int32",
+									},
+								},
+							},
+						},
+					},
+					"deleteSlots": {
+						SchemaProps: spec.SchemaProps{
+							Description: "deleteSlots are the delete slots of the delete-slots annotation which are in effect.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: 0,
+										Type:    []string{"integer"},
+										Format:  "int32",
+									},
+								},
+							},
+						},
+					},
+					"ignoredDeleteSlots": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ignoredDeleteSlots are the delete slots of the delete-slots annotation which are ignored because they are not below replicas plus the number of effective delete slots. They are removed from the annotation if the StatefulSet has the prune-delete-slots annotation.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: 0,
+										Type:    []string{"integer"},
+										Format:  "int32",
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"replicas"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetCondition"},
+			"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.RecreateBackoff", "github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetBlockedBy", "github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetCondition", "github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetPodSummary", "github.com/pingcap/advanced-statefulset/client/apis/apps/v1.StatefulSetSurge"},
+	}
+}
+
+func schema_client_apis_apps_v1_StatefulSetSurge(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "StatefulSetSurge describes a temporary Pod which replaces a stale Pod during a rolling update with maxSurge.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"ordinal": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Ordinal of the temporary Pod.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"target": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Target is the ordinal of the Pod which is replaced.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"ordinal", "target"},
+			},
+		},
 	}
 }
 
@@ -409,3 +822,30 @@ func schema_client_apis_apps_v1_StatefulSetUpdateStrategy(ref common.ReferenceCa
 			"github.com/pingcap/advanced-statefulset/client/apis/apps/v1.RollingUpdateStatefulSetStrategy"},
 	}
 }
+
+func schema_client_apis_apps_v1_StatefulSetVolumeClaimUpdateStrategy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "StatefulSetVolumeClaimUpdateStrategy indicates the strategy that the StatefulSet controller will use to update the PersistentVolumeClaims of existing Pods.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type indicates the type of the StatefulSetVolumeClaimUpdateStrategy. Default is OnDelete.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"restartPodOnFileSystemResizePending": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RestartPodOnFileSystemResizePending indicates that a Pod is deleted once the volume of one of its PersistentVolumeClaims has been expanded and only the file system is waiting to be resized, which happens when the volume is mounted again. It is only used by the InPlace strategy.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
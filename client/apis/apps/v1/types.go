@@ -34,6 +34,8 @@ package v1
 import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
@@ -117,6 +119,15 @@ type RollingUpdateStatefulSetStrategy struct {
 	// Default value is 0.
 	// +optional
 	Partition *int32 `json:"partition,omitempty" protobuf:"varint,1,opt,name=partition"`
+	// MaxSurge is the maximum number of temporary Pods that are created
+	// during a rolling update. If it is greater than zero, a stale Pod is
+	// only deleted once a temporary Pod at the update revision, with an
+	// ordinal beyond the largest ordinal of the StatefulSet, is Running and
+	// Ready. The temporary Pod is deleted once the stale Pod has been
+	// recreated at the update revision and is Running and Ready.
+	// Default value is 0.
+	// +optional
+	MaxSurge *int32 `json:"maxSurge,omitempty" protobuf:"varint,2,opt,name=maxSurge"`
 }
 
 // A StatefulSetSpec is the specification of a StatefulSet.
@@ -178,8 +189,149 @@ type StatefulSetSpec struct {
 	// consists of all revisions not represented by a currently applied
 	// StatefulSetSpec version. The default value is 10.
 	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty" protobuf:"varint,8,opt,name=revisionHistoryLimit"`
+
+	// progressDeadlineSeconds is the maximum time in seconds for a rolling
+	// update to make progress before it is considered to be failed. The
+	// controller will continue to process failed rolling updates and a
+	// condition with a ProgressDeadlineExceeded reason will be surfaced in
+	// the statefulset status. Progress is not tracked if it is not set.
+	// +optional
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty" protobuf:"varint,9,opt,name=progressDeadlineSeconds"`
+
+	// autoRollback indicates that a rolling update which exceeded its
+	// progress deadline is rolled back to the current revision. It has no
+	// effect if progressDeadlineSeconds is not set.
+	// +optional
+	AutoRollback bool `json:"autoRollback,omitempty" protobuf:"varint,10,opt,name=autoRollback"`
+
+	// volumeClaimUpdateStrategy indicates the StatefulSetVolumeClaimUpdateStrategy
+	// that will be employed to update the PersistentVolumeClaims of existing
+	// Pods when volumeClaimTemplates change.
+	// +optional
+	VolumeClaimUpdateStrategy StatefulSetVolumeClaimUpdateStrategy `json:"volumeClaimUpdateStrategy,omitempty" protobuf:"bytes,11,opt,name=volumeClaimUpdateStrategy"`
+
+	// ordinalOverrides patch the pod template of the Pods whose ordinals are
+	// in the given ranges. The overrides are part of the revision of the
+	// StatefulSet, changing them only rolls the Pods whose pod template
+	// changes. The patches of all overrides which contain an ordinal are
+	// applied in order.
+	// +optional
+	OrdinalOverrides []OrdinalOverride `json:"ordinalOverrides,omitempty" protobuf:"bytes,12,rep,name=ordinalOverrides"`
+
+	// maxRecreateAttempts is the maximum number of times the Pod of an
+	// ordinal is recreated after it failed or succeeded without becoming
+	// Running and Ready in between. Pods are recreated with an exponential
+	// backoff. Once the limit is reached, the Pod is left as it is and the
+	// ReplicaFailure condition is set. The attempts are reset when the Pod
+	// becomes Running and Ready or the update revision changes. Defaults to
+	// no limit.
+	// +optional
+	MaxRecreateAttempts *int32 `json:"maxRecreateAttempts,omitempty" protobuf:"varint,13,opt,name=maxRecreateAttempts"`
+
+	// scaleBatchSize is the maximum number of Pods which are created or
+	// deleted at once when the StatefulSet is scaled. With the OrderedReady
+	// pod management policy, the next batch of Pods is created once the Pods
+	// of the previous batch are Running and Ready, and the next batch of
+	// Pods is deleted once the Pods of the previous batch have terminated.
+	// Defaults to 1 for OrderedReady and to no limit for Parallel.
+	// +optional
+	ScaleBatchSize *int32 `json:"scaleBatchSize,omitempty" protobuf:"varint,14,opt,name=scaleBatchSize"`
+
+	// podDisruptionBudget indicates that the controller creates and owns a
+	// policy/v1 PodDisruptionBudget with the name and the selector of the
+	// StatefulSet. The PodDisruptionBudget is deleted when this field is
+	// unset or the StatefulSet is deleted.
+	// +optional
+	PodDisruptionBudget *StatefulSetPodDisruptionBudget `json:"podDisruptionBudget,omitempty" protobuf:"bytes,15,opt,name=podDisruptionBudget"`
+
+	// podDeletionPolicy controls how the controller deletes Pods for scale
+	// in and updates. The default policy is `Delete`, where Pods are deleted
+	// directly. The alternative policy is `Evict` which deletes Pods with
+	// the Eviction API, so that PodDisruptionBudgets are respected. Failed
+	// and succeeded Pods are always deleted directly.
+	// +optional
+	PodDeletionPolicy PodDeletionPolicyType `json:"podDeletionPolicy,omitempty" protobuf:"bytes,16,opt,name=podDeletionPolicy,casttype=PodDeletionPolicyType"`
+}
+
+// PodDeletionPolicyType defines how the controller deletes the pods of a
+// stateful set.
+type PodDeletionPolicyType string
+
+const (
+	// DeletePodDeletionPolicy deletes pods directly.
+	DeletePodDeletionPolicy PodDeletionPolicyType = "Delete"
+	// EvictPodDeletionPolicy deletes pods with the Eviction API. A pod whose
+	// eviction would violate a PodDisruptionBudget is retried with a
+	// backoff.
+	EvictPodDeletionPolicy PodDeletionPolicyType = "Evict"
+)
+
+// StatefulSetPodDisruptionBudget describes the PodDisruptionBudget of a
+// StatefulSet. At most one of MinAvailable and MaxUnavailable may be set. If
+// neither is set, at most one Pod may be unavailable. Percentages are
+// resolved against the replicas of the StatefulSet, rounding up.
+type StatefulSetPodDisruptionBudget struct {
+	// MinAvailable is the number or percentage of Pods which must be
+	// available after an eviction.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty" protobuf:"bytes,1,opt,name=minAvailable"`
+	// MaxUnavailable is the number or percentage of Pods which may be
+	// unavailable after an eviction.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty" protobuf:"bytes,2,opt,name=maxUnavailable"`
 }
 
+// OrdinalOverride is a patch of the pod template for the Pods whose ordinals
+// are in a range.
+type OrdinalOverride struct {
+	// Start is the first ordinal of the range.
+	Start int32 `json:"start" protobuf:"varint,1,opt,name=start"`
+	// End is the last ordinal of the range. Defaults to Start.
+	// +optional
+	End *int32 `json:"end,omitempty" protobuf:"varint,2,opt,name=end"`
+	// Patch is a strategic merge patch which is applied to the pod template
+	// when the Pods of the range are created.
+	Patch runtime.RawExtension `json:"patch" protobuf:"bytes,3,opt,name=patch"`
+}
+
+// StatefulSetVolumeClaimUpdateStrategy indicates the strategy that the
+// StatefulSet controller will use to update the PersistentVolumeClaims of
+// existing Pods.
+type StatefulSetVolumeClaimUpdateStrategy struct {
+	// Type indicates the type of the StatefulSetVolumeClaimUpdateStrategy.
+	// Default is OnDelete.
+	// +optional
+	Type VolumeClaimUpdateStrategyType `json:"type,omitempty" protobuf:"bytes,1,opt,name=type,casttype=VolumeClaimUpdateStrategyType"`
+	// RestartPodOnFileSystemResizePending indicates that a Pod is deleted once
+	// the volume of one of its PersistentVolumeClaims has been expanded and only
+	// the file system is waiting to be resized, which happens when the volume is
+	// mounted again. It is only used by the InPlace strategy.
+	// +optional
+	RestartPodOnFileSystemResizePending bool `json:"restartPodOnFileSystemResizePending,omitempty" protobuf:"varint,2,opt,name=restartPodOnFileSystemResizePending"`
+}
+
+// VolumeClaimUpdateStrategyType is a string enumeration type that enumerates
+// all possible volume claim update strategies for the StatefulSet controller.
+type VolumeClaimUpdateStrategyType string
+
+const (
+	// OnDeleteVolumeClaimUpdateStrategyType triggers the legacy behavior.
+	// Changes to volumeClaimTemplates only apply to PersistentVolumeClaims
+	// which are created after the change.
+	OnDeleteVolumeClaimUpdateStrategyType VolumeClaimUpdateStrategyType = "OnDelete"
+	// InPlaceVolumeClaimUpdateStrategyType indicates that the storage requests
+	// of existing PersistentVolumeClaims are expanded to the requests of
+	// volumeClaimTemplates, one ordinal at a time in decreasing order. The
+	// storage class of the claims must allow volume expansion.
+	InPlaceVolumeClaimUpdateStrategyType VolumeClaimUpdateStrategyType = "InPlace"
+	// RecreateVolumeClaimUpdateStrategyType indicates that PersistentVolumeClaims
+	// whose storage class, access modes, volume mode or storage request differ
+	// from volumeClaimTemplates are recreated, one ordinal at a time in
+	// decreasing order. The Pod and its claims are deleted and created again
+	// from the templates, the data of the old volumes is not migrated.
+	RecreateVolumeClaimUpdateStrategyType VolumeClaimUpdateStrategyType = "Recreate"
+)
+
 // StatefulSetStatus represents the current state of a StatefulSet.
 type StatefulSetStatus struct {
 	// observedGeneration is the most recent generation observed for this StatefulSet. It corresponds to the
@@ -220,16 +372,131 @@ type StatefulSetStatus struct {
 	// +patchMergeKey=type
 	// +patchStrategy=merge
 	Conditions []StatefulSetCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,10,rep,name=conditions"`
+
+	// updatedVolumeClaimReplicas is the number of Pods created by the StatefulSet controller whose
+	// PersistentVolumeClaims provide the storage requested by volumeClaimTemplates.
+	// +optional
+	UpdatedVolumeClaimReplicas int32 `json:"updatedVolumeClaimReplicas,omitempty" protobuf:"varint,11,opt,name=updatedVolumeClaimReplicas"`
+
+	// frozenOrdinals are the ordinals which are excluded from reconciliation
+	// because they are listed in the frozen-ordinals annotation of the
+	// StatefulSet or their Pod has the frozen annotation.
+	// +optional
+	FrozenOrdinals []int32 `json:"frozenOrdinals,omitempty" protobuf:"varint,12,rep,name=frozenOrdinals"`
+
+	// recreateBackoffs are the ordinals whose Pods have been recreated after
+	// they failed or succeeded, and have not become Running and Ready since.
+	// +optional
+	RecreateBackoffs []RecreateBackoff `json:"recreateBackoffs,omitempty" protobuf:"bytes,13,rep,name=recreateBackoffs"`
+
+	// surges are the temporary Pods of a rolling update with maxSurge.
+	// +optional
+	Surges []StatefulSetSurge `json:"surges,omitempty" protobuf:"bytes,14,rep,name=surges"`
+
+	// blockedBy is the Pod the controller waits for before it makes further
+	// progress. It is not set while the StatefulSet makes progress or once it
+	// is reconciled.
+	// +optional
+	BlockedBy *StatefulSetBlockedBy `json:"blockedBy,omitempty" protobuf:"bytes,15,opt,name=blockedBy"`
+
+	// pods summarizes the state of the Pods of the StatefulSet, sorted by
+	// ordinal.
+	// +optional
+	Pods []StatefulSetPodSummary `json:"pods,omitempty" protobuf:"bytes,16,rep,name=pods"`
+
+	// ordinals are the desired ordinals of the StatefulSet, the first
+	// replicas ordinals which are not in its effective delete slots.
+	// +optional
+	Ordinals []int32 `json:"ordinals,omitempty" protobuf:"varint,17,rep,name=ordinals"`
+
+	// deleteSlots are the delete slots of the delete-slots annotation which
+	// are in effect.
+	// +optional
+	DeleteSlots []int32 `json:"deleteSlots,omitempty" protobuf:"varint,18,rep,name=deleteSlots"`
+
+	// ignoredDeleteSlots are the delete slots of the delete-slots annotation
+	// which are ignored because they are not below replicas plus the number
+	// of effective delete slots. They are removed from the annotation if the StatefulSet has the
+	// prune-delete-slots annotation.
+	// +optional
+	IgnoredDeleteSlots []int32 `json:"ignoredDeleteSlots,omitempty" protobuf:"varint,19,rep,name=ignoredDeleteSlots"`
+}
+
+// StatefulSetBlockedBy describes the Pod a StatefulSet waits for.
+type StatefulSetBlockedBy struct {
+	// Pod is the name of the Pod.
+	Pod string `json:"pod" protobuf:"bytes,1,opt,name=pod"`
+	// Ordinal of the Pod.
+	Ordinal int32 `json:"ordinal" protobuf:"varint,2,opt,name=ordinal"`
+	// Reason is why the StatefulSet waits for the Pod, one of
+	// WaitingForReady, WaitingForTermination, WaitingForVolumeClaimResize,
+	// RecreateBackoff or EvictionBlocked.
+	Reason string `json:"reason" protobuf:"bytes,3,opt,name=reason"`
+}
+
+// StatefulSetPodSummary summarizes the state of the Pod of an ordinal.
+type StatefulSetPodSummary struct {
+	// Ordinal of the Pod.
+	Ordinal int32 `json:"ordinal" protobuf:"varint,1,opt,name=ordinal"`
+	// Revision is the revision of the StatefulSet the Pod was created from.
+	// +optional
+	Revision string `json:"revision,omitempty" protobuf:"bytes,2,opt,name=revision"`
+	// Ready is true if the Pod is Running and Ready.
+	Ready bool `json:"ready" protobuf:"varint,3,opt,name=ready"`
+	// Terminating is true if the Pod is being deleted.
+	// +optional
+	Terminating bool `json:"terminating,omitempty" protobuf:"varint,4,opt,name=terminating"`
+}
+
+// StatefulSetSurge describes a temporary Pod which replaces a stale Pod
+// during a rolling update with maxSurge.
+type StatefulSetSurge struct {
+	// Ordinal of the temporary Pod.
+	Ordinal int32 `json:"ordinal" protobuf:"varint,1,opt,name=ordinal"`
+	// Target is the ordinal of the Pod which is replaced.
+	Target int32 `json:"target" protobuf:"varint,2,opt,name=target"`
+}
+
+// RecreateBackoff describes the recreations of the Pod of an ordinal which
+// failed or succeeded.
+type RecreateBackoff struct {
+	// Ordinal of the Pod.
+	Ordinal int32 `json:"ordinal" protobuf:"varint,1,opt,name=ordinal"`
+	// Attempts is the number of times the Pod has been recreated.
+	Attempts int32 `json:"attempts" protobuf:"varint,2,opt,name=attempts"`
+	// NextRecreateTime is the earliest time the Pod is recreated again if it
+	// terminates. It is not set once maxRecreateAttempts is reached.
+	// +optional
+	NextRecreateTime *metav1.Time `json:"nextRecreateTime,omitempty" protobuf:"bytes,3,opt,name=nextRecreateTime"`
 }
 
 type StatefulSetConditionType string
 
+// These are valid conditions of a statefulset.
+const (
+	// StatefulSetRollback is true while the statefulset is rolling back to
+	// a previous revision requested with the rollback-to annotation.
+	StatefulSetRollback StatefulSetConditionType = "Rollback"
+	// StatefulSetProgressing is true while a rolling update makes progress
+	// and false once it exceeded spec.progressDeadlineSeconds.
+	StatefulSetProgressing StatefulSetConditionType = "Progressing"
+	// StatefulSetReplicaFailure is true while the Pod of an ordinal has
+	// reached spec.maxRecreateAttempts and is not recreated anymore.
+	StatefulSetReplicaFailure StatefulSetConditionType = "ReplicaFailure"
+	// StatefulSetDisruptionBlocked is true while the eviction of a Pod is
+	// refused because it would violate a PodDisruptionBudget.
+	StatefulSetDisruptionBlocked StatefulSetConditionType = "DisruptionBlocked"
+)
+
 // StatefulSetCondition describes the state of a statefulset at a certain point.
 type StatefulSetCondition struct {
 	// Type of statefulset condition.
 	Type StatefulSetConditionType `json:"type" protobuf:"bytes,1,opt,name=type,casttype=StatefulSetConditionType"`
 	// Status of the condition, one of True, False, Unknown.
 	Status v1.ConditionStatus `json:"status" protobuf:"bytes,2,opt,name=status,casttype=k8s.io/api/core/v1.ConditionStatus"`
+	// The last time this condition was updated.
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty" protobuf:"bytes,6,opt,name=lastUpdateTime"`
 	// Last time the condition transitioned from one status to another.
 	// +optional
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty" protobuf:"bytes,3,opt,name=lastTransitionTime"`
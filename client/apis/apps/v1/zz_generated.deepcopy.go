@@ -25,8 +25,51 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrdinalOverride) DeepCopyInto(out *OrdinalOverride) {
+	*out = *in
+	if in.End != nil {
+		in, out := &in.End, &out.End
+		*out = new(int32)
+		**out = **in
+	}
+	in.Patch.DeepCopyInto(&out.Patch)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrdinalOverride.
+func (in *OrdinalOverride) DeepCopy() *OrdinalOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(OrdinalOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecreateBackoff) DeepCopyInto(out *RecreateBackoff) {
+	*out = *in
+	if in.NextRecreateTime != nil {
+		in, out := &in.NextRecreateTime, &out.NextRecreateTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecreateBackoff.
+func (in *RecreateBackoff) DeepCopy() *RecreateBackoff {
+	if in == nil {
+		return nil
+	}
+	out := new(RecreateBackoff)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RollingUpdateStatefulSetStrategy) DeepCopyInto(out *RollingUpdateStatefulSetStrategy) {
 	*out = *in
@@ -35,6 +78,11 @@ func (in *RollingUpdateStatefulSetStrategy) DeepCopyInto(out *RollingUpdateState
 		*out = new(int32)
 		**out = **in
 	}
+	if in.MaxSurge != nil {
+		in, out := &in.MaxSurge, &out.MaxSurge
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -76,9 +124,26 @@ func (in *StatefulSet) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetBlockedBy) DeepCopyInto(out *StatefulSetBlockedBy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetBlockedBy.
+func (in *StatefulSetBlockedBy) DeepCopy() *StatefulSetBlockedBy {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetBlockedBy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StatefulSetCondition) DeepCopyInto(out *StatefulSetCondition) {
 	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
@@ -126,6 +191,48 @@ func (in *StatefulSetList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetPodDisruptionBudget) DeepCopyInto(out *StatefulSetPodDisruptionBudget) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetPodDisruptionBudget.
+func (in *StatefulSetPodDisruptionBudget) DeepCopy() *StatefulSetPodDisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetPodDisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetPodSummary) DeepCopyInto(out *StatefulSetPodSummary) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetPodSummary.
+func (in *StatefulSetPodSummary) DeepCopy() *StatefulSetPodSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetPodSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StatefulSetSpec) DeepCopyInto(out *StatefulSetSpec) {
 	*out = *in
@@ -153,6 +260,34 @@ func (in *StatefulSetSpec) DeepCopyInto(out *StatefulSetSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ProgressDeadlineSeconds != nil {
+		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	out.VolumeClaimUpdateStrategy = in.VolumeClaimUpdateStrategy
+	if in.OrdinalOverrides != nil {
+		in, out := &in.OrdinalOverrides, &out.OrdinalOverrides
+		*out = make([]OrdinalOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MaxRecreateAttempts != nil {
+		in, out := &in.MaxRecreateAttempts, &out.MaxRecreateAttempts
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ScaleBatchSize != nil {
+		in, out := &in.ScaleBatchSize, &out.ScaleBatchSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(StatefulSetPodDisruptionBudget)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -181,6 +316,48 @@ func (in *StatefulSetStatus) DeepCopyInto(out *StatefulSetStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.FrozenOrdinals != nil {
+		in, out := &in.FrozenOrdinals, &out.FrozenOrdinals
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.RecreateBackoffs != nil {
+		in, out := &in.RecreateBackoffs, &out.RecreateBackoffs
+		*out = make([]RecreateBackoff, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Surges != nil {
+		in, out := &in.Surges, &out.Surges
+		*out = make([]StatefulSetSurge, len(*in))
+		copy(*out, *in)
+	}
+	if in.BlockedBy != nil {
+		in, out := &in.BlockedBy, &out.BlockedBy
+		*out = new(StatefulSetBlockedBy)
+		**out = **in
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]StatefulSetPodSummary, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ordinals != nil {
+		in, out := &in.Ordinals, &out.Ordinals
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeleteSlots != nil {
+		in, out := &in.DeleteSlots, &out.DeleteSlots
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.IgnoredDeleteSlots != nil {
+		in, out := &in.IgnoredDeleteSlots, &out.IgnoredDeleteSlots
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -194,6 +371,22 @@ func (in *StatefulSetStatus) DeepCopy() *StatefulSetStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetSurge) DeepCopyInto(out *StatefulSetSurge) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetSurge.
+func (in *StatefulSetSurge) DeepCopy() *StatefulSetSurge {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetSurge)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StatefulSetUpdateStrategy) DeepCopyInto(out *StatefulSetUpdateStrategy) {
 	*out = *in
@@ -214,3 +407,19 @@ func (in *StatefulSetUpdateStrategy) DeepCopy() *StatefulSetUpdateStrategy {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetVolumeClaimUpdateStrategy) DeepCopyInto(out *StatefulSetVolumeClaimUpdateStrategy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetVolumeClaimUpdateStrategy.
+func (in *StatefulSetVolumeClaimUpdateStrategy) DeepCopy() *StatefulSetVolumeClaimUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetVolumeClaimUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,61 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// OrdinalOverrideApplyConfiguration represents an declarative configuration of the OrdinalOverride type for use
+// with apply.
+type OrdinalOverrideApplyConfiguration struct {
+	Start *int32                `json:"start,omitempty"`
+	End   *int32                `json:"end,omitempty"`
+	Patch *runtime.RawExtension `json:"patch,omitempty"`
+}
+
+// OrdinalOverrideApplyConfiguration constructs an declarative configuration of the OrdinalOverride type for use with
+// apply.
+func OrdinalOverride() *OrdinalOverrideApplyConfiguration {
+	return &OrdinalOverrideApplyConfiguration{}
+}
+
+// WithStart sets the Start field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Start field is set to the value of the last call.
+func (b *OrdinalOverrideApplyConfiguration) WithStart(value int32) *OrdinalOverrideApplyConfiguration {
+	b.Start = &value
+	return b
+}
+
+// WithEnd sets the End field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the End field is set to the value of the last call.
+func (b *OrdinalOverrideApplyConfiguration) WithEnd(value int32) *OrdinalOverrideApplyConfiguration {
+	b.End = &value
+	return b
+}
+
+// WithPatch sets the Patch field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Patch field is set to the value of the last call.
+func (b *OrdinalOverrideApplyConfiguration) WithPatch(value runtime.RawExtension) *OrdinalOverrideApplyConfiguration {
+	b.Patch = &value
+	return b
+}
@@ -0,0 +1,61 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RecreateBackoffApplyConfiguration represents an declarative configuration of the RecreateBackoff type for use
+// with apply.
+type RecreateBackoffApplyConfiguration struct {
+	Ordinal          *int32   `json:"ordinal,omitempty"`
+	Attempts         *int32   `json:"attempts,omitempty"`
+	NextRecreateTime *v1.Time `json:"nextRecreateTime,omitempty"`
+}
+
+// RecreateBackoffApplyConfiguration constructs an declarative configuration of the RecreateBackoff type for use with
+// apply.
+func RecreateBackoff() *RecreateBackoffApplyConfiguration {
+	return &RecreateBackoffApplyConfiguration{}
+}
+
+// WithOrdinal sets the Ordinal field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Ordinal field is set to the value of the last call.
+func (b *RecreateBackoffApplyConfiguration) WithOrdinal(value int32) *RecreateBackoffApplyConfiguration {
+	b.Ordinal = &value
+	return b
+}
+
+// WithAttempts sets the Attempts field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Attempts field is set to the value of the last call.
+func (b *RecreateBackoffApplyConfiguration) WithAttempts(value int32) *RecreateBackoffApplyConfiguration {
+	b.Attempts = &value
+	return b
+}
+
+// WithNextRecreateTime sets the NextRecreateTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the NextRecreateTime field is set to the value of the last call.
+func (b *RecreateBackoffApplyConfiguration) WithNextRecreateTime(value v1.Time) *RecreateBackoffApplyConfiguration {
+	b.NextRecreateTime = &value
+	return b
+}
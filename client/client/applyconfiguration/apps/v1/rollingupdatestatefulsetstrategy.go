@@ -22,6 +22,7 @@ package v1
 // with apply.
 type RollingUpdateStatefulSetStrategyApplyConfiguration struct {
 	Partition *int32 `json:"partition,omitempty"`
+	MaxSurge  *int32 `json:"maxSurge,omitempty"`
 }
 
 // RollingUpdateStatefulSetStrategyApplyConfiguration constructs an declarative configuration of the RollingUpdateStatefulSetStrategy type for use with
@@ -37,3 +38,11 @@ func (b *RollingUpdateStatefulSetStrategyApplyConfiguration) WithPartition(value
 	b.Partition = &value
 	return b
 }
+
+// WithMaxSurge sets the MaxSurge field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxSurge field is set to the value of the last call.
+func (b *RollingUpdateStatefulSetStrategyApplyConfiguration) WithMaxSurge(value int32) *RollingUpdateStatefulSetStrategyApplyConfiguration {
+	b.MaxSurge = &value
+	return b
+}
@@ -0,0 +1,57 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// StatefulSetBlockedByApplyConfiguration represents an declarative configuration of the StatefulSetBlockedBy type for use
+// with apply.
+type StatefulSetBlockedByApplyConfiguration struct {
+	Pod     *string `json:"pod,omitempty"`
+	Ordinal *int32  `json:"ordinal,omitempty"`
+	Reason  *string `json:"reason,omitempty"`
+}
+
+// StatefulSetBlockedByApplyConfiguration constructs an declarative configuration of the StatefulSetBlockedBy type for use with
+// apply.
+func StatefulSetBlockedBy() *StatefulSetBlockedByApplyConfiguration {
+	return &StatefulSetBlockedByApplyConfiguration{}
+}
+
+// WithPod sets the Pod field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Pod field is set to the value of the last call.
+func (b *StatefulSetBlockedByApplyConfiguration) WithPod(value string) *StatefulSetBlockedByApplyConfiguration {
+	b.Pod = &value
+	return b
+}
+
+// WithOrdinal sets the Ordinal field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Ordinal field is set to the value of the last call.
+func (b *StatefulSetBlockedByApplyConfiguration) WithOrdinal(value int32) *StatefulSetBlockedByApplyConfiguration {
+	b.Ordinal = &value
+	return b
+}
+
+// WithReason sets the Reason field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Reason field is set to the value of the last call.
+func (b *StatefulSetBlockedByApplyConfiguration) WithReason(value string) *StatefulSetBlockedByApplyConfiguration {
+	b.Reason = &value
+	return b
+}
@@ -29,6 +29,7 @@ import (
 type StatefulSetConditionApplyConfiguration struct {
 	Type               *v1.StatefulSetConditionType `json:"type,omitempty"`
 	Status             *corev1.ConditionStatus      `json:"status,omitempty"`
+	LastUpdateTime     *metav1.Time                 `json:"lastUpdateTime,omitempty"`
 	LastTransitionTime *metav1.Time                 `json:"lastTransitionTime,omitempty"`
 	Reason             *string                      `json:"reason,omitempty"`
 	Message            *string                      `json:"message,omitempty"`
@@ -56,6 +57,14 @@ func (b *StatefulSetConditionApplyConfiguration) WithStatus(value corev1.Conditi
 	return b
 }
 
+// WithLastUpdateTime sets the LastUpdateTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastUpdateTime field is set to the value of the last call.
+func (b *StatefulSetConditionApplyConfiguration) WithLastUpdateTime(value metav1.Time) *StatefulSetConditionApplyConfiguration {
+	b.LastUpdateTime = &value
+	return b
+}
+
 // WithLastTransitionTime sets the LastTransitionTime field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the LastTransitionTime field is set to the value of the last call.
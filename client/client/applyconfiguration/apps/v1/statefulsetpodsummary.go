@@ -0,0 +1,66 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// StatefulSetPodSummaryApplyConfiguration represents an declarative configuration of the StatefulSetPodSummary type for use
+// with apply.
+type StatefulSetPodSummaryApplyConfiguration struct {
+	Ordinal     *int32  `json:"ordinal,omitempty"`
+	Revision    *string `json:"revision,omitempty"`
+	Ready       *bool   `json:"ready,omitempty"`
+	Terminating *bool   `json:"terminating,omitempty"`
+}
+
+// StatefulSetPodSummaryApplyConfiguration constructs an declarative configuration of the StatefulSetPodSummary type for use with
+// apply.
+func StatefulSetPodSummary() *StatefulSetPodSummaryApplyConfiguration {
+	return &StatefulSetPodSummaryApplyConfiguration{}
+}
+
+// WithOrdinal sets the Ordinal field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Ordinal field is set to the value of the last call.
+func (b *StatefulSetPodSummaryApplyConfiguration) WithOrdinal(value int32) *StatefulSetPodSummaryApplyConfiguration {
+	b.Ordinal = &value
+	return b
+}
+
+// WithRevision sets the Revision field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Revision field is set to the value of the last call.
+func (b *StatefulSetPodSummaryApplyConfiguration) WithRevision(value string) *StatefulSetPodSummaryApplyConfiguration {
+	b.Revision = &value
+	return b
+}
+
+// WithReady sets the Ready field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Ready field is set to the value of the last call.
+func (b *StatefulSetPodSummaryApplyConfiguration) WithReady(value bool) *StatefulSetPodSummaryApplyConfiguration {
+	b.Ready = &value
+	return b
+}
+
+// WithTerminating sets the Terminating field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Terminating field is set to the value of the last call.
+func (b *StatefulSetPodSummaryApplyConfiguration) WithTerminating(value bool) *StatefulSetPodSummaryApplyConfiguration {
+	b.Terminating = &value
+	return b
+}
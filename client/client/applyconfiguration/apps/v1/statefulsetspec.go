@@ -27,14 +27,22 @@ import (
 // StatefulSetSpecApplyConfiguration represents an declarative configuration of the StatefulSetSpec type for use
 // with apply.
 type StatefulSetSpecApplyConfiguration struct {
-	Replicas             *int32                                       `json:"replicas,omitempty"`
-	Selector             *v1.LabelSelector                            `json:"selector,omitempty"`
-	Template             *corev1.PodTemplateSpec                      `json:"template,omitempty"`
-	VolumeClaimTemplates []corev1.PersistentVolumeClaim               `json:"volumeClaimTemplates,omitempty"`
-	ServiceName          *string                                      `json:"serviceName,omitempty"`
-	PodManagementPolicy  *appsv1.PodManagementPolicyType              `json:"podManagementPolicy,omitempty"`
-	UpdateStrategy       *StatefulSetUpdateStrategyApplyConfiguration `json:"updateStrategy,omitempty"`
-	RevisionHistoryLimit *int32                                       `json:"revisionHistoryLimit,omitempty"`
+	Replicas                  *int32                                                  `json:"replicas,omitempty"`
+	Selector                  *v1.LabelSelector                                       `json:"selector,omitempty"`
+	Template                  *corev1.PodTemplateSpec                                 `json:"template,omitempty"`
+	VolumeClaimTemplates      []corev1.PersistentVolumeClaim                          `json:"volumeClaimTemplates,omitempty"`
+	ServiceName               *string                                                 `json:"serviceName,omitempty"`
+	PodManagementPolicy       *appsv1.PodManagementPolicyType                         `json:"podManagementPolicy,omitempty"`
+	UpdateStrategy            *StatefulSetUpdateStrategyApplyConfiguration            `json:"updateStrategy,omitempty"`
+	RevisionHistoryLimit      *int32                                                  `json:"revisionHistoryLimit,omitempty"`
+	ProgressDeadlineSeconds   *int32                                                  `json:"progressDeadlineSeconds,omitempty"`
+	AutoRollback              *bool                                                   `json:"autoRollback,omitempty"`
+	VolumeClaimUpdateStrategy *StatefulSetVolumeClaimUpdateStrategyApplyConfiguration `json:"volumeClaimUpdateStrategy,omitempty"`
+	OrdinalOverrides          []OrdinalOverrideApplyConfiguration                     `json:"ordinalOverrides,omitempty"`
+	MaxRecreateAttempts       *int32                                                  `json:"maxRecreateAttempts,omitempty"`
+	ScaleBatchSize            *int32                                                  `json:"scaleBatchSize,omitempty"`
+	PodDisruptionBudget       *StatefulSetPodDisruptionBudgetApplyConfiguration       `json:"podDisruptionBudget,omitempty"`
+	PodDeletionPolicy         *appsv1.PodDeletionPolicyType                           `json:"podDeletionPolicy,omitempty"`
 }
 
 // StatefulSetSpecApplyConfiguration constructs an declarative configuration of the StatefulSetSpec type for use with
@@ -108,3 +116,72 @@ func (b *StatefulSetSpecApplyConfiguration) WithRevisionHistoryLimit(value int32
 	b.RevisionHistoryLimit = &value
 	return b
 }
+
+// WithProgressDeadlineSeconds sets the ProgressDeadlineSeconds field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ProgressDeadlineSeconds field is set to the value of the last call.
+func (b *StatefulSetSpecApplyConfiguration) WithProgressDeadlineSeconds(value int32) *StatefulSetSpecApplyConfiguration {
+	b.ProgressDeadlineSeconds = &value
+	return b
+}
+
+// WithAutoRollback sets the AutoRollback field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the AutoRollback field is set to the value of the last call.
+func (b *StatefulSetSpecApplyConfiguration) WithAutoRollback(value bool) *StatefulSetSpecApplyConfiguration {
+	b.AutoRollback = &value
+	return b
+}
+
+// WithVolumeClaimUpdateStrategy sets the VolumeClaimUpdateStrategy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the VolumeClaimUpdateStrategy field is set to the value of the last call.
+func (b *StatefulSetSpecApplyConfiguration) WithVolumeClaimUpdateStrategy(value *StatefulSetVolumeClaimUpdateStrategyApplyConfiguration) *StatefulSetSpecApplyConfiguration {
+	b.VolumeClaimUpdateStrategy = value
+	return b
+}
+
+// WithOrdinalOverrides adds the given value to the OrdinalOverrides field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the OrdinalOverrides field.
+func (b *StatefulSetSpecApplyConfiguration) WithOrdinalOverrides(values ...*OrdinalOverrideApplyConfiguration) *StatefulSetSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithOrdinalOverrides")
+		}
+		b.OrdinalOverrides = append(b.OrdinalOverrides, *values[i])
+	}
+	return b
+}
+
+// WithMaxRecreateAttempts sets the MaxRecreateAttempts field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxRecreateAttempts field is set to the value of the last call.
+func (b *StatefulSetSpecApplyConfiguration) WithMaxRecreateAttempts(value int32) *StatefulSetSpecApplyConfiguration {
+	b.MaxRecreateAttempts = &value
+	return b
+}
+
+// WithScaleBatchSize sets the ScaleBatchSize field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ScaleBatchSize field is set to the value of the last call.
+func (b *StatefulSetSpecApplyConfiguration) WithScaleBatchSize(value int32) *StatefulSetSpecApplyConfiguration {
+	b.ScaleBatchSize = &value
+	return b
+}
+
+// WithPodDisruptionBudget sets the PodDisruptionBudget field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PodDisruptionBudget field is set to the value of the last call.
+func (b *StatefulSetSpecApplyConfiguration) WithPodDisruptionBudget(value *StatefulSetPodDisruptionBudgetApplyConfiguration) *StatefulSetSpecApplyConfiguration {
+	b.PodDisruptionBudget = value
+	return b
+}
+
+// WithPodDeletionPolicy sets the PodDeletionPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PodDeletionPolicy field is set to the value of the last call.
+func (b *StatefulSetSpecApplyConfiguration) WithPodDeletionPolicy(value appsv1.PodDeletionPolicyType) *StatefulSetSpecApplyConfiguration {
+	b.PodDeletionPolicy = &value
+	return b
+}
@@ -21,15 +21,24 @@ package v1
 // StatefulSetStatusApplyConfiguration represents an declarative configuration of the StatefulSetStatus type for use
 // with apply.
 type StatefulSetStatusApplyConfiguration struct {
-	ObservedGeneration *int64                                   `json:"observedGeneration,omitempty"`
-	Replicas           *int32                                   `json:"replicas,omitempty"`
-	ReadyReplicas      *int32                                   `json:"readyReplicas,omitempty"`
-	CurrentReplicas    *int32                                   `json:"currentReplicas,omitempty"`
-	UpdatedReplicas    *int32                                   `json:"updatedReplicas,omitempty"`
-	CurrentRevision    *string                                  `json:"currentRevision,omitempty"`
-	UpdateRevision     *string                                  `json:"updateRevision,omitempty"`
-	CollisionCount     *int32                                   `json:"collisionCount,omitempty"`
-	Conditions         []StatefulSetConditionApplyConfiguration `json:"conditions,omitempty"`
+	ObservedGeneration         *int64                                    `json:"observedGeneration,omitempty"`
+	Replicas                   *int32                                    `json:"replicas,omitempty"`
+	ReadyReplicas              *int32                                    `json:"readyReplicas,omitempty"`
+	CurrentReplicas            *int32                                    `json:"currentReplicas,omitempty"`
+	UpdatedReplicas            *int32                                    `json:"updatedReplicas,omitempty"`
+	CurrentRevision            *string                                   `json:"currentRevision,omitempty"`
+	UpdateRevision             *string                                   `json:"updateRevision,omitempty"`
+	CollisionCount             *int32                                    `json:"collisionCount,omitempty"`
+	Conditions                 []StatefulSetConditionApplyConfiguration  `json:"conditions,omitempty"`
+	UpdatedVolumeClaimReplicas *int32                                    `json:"updatedVolumeClaimReplicas,omitempty"`
+	FrozenOrdinals             []int32                                   `json:"frozenOrdinals,omitempty"`
+	RecreateBackoffs           []RecreateBackoffApplyConfiguration       `json:"recreateBackoffs,omitempty"`
+	Surges                     []StatefulSetSurgeApplyConfiguration      `json:"surges,omitempty"`
+	BlockedBy                  *StatefulSetBlockedByApplyConfiguration   `json:"blockedBy,omitempty"`
+	Pods                       []StatefulSetPodSummaryApplyConfiguration `json:"pods,omitempty"`
+	Ordinals                   []int32                                   `json:"ordinals,omitempty"`
+	DeleteSlots                []int32                                   `json:"deleteSlots,omitempty"`
+	IgnoredDeleteSlots         []int32                                   `json:"ignoredDeleteSlots,omitempty"`
 }
 
 // StatefulSetStatusApplyConfiguration constructs an declarative configuration of the StatefulSetStatus type for use with
@@ -114,3 +123,98 @@ func (b *StatefulSetStatusApplyConfiguration) WithConditions(values ...*Stateful
 	}
 	return b
 }
+
+// WithUpdatedVolumeClaimReplicas sets the UpdatedVolumeClaimReplicas field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the UpdatedVolumeClaimReplicas field is set to the value of the last call.
+func (b *StatefulSetStatusApplyConfiguration) WithUpdatedVolumeClaimReplicas(value int32) *StatefulSetStatusApplyConfiguration {
+	b.UpdatedVolumeClaimReplicas = &value
+	return b
+}
+
+// WithFrozenOrdinals adds the given value to the FrozenOrdinals field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the FrozenOrdinals field.
+func (b *StatefulSetStatusApplyConfiguration) WithFrozenOrdinals(values ...int32) *StatefulSetStatusApplyConfiguration {
+	for i := range values {
+		b.FrozenOrdinals = append(b.FrozenOrdinals, values[i])
+	}
+	return b
+}
+
+// WithRecreateBackoffs adds the given value to the RecreateBackoffs field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the RecreateBackoffs field.
+func (b *StatefulSetStatusApplyConfiguration) WithRecreateBackoffs(values ...*RecreateBackoffApplyConfiguration) *StatefulSetStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithRecreateBackoffs")
+		}
+		b.RecreateBackoffs = append(b.RecreateBackoffs, *values[i])
+	}
+	return b
+}
+
+// WithSurges adds the given value to the Surges field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Surges field.
+func (b *StatefulSetStatusApplyConfiguration) WithSurges(values ...*StatefulSetSurgeApplyConfiguration) *StatefulSetStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithSurges")
+		}
+		b.Surges = append(b.Surges, *values[i])
+	}
+	return b
+}
+
+// WithBlockedBy sets the BlockedBy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the BlockedBy field is set to the value of the last call.
+func (b *StatefulSetStatusApplyConfiguration) WithBlockedBy(value *StatefulSetBlockedByApplyConfiguration) *StatefulSetStatusApplyConfiguration {
+	b.BlockedBy = value
+	return b
+}
+
+// WithPods adds the given value to the Pods field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Pods field.
+func (b *StatefulSetStatusApplyConfiguration) WithPods(values ...*StatefulSetPodSummaryApplyConfiguration) *StatefulSetStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithPods")
+		}
+		b.Pods = append(b.Pods, *values[i])
+	}
+	return b
+}
+
+// WithOrdinals adds the given value to the Ordinals field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Ordinals field.
+func (b *StatefulSetStatusApplyConfiguration) WithOrdinals(values ...int32) *StatefulSetStatusApplyConfiguration {
+	for i := range values {
+		b.Ordinals = append(b.Ordinals, values[i])
+	}
+	return b
+}
+
+// WithDeleteSlots adds the given value to the DeleteSlots field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the DeleteSlots field.
+func (b *StatefulSetStatusApplyConfiguration) WithDeleteSlots(values ...int32) *StatefulSetStatusApplyConfiguration {
+	for i := range values {
+		b.DeleteSlots = append(b.DeleteSlots, values[i])
+	}
+	return b
+}
+
+// WithIgnoredDeleteSlots adds the given value to the IgnoredDeleteSlots field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the IgnoredDeleteSlots field.
+func (b *StatefulSetStatusApplyConfiguration) WithIgnoredDeleteSlots(values ...int32) *StatefulSetStatusApplyConfiguration {
+	for i := range values {
+		b.IgnoredDeleteSlots = append(b.IgnoredDeleteSlots, values[i])
+	}
+	return b
+}
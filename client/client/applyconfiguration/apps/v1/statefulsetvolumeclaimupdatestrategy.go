@@ -0,0 +1,52 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	v1 "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+)
+
+// StatefulSetVolumeClaimUpdateStrategyApplyConfiguration represents an declarative configuration of the StatefulSetVolumeClaimUpdateStrategy type for use
+// with apply.
+type StatefulSetVolumeClaimUpdateStrategyApplyConfiguration struct {
+	Type                                *v1.VolumeClaimUpdateStrategyType `json:"type,omitempty"`
+	RestartPodOnFileSystemResizePending *bool                             `json:"restartPodOnFileSystemResizePending,omitempty"`
+}
+
+// StatefulSetVolumeClaimUpdateStrategyApplyConfiguration constructs an declarative configuration of the StatefulSetVolumeClaimUpdateStrategy type for use with
+// apply.
+func StatefulSetVolumeClaimUpdateStrategy() *StatefulSetVolumeClaimUpdateStrategyApplyConfiguration {
+	return &StatefulSetVolumeClaimUpdateStrategyApplyConfiguration{}
+}
+
+// WithType sets the Type field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Type field is set to the value of the last call.
+func (b *StatefulSetVolumeClaimUpdateStrategyApplyConfiguration) WithType(value v1.VolumeClaimUpdateStrategyType) *StatefulSetVolumeClaimUpdateStrategyApplyConfiguration {
+	b.Type = &value
+	return b
+}
+
+// WithRestartPodOnFileSystemResizePending sets the RestartPodOnFileSystemResizePending field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RestartPodOnFileSystemResizePending field is set to the value of the last call.
+func (b *StatefulSetVolumeClaimUpdateStrategyApplyConfiguration) WithRestartPodOnFileSystemResizePending(value bool) *StatefulSetVolumeClaimUpdateStrategyApplyConfiguration {
+	b.RestartPodOnFileSystemResizePending = &value
+	return b
+}
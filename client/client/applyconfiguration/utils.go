@@ -29,18 +29,32 @@ import (
 func ForKind(kind schema.GroupVersionKind) interface{} {
 	switch kind {
 	// Group=apps.pingcap.com, Version=v1
+	case v1.SchemeGroupVersion.WithKind("OrdinalOverride"):
+		return &appsv1.OrdinalOverrideApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("RecreateBackoff"):
+		return &appsv1.RecreateBackoffApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("RollingUpdateStatefulSetStrategy"):
 		return &appsv1.RollingUpdateStatefulSetStrategyApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("StatefulSet"):
 		return &appsv1.StatefulSetApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("StatefulSetBlockedBy"):
+		return &appsv1.StatefulSetBlockedByApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("StatefulSetCondition"):
 		return &appsv1.StatefulSetConditionApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("StatefulSetPodDisruptionBudget"):
+		return &appsv1.StatefulSetPodDisruptionBudgetApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("StatefulSetPodSummary"):
+		return &appsv1.StatefulSetPodSummaryApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("StatefulSetSpec"):
 		return &appsv1.StatefulSetSpecApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("StatefulSetStatus"):
 		return &appsv1.StatefulSetStatusApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("StatefulSetSurge"):
+		return &appsv1.StatefulSetSurgeApplyConfiguration{}
 	case v1.SchemeGroupVersion.WithKind("StatefulSetUpdateStrategy"):
 		return &appsv1.StatefulSetUpdateStrategyApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("StatefulSetVolumeClaimUpdateStrategy"):
+		return &appsv1.StatefulSetVolumeClaimUpdateStrategyApplyConfiguration{}
 
 	}
 	return nil
@@ -18,7 +18,9 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
-	"runtime"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	pcinformers "github.com/pingcap/advanced-statefulset/client/client/informers/externalversions"
@@ -29,9 +31,10 @@ import (
 	"github.com/pingcap/advanced-statefulset/pkg/version"
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
-	"k8s.io/apimachinery/pkg/util/wait"
+	kubeapps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/metadata/metadatainformer"
 	cliflag "k8s.io/component-base/cli/flag"
 	"k8s.io/component-base/cli/globalflag"
 	"k8s.io/component-base/term"
@@ -48,60 +51,140 @@ func ResyncPeriod(c *config.CompletedConfig) func() time.Duration {
 	}
 }
 
-// Run runs the controller-manager. This should never exit.
-func Run(cc *config.CompletedConfig, stopCh <-chan struct{}) error {
+// Run runs the controller-manager until ctx is done. The syncs in flight are then drained for up to the shutdown
+// grace period, and the leases are released so that another replica takes over at once.
+func Run(ctx context.Context, cc *config.CompletedConfig) error {
 	// To help debugging, immediately log version
 	klog.Infof("Version: %+v", version.Get())
 
 	run := func(ctx context.Context) {
-		informerFactory := informers.NewSharedInformerFactory(cc.Client, cc.GenericComponent.MinResyncPeriod.Duration)
-		pcInformerFactory := pcinformers.NewSharedInformerFactory(cc.PCClient, cc.GenericComponent.MinResyncPeriod.Duration)
-		stsCtrl := statefulset.NewStatefulSetController(
-			informerFactory.Core().V1().Pods(),
-			pcInformerFactory.Apps().V1().StatefulSets(),
-			informerFactory.Core().V1().PersistentVolumeClaims(),
-			informerFactory.Apps().V1().ControllerRevisions(),
-			cc.Client,
-			cc.PCClient,
-		)
-		go stsCtrl.Run(runtime.NumCPU(), ctx.Done())
-		// Start informers after all event listeners are registered.
-		informerFactory.Start(ctx.Done())
-		pcInformerFactory.Start(ctx.Done())
+		namespaces := cc.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{metav1.NamespaceAll}
+		}
+		var controllers sync.WaitGroup
+		for i, namespace := range namespaces {
+			controllers.Add(1)
+			go func(namespace string, workers int) {
+				defer controllers.Done()
+				runStatefulSetController(ctx, cc, namespace, workers)
+			}(namespace, namespaceWorkers(cc.ConcurrentStatefulSetSyncs, len(namespaces), i))
+		}
 		<-ctx.Done()
+		klog.Infof("Draining the syncs in flight for up to %v", cc.ShutdownGracePeriod)
+		if !waitTimeout(&controllers, cc.ShutdownGracePeriod) {
+			klog.Warningf("Syncs still in flight after the shutdown grace period of %v", cc.ShutdownGracePeriod)
+		}
 	}
 
-	ctx, cancel := context.WithCancel(context.TODO()) // TODO once Run() accepts a context, it should be used here
-	defer cancel()
+	// If sharding is enabled, all replicas are active and reconcile the StatefulSets of the shards they hold.
+	if cc.Shards != nil {
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			run(ctx)
+		}()
+		// the shards are given up once their syncs in flight are done
+		cc.Shards.Run(ctx)
+		<-stopped
+		return nil
+	}
 
+	// If leader election is enabled, run while holding the lease.
+	if cc.LeaderElection != nil {
+		return runWithLeaderElection(ctx, *cc.LeaderElection, run)
+	}
+
+	run(ctx)
+	return nil
+}
+
+// waitTimeout waits for wg for up to timeout, it returns false if wg is not done in time.
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
 	go func() {
-		select {
-		case <-stopCh:
-			cancel()
-		case <-ctx.Done():
-		}
+		wg.Wait()
+		close(done)
 	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
 
-	// If leader election is enabled, runCommand via LeaderElector until done and exit.
-	if cc.LeaderElection != nil {
-		cc.LeaderElection.Callbacks = leaderelection.LeaderCallbacks{
-			OnStartedLeading: run,
-			OnStoppedLeading: func() {
-				klog.Fatalf("leaderelection lost")
-			},
-		}
-		leaderElector, err := leaderelection.NewLeaderElector(*cc.LeaderElection)
-		if err != nil {
-			return fmt.Errorf("couldn't create leader elector: %v", err)
-		}
-
-		leaderElector.Run(ctx)
+// setupSignalContext returns a context which is done on SIGTERM or SIGINT. The process exits on a second signal.
+func setupSignalContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	go func() {
+		<-signals
+		klog.Infof("Shutting down")
+		cancel()
+		<-signals
+		os.Exit(1)
+	}()
+	return ctx
+}
 
-		return fmt.Errorf("lost lease")
+// namespaceWorkers returns the number of workers of the i-th of n namespace controllers, so that the controllers
+// sync at most total StatefulSets concurrently. Each controller has at least one worker.
+func namespaceWorkers(total, n, i int) int {
+	workers := total / n
+	if i < total%n {
+		workers++
 	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
 
-	run(ctx)
-	return fmt.Errorf("finished without leader elect")
+// runStatefulSetController runs a StatefulSet controller with workers workers for the StatefulSets in namespace,
+// with informers restricted to namespace, until ctx is done and its syncs in flight are drained.
+func runStatefulSetController(ctx context.Context, cc *config.CompletedConfig, namespace string, workers int) {
+	resync := cc.GenericComponent.MinResyncPeriod.Duration
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(cc.Client, resync, informers.WithNamespace(namespace))
+	pcInformerFactory := pcinformers.NewSharedInformerFactoryWithOptions(cc.PCClient, resync,
+		pcinformers.WithNamespace(namespace),
+		pcinformers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = cc.SetSelector.String()
+		}))
+	// the informers of the objects created for StatefulSets are restricted by the informer selector
+	tweakListOptions := func(options *metav1.ListOptions) {
+		options.LabelSelector = cc.InformerSelector.String()
+	}
+	filteredInformerFactory := informers.NewSharedInformerFactoryWithOptions(cc.Client, resync,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(tweakListOptions))
+	metadataInformerFactory := metadatainformer.NewFilteredSharedInformerFactory(cc.MetadataClient, resync,
+		namespace, tweakListOptions)
+	// the controller only waits for revisions to be synced, it reads them from the API server
+	revInformer := filteredInformerFactory.Apps().V1().ControllerRevisions().Informer()
+	if cc.MetadataOnlyRevisionInformer {
+		revInformer = metadataInformerFactory.ForResource(kubeapps.SchemeGroupVersion.WithResource("controllerrevisions")).Informer()
+	}
+	stsCtrl := statefulset.NewStatefulSetController(
+		filteredInformerFactory.Core().V1().Pods(),
+		pcInformerFactory.Apps().V1().StatefulSets(),
+		filteredInformerFactory.Core().V1().PersistentVolumeClaims(),
+		revInformer,
+		informerFactory.Policy().V1().PodDisruptionBudgets(),
+		cc.InformerSelector,
+		cc.Shards,
+		cc.StatefulSetQueue,
+		cc.DryRun,
+		cc.Client,
+		cc.PCClient,
+	)
+	// Start informers after all event listeners are registered.
+	informerFactory.Start(ctx.Done())
+	filteredInformerFactory.Start(ctx.Done())
+	metadataInformerFactory.Start(ctx.Done())
+	pcInformerFactory.Start(ctx.Done())
+	stsCtrl.Run(workers, ctx.Done())
 }
 
 func NewControllerManagerCommand() *cobra.Command {
@@ -113,13 +196,22 @@ func NewControllerManagerCommand() *cobra.Command {
 			verflag.PrintAndExitIfRequested()
 			cliflag.PrintFlags(flag.CommandLine)
 
+			if err := opts.ApplyConfigFile(cmd.Flags()); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			if err := opts.Validate(); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+
 			c, err := opts.Config()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "%v\n", err)
 				os.Exit(1)
 			}
 
-			if err := Run(c.Complete(), wait.NeverStop); err != nil {
+			if err := Run(setupSignalContext(), c.Complete()); err != nil {
 				fmt.Fprintf(os.Stderr, "%v\n", err)
 				os.Exit(1)
 			}
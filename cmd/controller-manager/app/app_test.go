@@ -0,0 +1,40 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNamespaceWorkers(t *testing.T) {
+	tests := []struct {
+		total, namespaces int
+		want              []int
+	}{
+		{total: 4, namespaces: 1, want: []int{4}},
+		{total: 4, namespaces: 2, want: []int{2, 2}},
+		{total: 5, namespaces: 3, want: []int{2, 2, 1}},
+		{total: 2, namespaces: 3, want: []int{1, 1, 1}},
+	}
+	for _, tt := range tests {
+		var got []int
+		for i := 0; i < tt.namespaces; i++ {
+			got = append(got, namespaceWorkers(tt.total, tt.namespaces, i))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%d workers across %d namespaces: got %v, want %v", tt.total, tt.namespaces, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/klog/v2"
+)
+
+// runWithLeaderElection runs run while the lease of config is held, until ctx is done or the lease is lost. run must
+// return once its context is done and the work guarded by the lease is stopped. On shutdown, the lease is only
+// released once run returned, so that another replica does not take over while the syncs of this one are in flight.
+// No error is returned if ctx is done.
+func runWithLeaderElection(ctx context.Context, config leaderelection.LeaderElectionConfig, run func(context.Context)) error {
+	// the elector is cancelled once run returned, so that the lease is released after it
+	electorCtx, cancelElector := context.WithCancel(context.Background())
+	defer cancelElector()
+
+	var lock sync.Mutex
+	leading, stopping := false, false
+	stopped := make(chan struct{})
+
+	config.ReleaseOnCancel = true
+	config.Callbacks = leaderelection.LeaderCallbacks{
+		OnStartedLeading: func(leaderCtx context.Context) {
+			lock.Lock()
+			if stopping {
+				lock.Unlock()
+				return
+			}
+			leading = true
+			lock.Unlock()
+			defer close(stopped)
+
+			// run is stopped on shutdown, or when the lease is lost
+			runCtx, cancel := context.WithCancel(leaderCtx)
+			defer cancel()
+			go func() {
+				select {
+				case <-ctx.Done():
+					cancel()
+				case <-runCtx.Done():
+				}
+			}()
+			run(runCtx)
+			cancelElector()
+		},
+		OnStoppedLeading: func() {
+			klog.Infof("Stopped leading %s", config.Lock.Describe())
+		},
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-electorCtx.Done():
+			return
+		}
+		lock.Lock()
+		stopping = true
+		wasLeading := leading
+		lock.Unlock()
+		// the lease is released by OnStartedLeading once run returned
+		if !wasLeading {
+			cancelElector()
+		}
+	}()
+
+	leaderElector, err := leaderelection.NewLeaderElector(config)
+	if err != nil {
+		return fmt.Errorf("couldn't create leader elector: %v", err)
+	}
+	leaderElector.Run(electorCtx)
+
+	lock.Lock()
+	wasLeading := leading
+	lock.Unlock()
+	if wasLeading {
+		<-stopped
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return fmt.Errorf("lost lease")
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// fakeLock is an in-memory resourcelock.Interface which records whether the lease was released after the guarded work
+// was drained.
+type fakeLock struct {
+	sync.Mutex
+	identity string
+	record   *resourcelock.LeaderElectionRecord
+	drained  func() bool
+	// releasedAfterDrain is set when the lease is released, to whether the guarded work was drained at that time
+	released           bool
+	releasedAfterDrain bool
+}
+
+var _ resourcelock.Interface = &fakeLock{}
+
+func (l *fakeLock) Get(ctx context.Context) (*resourcelock.LeaderElectionRecord, []byte, error) {
+	l.Lock()
+	defer l.Unlock()
+	if l.record == nil {
+		return nil, nil, apierrors.NewNotFound(schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}, "controller")
+	}
+	record := *l.record
+	return &record, []byte(record.HolderIdentity + record.RenewTime.String()), nil
+}
+
+func (l *fakeLock) Create(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	l.Lock()
+	defer l.Unlock()
+	l.record = &ler
+	return nil
+}
+
+func (l *fakeLock) Update(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	l.Lock()
+	defer l.Unlock()
+	l.record = &ler
+	if ler.HolderIdentity == "" && !l.released {
+		l.released = true
+		l.releasedAfterDrain = l.drained()
+	}
+	return nil
+}
+
+func (l *fakeLock) RecordEvent(string) {}
+
+func (l *fakeLock) Identity() string {
+	return l.identity
+}
+
+func (l *fakeLock) Describe() string {
+	return "default/controller"
+}
+
+func TestRunWithLeaderElection(t *testing.T) {
+	var lock sync.Mutex
+	started, drained := false, false
+	startedCh := make(chan struct{})
+	leaseLock := &fakeLock{
+		identity: "a",
+		drained: func() bool {
+			lock.Lock()
+			defer lock.Unlock()
+			return drained
+		},
+	}
+	config := leaderelection.LeaderElectionConfig{
+		Lock:          leaseLock,
+		LeaseDuration: 2 * time.Second,
+		RenewDeadline: time.Second,
+		RetryPeriod:   100 * time.Millisecond,
+	}
+	run := func(ctx context.Context) {
+		lock.Lock()
+		started = true
+		lock.Unlock()
+		close(startedCh)
+		<-ctx.Done()
+		// a sync in flight on shutdown
+		time.Sleep(200 * time.Millisecond)
+		lock.Lock()
+		drained = true
+		lock.Unlock()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() {
+		errCh <- runWithLeaderElection(ctx, config, run)
+	}()
+	select {
+	case <-startedCh:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("run should be started once the lease is acquired")
+	}
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("unexpected error on shutdown: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("runWithLeaderElection should return on shutdown")
+	}
+
+	leaseLock.Lock()
+	defer leaseLock.Unlock()
+	if !leaseLock.released {
+		t.Fatalf("the lease should be released on shutdown")
+	}
+	if !leaseLock.releasedAfterDrain {
+		t.Errorf("the lease should only be released once run returned")
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if !started || !drained {
+		t.Errorf("run should be started and drained, started: %v, drained: %v", started, drained)
+	}
+}
+
+func TestRunWithLeaderElectionNotLeading(t *testing.T) {
+	leaseLock := &fakeLock{identity: "a", drained: func() bool { return true }}
+	// the lease is held by another replica
+	leaseLock.record = &resourcelock.LeaderElectionRecord{
+		HolderIdentity:       "b",
+		LeaseDurationSeconds: 60,
+	}
+	config := leaderelection.LeaderElectionConfig{
+		Lock:          leaseLock,
+		LeaseDuration: 2 * time.Second,
+		RenewDeadline: time.Second,
+		RetryPeriod:   100 * time.Millisecond,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		cancel()
+	}()
+	err := runWithLeaderElection(ctx, config, func(context.Context) {
+		t.Errorf("run should not be started without the lease")
+	})
+	if err != nil {
+		t.Errorf("unexpected error on shutdown: %v", err)
+	}
+	if leaseLock.record.HolderIdentity != "b" {
+		t.Errorf("the lease of another replica should not be released, holder: %q", leaseLock.record.HolderIdentity)
+	}
+}
@@ -14,9 +14,15 @@
 package config
 
 import (
+	"time"
+
 	pcclientset "github.com/pingcap/advanced-statefulset/client/client/clientset/versioned"
 	"github.com/pingcap/advanced-statefulset/pkg/component/config"
+	"github.com/pingcap/advanced-statefulset/pkg/controller/sharding"
+	"github.com/pingcap/advanced-statefulset/pkg/controller/statefulset"
+	"k8s.io/apimachinery/pkg/labels"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/record"
@@ -32,10 +38,35 @@ type Config struct {
 	// the general pingcap client
 	PCClient *pcclientset.Clientset
 
+	// ConcurrentStatefulSetSyncs is the number of StatefulSets synced concurrently.
+	ConcurrentStatefulSetSyncs int
+	// StatefulSetQueue configures the work queue of the StatefulSets.
+	StatefulSetQueue statefulset.QueueConfig
+	// ShutdownGracePeriod is the time the syncs in flight are drained for on shutdown.
+	ShutdownGracePeriod time.Duration
+
+	// the client for metadata-only informers
+	MetadataClient metadata.Interface
+
+	// InformerSelector restricts the informers of Pods, PersistentVolumeClaims and ControllerRevisions.
+	InformerSelector labels.Selector
+	// MetadataOnlyRevisionInformer watches ControllerRevisions with a metadata-only informer.
+	MetadataOnlyRevisionInformer bool
+
+	// Namespaces are the namespaces of the StatefulSets to reconcile, all namespaces if empty.
+	Namespaces []string
+	// SetSelector restricts the StatefulSets to reconcile.
+	SetSelector labels.Selector
+
 	// the client only used for leader election
 	// LeaderElectionClient *clientset.Clientset
 	// LeaderElection is optional.
 	LeaderElection *leaderelection.LeaderElectionConfig
+	// Shards spreads the StatefulSets across the replicas instead of the leader election, it is optional.
+	Shards *sharding.Manager
+
+	// DryRun records the writes of the controller as logs and events instead of performing them.
+	DryRun bool
 
 	// the rest config for the master
 	Kubeconfig *rest.Config
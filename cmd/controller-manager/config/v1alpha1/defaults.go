@@ -0,0 +1,78 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/pingcap/advanced-statefulset/pkg/component/config"
+	"github.com/pingcap/advanced-statefulset/pkg/controller/statefulset"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	componentbaseconfigv1alpha1 "k8s.io/component-base/config/v1alpha1"
+	utilpointer "k8s.io/utils/pointer"
+)
+
+// SetDefaults_ControllerManagerConfiguration sets the defaults of the fields of obj which are not set. They are the
+// defaults of the flags.
+func SetDefaults_ControllerManagerConfiguration(obj *ControllerManagerConfiguration) {
+	generic := config.NewDefaultGenericComponentConfiguration()
+	if obj.ContentType == "" {
+		obj.ContentType = generic.ContentType
+	}
+	if obj.KubeAPIQPS == 0 {
+		obj.KubeAPIQPS = generic.KubeAPIQPS
+	}
+	if obj.KubeAPIBurst == 0 {
+		obj.KubeAPIBurst = generic.KubeAPIBurst
+	}
+	if obj.MinResyncPeriod.Duration == 0 {
+		obj.MinResyncPeriod = generic.MinResyncPeriod
+	}
+	if obj.LeaderElection.ResourceLock == "" {
+		obj.LeaderElection.ResourceLock = resourcelock.LeasesResourceLock
+	}
+	componentbaseconfigv1alpha1.RecommendedDefaultLeaderElectionConfiguration(&obj.LeaderElection)
+	if obj.ConcurrentStatefulSetSyncs == nil {
+		obj.ConcurrentStatefulSetSyncs = utilpointer.Int32(int32(runtime.NumCPU()))
+	}
+	queue := statefulset.DefaultQueueConfig()
+	if obj.StatefulSetQueue.BaseDelay.Duration == 0 {
+		obj.StatefulSetQueue.BaseDelay.Duration = queue.BaseDelay
+	}
+	if obj.StatefulSetQueue.MaxDelay.Duration == 0 {
+		obj.StatefulSetQueue.MaxDelay.Duration = queue.MaxDelay
+	}
+	if obj.StatefulSetQueue.QPS == 0 {
+		obj.StatefulSetQueue.QPS = queue.QPS
+	}
+	if obj.StatefulSetQueue.Burst == 0 {
+		obj.StatefulSetQueue.Burst = int32(queue.Burst)
+	}
+	if obj.StatefulSetQueue.Fair == nil {
+		obj.StatefulSetQueue.Fair = utilpointer.Bool(queue.Fair)
+	}
+	if obj.ShutdownGracePeriod.Duration == 0 {
+		obj.ShutdownGracePeriod.Duration = 20 * time.Second
+	}
+	if obj.MetadataOnlyRevisionInformer == nil {
+		obj.MetadataOnlyRevisionInformer = utilpointer.Bool(false)
+	}
+	if obj.DryRun == nil {
+		obj.DryRun = utilpointer.Bool(false)
+	}
+	if obj.Shards == nil {
+		obj.Shards = utilpointer.Int32(0)
+	}
+}
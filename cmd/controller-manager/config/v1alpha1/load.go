@@ -0,0 +1,47 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Kind is the kind of ControllerManagerConfiguration.
+const Kind = "ControllerManagerConfiguration"
+
+// LoadConfigFile loads and defaults the ControllerManagerConfiguration in the YAML or JSON file at path. Unknown
+// fields are rejected so that typos are not silently ignored.
+func LoadConfigFile(path string) (*ControllerManagerConfiguration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file %q: %v", path, err)
+	}
+	return decode(data)
+}
+
+func decode(data []byte) (*ControllerManagerConfiguration, error) {
+	obj := &ControllerManagerConfiguration{}
+	if err := yaml.UnmarshalStrict(data, obj); err != nil {
+		return nil, fmt.Errorf("unable to decode config: %v", err)
+	}
+	if obj.APIVersion != SchemeGroupVersion.String() || obj.Kind != Kind {
+		return nil, fmt.Errorf("unsupported config %s, %s with apiVersion %s is expected",
+			obj.GroupVersionKind(), Kind, SchemeGroupVersion)
+	}
+	SetDefaults_ControllerManagerConfiguration(obj)
+	return obj, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	cfg, err := decode([]byte(`
+apiVersion: controllermanager.config.apps.pingcap.com/v1alpha1
+kind: ControllerManagerConfiguration
+kubeAPIQPS: 50
+concurrentStatefulSetSyncs: 8
+leaderElection:
+  leaderElect: true
+  resourceName: advanced-statefulset-controller
+  resourceNamespace: advanced-statefulset
+featureGates:
+  PodEviction: false
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.KubeAPIQPS != 50 || cfg.KubeAPIBurst != 30 {
+		t.Errorf("got QPS %v and burst %d, want 50 and the default 30", cfg.KubeAPIQPS, cfg.KubeAPIBurst)
+	}
+	if *cfg.ConcurrentStatefulSetSyncs != 8 {
+		t.Errorf("got %d concurrent syncs, want 8", *cfg.ConcurrentStatefulSetSyncs)
+	}
+	if cfg.MinResyncPeriod.Duration != 12*time.Hour {
+		t.Errorf("got min resync period %v, want the default 12h", cfg.MinResyncPeriod.Duration)
+	}
+	if cfg.LeaderElection.LeaseDuration.Duration != 15*time.Second || cfg.LeaderElection.ResourceLock != "leases" {
+		t.Errorf("leader election should be defaulted, got %+v", cfg.LeaderElection)
+	}
+	if cfg.FeatureGates["PodEviction"] {
+		t.Errorf("PodEviction should be disabled")
+	}
+
+	cfg, err = decode([]byte(`
+apiVersion: controllermanager.config.apps.pingcap.com/v1alpha1
+kind: ControllerManagerConfiguration
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *cfg.ConcurrentStatefulSetSyncs != int32(runtime.NumCPU()) || *cfg.Shards != 0 || *cfg.MetadataOnlyRevisionInformer || *cfg.DryRun {
+		t.Errorf("empty config should be defaulted, got %+v", cfg)
+	}
+	if queue := cfg.StatefulSetQueue; queue.BaseDelay.Duration != 5*time.Millisecond || queue.QPS != 10 || queue.Burst != 100 || !*queue.Fair {
+		t.Errorf("queue config should be defaulted, got %+v", queue)
+	}
+	if cfg.ShutdownGracePeriod.Duration != 20*time.Second {
+		t.Errorf("got shutdown grace period %v, want the default 20s", cfg.ShutdownGracePeriod.Duration)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name: "unknown field",
+			config: `
+apiVersion: controllermanager.config.apps.pingcap.com/v1alpha1
+kind: ControllerManagerConfiguration
+concurrentSyncs: 8
+`,
+			err: "unknown field",
+		},
+		{
+			name: "unknown kind",
+			config: `
+apiVersion: controllermanager.config.apps.pingcap.com/v1alpha1
+kind: KubeControllerManagerConfiguration
+`,
+			err: "unsupported config",
+		},
+		{
+			name: "unknown version",
+			config: `
+apiVersion: controllermanager.config.apps.pingcap.com/v1
+kind: ControllerManagerConfiguration
+`,
+			err: "unsupported config",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decode([]byte(tt.config)); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v1alpha1 is the v1alpha1 version of the configuration file of the controller manager.
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	componentbaseconfigv1alpha1 "k8s.io/component-base/config/v1alpha1"
+)
+
+// GroupName is the group name of the configuration of the controller manager.
+const GroupName = "controllermanager.config.apps.pingcap.com"
+
+// SchemeGroupVersion is the group version of ControllerManagerConfiguration.
+var SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: "v1alpha1"}
+
+// ControllerManagerConfiguration configures the advanced-statefulset-controller-manager. It is loaded from the file
+// given with --config, flags set on the command line override its fields.
+type ControllerManagerConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Kubeconfig is the path to the kubeconfig file with the location of and the credentials for the API server.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// ContentType is the content type of requests sent to the API server.
+	ContentType string `json:"contentType,omitempty"`
+	// KubeAPIQPS is the QPS to use while talking with the API server.
+	KubeAPIQPS float32 `json:"kubeAPIQPS,omitempty"`
+	// KubeAPIBurst is the burst to use while talking with the API server.
+	KubeAPIBurst int32 `json:"kubeAPIBurst,omitempty"`
+	// MinResyncPeriod is the resync period in reflectors, it will be random between MinResyncPeriod and
+	// 2*MinResyncPeriod.
+	MinResyncPeriod metav1.Duration `json:"minResyncPeriod,omitempty"`
+	// LeaderElection configures the leader election, and the leases of the shards.
+	LeaderElection componentbaseconfigv1alpha1.LeaderElectionConfiguration `json:"leaderElection"`
+
+	// ConcurrentStatefulSetSyncs is the number of StatefulSets synced concurrently, divided across the namespaces.
+	// Defaults to the number of CPUs.
+	ConcurrentStatefulSetSyncs *int32 `json:"concurrentStatefulSetSyncs,omitempty"`
+	// StatefulSetQueue configures the rate limiting and the fairness of the work queue of the StatefulSets.
+	StatefulSetQueue QueueConfiguration `json:"statefulSetQueue"`
+	// ShutdownGracePeriod is the time the syncs in flight are drained for on SIGTERM, before the leases are
+	// released. Defaults to 20s.
+	ShutdownGracePeriod metav1.Duration `json:"shutdownGracePeriod,omitempty"`
+
+	// InformerLabelSelector restricts the informers of Pods, PersistentVolumeClaims and ControllerRevisions.
+	InformerLabelSelector string `json:"informerLabelSelector,omitempty"`
+	// MetadataOnlyRevisionInformer watches ControllerRevisions with a metadata-only informer.
+	MetadataOnlyRevisionInformer *bool `json:"metadataOnlyRevisionInformer,omitempty"`
+	// Namespaces restricts the StatefulSets to reconcile to these namespaces, all namespaces if empty.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// SetSelector restricts the StatefulSets to reconcile to the ones matching it.
+	SetSelector string `json:"setSelector,omitempty"`
+	// Shards is the number of shards the StatefulSets are spread across, only the leader is active if it is 0.
+	Shards *int32 `json:"shards,omitempty"`
+	// DryRun records the writes of the controller as logs and events instead of performing them.
+	DryRun *bool `json:"dryRun,omitempty"`
+
+	// FeatureGates enables or disables the advanced behaviors of the controller by name.
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+}
+
+// QueueConfiguration configures the work queue of the StatefulSets. The retries of a StatefulSet whose sync failed
+// are delayed exponentially from BaseDelay to MaxDelay, and the retries of all StatefulSets are limited to QPS.
+type QueueConfiguration struct {
+	// BaseDelay is the delay of the first retry of a StatefulSet whose sync failed.
+	BaseDelay metav1.Duration `json:"baseDelay,omitempty"`
+	// MaxDelay is the maximum delay of the retries of a StatefulSet.
+	MaxDelay metav1.Duration `json:"maxDelay,omitempty"`
+	// QPS is the rate of the retries of all StatefulSets.
+	QPS float64 `json:"qps,omitempty"`
+	// Burst is the number of retries of all StatefulSets allowed above QPS.
+	Burst int32 `json:"burst,omitempty"`
+	// Fair dispatches the StatefulSets in a round robin across namespaces. Defaults to true.
+	Fair *bool `json:"fair,omitempty"`
+}
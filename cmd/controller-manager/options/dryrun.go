@@ -0,0 +1,62 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"net/http"
+	"strings"
+)
+
+// dryRunRoundTripper sends the mutating requests as server-side dry runs, so that they are validated by the API server
+// but not persisted. Events are sent as is, they report what the controller would do.
+type dryRunRoundTripper struct {
+	rt http.RoundTripper
+}
+
+func newDryRunRoundTripper(rt http.RoundTripper) http.RoundTripper {
+	return &dryRunRoundTripper{rt}
+}
+
+func (rt *dryRunRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return rt.rt.RoundTrip(req)
+	}
+	if isEventRequest(req) {
+		return rt.rt.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	query := req.URL.Query()
+	query.Set("dryRun", "All")
+	req.URL.RawQuery = query.Encode()
+	return rt.rt.RoundTrip(req)
+}
+
+// isEventRequest returns true if req is a request for core or events.k8s.io Events.
+func isEventRequest(req *http.Request) bool {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	// skip the /api/<version> or /apis/<group>/<version> prefix, and the namespace
+	switch {
+	case len(segments) >= 2 && segments[0] == "api":
+		segments = segments[2:]
+	case len(segments) >= 3 && segments[0] == "apis":
+		segments = segments[3:]
+	default:
+		return false
+	}
+	if len(segments) >= 3 && segments[0] == "namespaces" {
+		segments = segments[2:]
+	}
+	return len(segments) > 0 && segments[0] == "events"
+}
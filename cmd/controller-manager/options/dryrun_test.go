@@ -0,0 +1,60 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type recordingRoundTripper struct {
+	req *http.Request
+}
+
+func (rt *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.req = req
+	return &http.Response{StatusCode: http.StatusOK}, nil
+}
+
+func TestDryRunRoundTripper(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		dryRun bool
+	}{
+		{method: http.MethodGet, path: "/api/v1/namespaces/default/pods"},
+		{method: http.MethodPost, path: "/api/v1/namespaces/default/pods", dryRun: true},
+		{method: http.MethodPut, path: "/apis/apps.pingcap.com/v1/namespaces/default/statefulsets/foo/status", dryRun: true},
+		{method: http.MethodDelete, path: "/api/v1/namespaces/default/persistentvolumeclaims/datadir-foo-0", dryRun: true},
+		{method: http.MethodPost, path: "/api/v1/namespaces/default/pods/foo-0/eviction", dryRun: true},
+		{method: http.MethodPost, path: "/api/v1/namespaces/events/pods", dryRun: true},
+		{method: http.MethodPost, path: "/api/v1/namespaces/default/events"},
+		{method: http.MethodPatch, path: "/api/v1/namespaces/default/events/foo.1"},
+		{method: http.MethodPost, path: "/apis/events.k8s.io/v1/namespaces/default/events"},
+	}
+	for _, test := range tests {
+		recorder := &recordingRoundTripper{}
+		req := httptest.NewRequest(test.method, "https://localhost"+test.path, nil)
+		if _, err := newDryRunRoundTripper(recorder).RoundTrip(req); err != nil {
+			t.Fatal(err)
+		}
+		if dryRun := recorder.req.URL.Query().Get("dryRun") == "All"; dryRun != test.dryRun {
+			t.Errorf("%s %s: got dry run %v, want %v", test.method, test.path, dryRun, test.dryRun)
+		}
+		if req.URL.RawQuery != "" {
+			t.Errorf("%s %s: the original request should not be modified", test.method, test.path)
+		}
+	}
+}
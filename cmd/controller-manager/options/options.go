@@ -15,19 +15,31 @@ package options
 
 import (
 	"fmt"
+	"hash/fnv"
 	"os"
+	"runtime"
+	"strings"
 	"time"
 
 	pcclientset "github.com/pingcap/advanced-statefulset/client/client/clientset/versioned"
 	controllermanagerconfig "github.com/pingcap/advanced-statefulset/cmd/controller-manager/config"
+	"github.com/pingcap/advanced-statefulset/cmd/controller-manager/config/v1alpha1"
 	"github.com/pingcap/advanced-statefulset/pkg/component/config"
 	"github.com/pingcap/advanced-statefulset/pkg/component/options"
+	"github.com/pingcap/advanced-statefulset/pkg/controller/sharding"
+	"github.com/pingcap/advanced-statefulset/pkg/controller/statefulset"
+	"github.com/pingcap/advanced-statefulset/pkg/features"
+	"github.com/spf13/pflag"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/validation"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/leaderelection"
@@ -35,6 +47,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	cliflag "k8s.io/component-base/cli/flag"
 	componentbaseconfig "k8s.io/component-base/config"
+	componentbaseconfigv1alpha1 "k8s.io/component-base/config/v1alpha1"
 	"k8s.io/klog/v2"
 )
 
@@ -42,30 +55,135 @@ import (
 type ControllerManagerOptions struct {
 	GenericComponent *options.GenericComponentOptions
 
-	Controllers []string
+	// ConfigFile is the path to the ControllerManagerConfiguration file, flags set on the command line override it.
+	ConfigFile string
 
 	Master     string
 	Kubeconfig string
+
+	// ConcurrentStatefulSetSyncs is the number of StatefulSets synced concurrently.
+	ConcurrentStatefulSetSyncs int
+	// StatefulSetQueue configures the rate limiting and the fairness of the work queue of the StatefulSets.
+	StatefulSetQueue statefulset.QueueConfig
+	// ShutdownGracePeriod is the time the syncs in flight are drained for on shutdown, before the leases are
+	// released.
+	ShutdownGracePeriod time.Duration
+
+	// InformerLabelSelector restricts the informers of Pods, PersistentVolumeClaims and ControllerRevisions to the
+	// objects matching it, StatefulSets whose Pods and claims do not match it are not synced.
+	InformerLabelSelector string
+	// MetadataOnlyRevisionInformer watches ControllerRevisions with a metadata-only informer.
+	MetadataOnlyRevisionInformer bool
+
+	// Namespaces restricts the controller to the StatefulSets in these namespaces, all namespaces if empty.
+	Namespaces []string
+	// SetSelector restricts the controller to the StatefulSets matching it.
+	SetSelector string
+
+	// Shards is the number of shards the StatefulSets are spread across, so that all replicas of the controller
+	// are active. Only the leader is active if it is 0.
+	Shards int
+
+	// DryRun records the writes the controller would make as logs and events instead of performing them.
+	DryRun bool
+
+	// FeatureGates enables or disables the advanced behaviors of the controller by name.
+	FeatureGates map[string]bool
 }
 
 // NewControllerManagerOptions creates a new ControllerManagerOptions with a default config.
 func NewControllerManagerOptions() *ControllerManagerOptions {
 	genericComponetConfig := config.NewDefaultGenericComponentConfiguration()
 	s := ControllerManagerOptions{
-		GenericComponent: options.NewGenericComponentOptions(genericComponetConfig),
+		GenericComponent:           options.NewGenericComponentOptions(genericComponetConfig),
+		ConcurrentStatefulSetSyncs: runtime.NumCPU(),
+		StatefulSetQueue:           statefulset.DefaultQueueConfig(),
+		ShutdownGracePeriod:        20 * time.Second,
 	}
 	return &s
 }
 
 func (s *ControllerManagerOptions) Flags() (nfs cliflag.NamedFlagSets) {
 	fs := nfs.FlagSet("misc")
+	fs.StringVar(&s.ConfigFile, "config", s.ConfigFile, "Path to a ControllerManagerConfiguration file. Flags set on the command line override the values of the file.")
 	fs.StringVar(&s.Master, "master", s.Master, "The address of the Kubernetes API server (overrides any value in kubeconfig).")
 	fs.StringVar(&s.Kubeconfig, "kubeconfig", s.Kubeconfig, "Path to kubeconfig file with authorization and master location information.")
+	fs.IntVar(&s.ConcurrentStatefulSetSyncs, "concurrent-statefulset-syncs", s.ConcurrentStatefulSetSyncs, "The number of StatefulSets that are allowed to sync concurrently. Defaults to the number of CPUs. With several --namespace, the syncs are divided across the namespaces, each of which has at least one.")
+	fs.DurationVar(&s.StatefulSetQueue.BaseDelay, "statefulset-sync-base-delay", s.StatefulSetQueue.BaseDelay, "The delay of the first retry of a StatefulSet whose sync failed, doubled on each failure.")
+	fs.DurationVar(&s.StatefulSetQueue.MaxDelay, "statefulset-sync-max-delay", s.StatefulSetQueue.MaxDelay, "The maximum delay of the retries of a StatefulSet whose sync failed.")
+	fs.Float64Var(&s.StatefulSetQueue.QPS, "statefulset-sync-qps", s.StatefulSetQueue.QPS, "The rate of the retries of all StatefulSets whose sync failed.")
+	fs.IntVar(&s.StatefulSetQueue.Burst, "statefulset-sync-burst", s.StatefulSetQueue.Burst, "The number of retries of StatefulSets allowed above --statefulset-sync-qps.")
+	fs.DurationVar(&s.ShutdownGracePeriod, "shutdown-grace-period", s.ShutdownGracePeriod, "The time the syncs in flight are drained for on SIGTERM, before the leases are released. It should be less than the termination grace period of the Pod.")
+	fs.BoolVar(&s.StatefulSetQueue.Fair, "fair-statefulset-queue", s.StatefulSetQueue.Fair, "Dispatch the StatefulSets to the workers in a round robin across namespaces, so that the StatefulSets of one namespace cannot starve the others.")
+	fs.StringVar(&s.InformerLabelSelector, "informer-label-selector", s.InformerLabelSelector, "Only watch Pods, PersistentVolumeClaims and ControllerRevisions matching this label selector to reduce memory usage. StatefulSets whose Pods or PersistentVolumeClaims do not match it are not synced.")
+	fs.BoolVar(&s.MetadataOnlyRevisionInformer, "metadata-only-revision-informer", s.MetadataOnlyRevisionInformer, "Watch ControllerRevisions with a metadata-only informer to reduce memory usage.")
+	fs.StringSliceVar(&s.Namespaces, "namespace", s.Namespaces, "Only reconcile StatefulSets in this namespace, can be repeated. All namespaces are reconciled if unset.")
+	fs.StringVar(&s.SetSelector, "set-selector", s.SetSelector, "Only reconcile StatefulSets matching this label selector.")
+	fs.IntVar(&s.Shards, "shards", s.Shards, "The number of shards the StatefulSets are spread across by the hash of their namespace/name. Each shard is reconciled by the replica holding its lease, so that all replicas are active. Requires --leader-elect. If 0, only the leader is active.")
+	fs.BoolVar(&s.DryRun, "dry-run", s.DryRun, "Log the creates, updates and deletes of Pods and PersistentVolumeClaims and the status updates the controller would make, and record them as events, without performing them. The other writes are sent as server-side dry runs. Cannot be used with --leader-elect.")
+
+	fs.Var(cliflag.NewMapStringBool(&s.FeatureGates), "feature-gates", "A set of key=value pairs that describe feature gates for the advanced behaviors of the controller. Options are:\n"+strings.Join(features.DefaultMutableFeatureGate.KnownFeatures(), "\n"))
 
 	s.GenericComponent.AddFlags(nfs.FlagSet("generic"))
 	return
 }
 
+// ApplyConfigFile loads the ControllerManagerConfiguration file, if any, into the options. The options whose flags
+// are set in fs are kept.
+func (s *ControllerManagerOptions) ApplyConfigFile(fs *pflag.FlagSet) error {
+	if s.ConfigFile == "" {
+		return nil
+	}
+	cfg, err := v1alpha1.LoadConfigFile(s.ConfigFile)
+	if err != nil {
+		return err
+	}
+	var leaderElection componentbaseconfig.LeaderElectionConfiguration
+	if err := componentbaseconfigv1alpha1.Convert_v1alpha1_LeaderElectionConfiguration_To_config_LeaderElectionConfiguration(&cfg.LeaderElection, &leaderElection, nil); err != nil {
+		return err
+	}
+	apply := func(name string, set func()) {
+		if !fs.Changed(name) {
+			set()
+		}
+	}
+	apply("kubeconfig", func() { s.Kubeconfig = cfg.Kubeconfig })
+	apply("kube-api-content-type", func() { s.GenericComponent.ContentType = cfg.ContentType })
+	apply("kube-api-qps", func() { s.GenericComponent.KubeAPIQPS = cfg.KubeAPIQPS })
+	apply("kube-api-burst", func() { s.GenericComponent.KubeAPIBurst = cfg.KubeAPIBurst })
+	apply("min-resync-period", func() { s.GenericComponent.MinResyncPeriod = cfg.MinResyncPeriod })
+	apply("leader-elect", func() { s.GenericComponent.LeaderElection.LeaderElect = leaderElection.LeaderElect })
+	apply("leader-elect-lease-duration", func() { s.GenericComponent.LeaderElection.LeaseDuration = leaderElection.LeaseDuration })
+	apply("leader-elect-renew-deadline", func() { s.GenericComponent.LeaderElection.RenewDeadline = leaderElection.RenewDeadline })
+	apply("leader-elect-retry-period", func() { s.GenericComponent.LeaderElection.RetryPeriod = leaderElection.RetryPeriod })
+	apply("leader-elect-resource-lock", func() { s.GenericComponent.LeaderElection.ResourceLock = leaderElection.ResourceLock })
+	apply("leader-elect-resource-name", func() { s.GenericComponent.LeaderElection.ResourceName = leaderElection.ResourceName })
+	apply("leader-elect-resource-namespace", func() { s.GenericComponent.LeaderElection.ResourceNamespace = leaderElection.ResourceNamespace })
+	apply("concurrent-statefulset-syncs", func() { s.ConcurrentStatefulSetSyncs = int(*cfg.ConcurrentStatefulSetSyncs) })
+	apply("statefulset-sync-base-delay", func() { s.StatefulSetQueue.BaseDelay = cfg.StatefulSetQueue.BaseDelay.Duration })
+	apply("statefulset-sync-max-delay", func() { s.StatefulSetQueue.MaxDelay = cfg.StatefulSetQueue.MaxDelay.Duration })
+	apply("statefulset-sync-qps", func() { s.StatefulSetQueue.QPS = cfg.StatefulSetQueue.QPS })
+	apply("statefulset-sync-burst", func() { s.StatefulSetQueue.Burst = int(cfg.StatefulSetQueue.Burst) })
+	apply("fair-statefulset-queue", func() { s.StatefulSetQueue.Fair = *cfg.StatefulSetQueue.Fair })
+	apply("shutdown-grace-period", func() { s.ShutdownGracePeriod = cfg.ShutdownGracePeriod.Duration })
+	apply("informer-label-selector", func() { s.InformerLabelSelector = cfg.InformerLabelSelector })
+	apply("metadata-only-revision-informer", func() { s.MetadataOnlyRevisionInformer = *cfg.MetadataOnlyRevisionInformer })
+	apply("namespace", func() { s.Namespaces = cfg.Namespaces })
+	apply("set-selector", func() { s.SetSelector = cfg.SetSelector })
+	apply("shards", func() { s.Shards = int(*cfg.Shards) })
+	apply("dry-run", func() { s.DryRun = *cfg.DryRun })
+	// the feature gates of the file and of the flag are merged
+	featureGates := make(map[string]bool, len(cfg.FeatureGates)+len(s.FeatureGates))
+	for name, enabled := range cfg.FeatureGates {
+		featureGates[name] = enabled
+	}
+	for name, enabled := range s.FeatureGates {
+		featureGates[name] = enabled
+	}
+	s.FeatureGates = featureGates
+	return nil
+}
+
 // ApplyTo fills up controller manager config with options.
 func (s *ControllerManagerOptions) ApplyTo(c *controllermanagerconfig.Config, userAgent string) error {
 	if err := s.GenericComponent.ApplyTo(&c.GenericComponent); err != nil {
@@ -73,6 +191,24 @@ func (s *ControllerManagerOptions) ApplyTo(c *controllermanagerconfig.Config, us
 	}
 
 	var err error
+	c.InformerSelector, err = labels.Parse(s.InformerLabelSelector)
+	if err != nil {
+		return fmt.Errorf("invalid informer label selector: %v", err)
+	}
+	c.MetadataOnlyRevisionInformer = s.MetadataOnlyRevisionInformer
+	c.SetSelector, err = labels.Parse(s.SetSelector)
+	if err != nil {
+		return fmt.Errorf("invalid set selector: %v", err)
+	}
+	c.Namespaces = normalizeNamespaces(s.Namespaces)
+	c.ConcurrentStatefulSetSyncs = s.ConcurrentStatefulSetSyncs
+	c.StatefulSetQueue = s.StatefulSetQueue
+	c.ShutdownGracePeriod = s.ShutdownGracePeriod
+	c.DryRun = s.DryRun
+	if err := features.DefaultMutableFeatureGate.SetFromMap(s.FeatureGates); err != nil {
+		return err
+	}
+
 	c.Kubeconfig, err = clientcmd.BuildConfigFromFlags(s.Master, s.Kubeconfig)
 	if err != nil {
 		return err
@@ -80,11 +216,18 @@ func (s *ControllerManagerOptions) ApplyTo(c *controllermanagerconfig.Config, us
 	c.Kubeconfig.ContentConfig.ContentType = s.GenericComponent.ContentType
 	c.Kubeconfig.QPS = s.GenericComponent.KubeAPIQPS
 	c.Kubeconfig.Burst = int(s.GenericComponent.KubeAPIBurst)
+	if s.DryRun {
+		c.Kubeconfig.Wrap(newDryRunRoundTripper)
+	}
 
 	c.Client, err = clientset.NewForConfig(rest.AddUserAgent(c.Kubeconfig, userAgent))
 	if err != nil {
 		return err
 	}
+	c.MetadataClient, err = metadata.NewForConfig(rest.AddUserAgent(c.Kubeconfig, userAgent))
+	if err != nil {
+		return err
+	}
 
 	// CRD does not support protobuf.
 	c.Kubeconfig.ContentConfig.ContentType = "application/json"
@@ -99,7 +242,13 @@ func (s *ControllerManagerOptions) ApplyTo(c *controllermanagerconfig.Config, us
 	// Set up leader election if enabled.
 	var leaderElectionConfig *leaderelection.LeaderElectionConfig
 	if c.GenericComponent.LeaderElection.LeaderElect {
-		leaderElectionConfig, err = makeLeaderElectionConfig(c.GenericComponent.LeaderElection, leaderElectionClient, c.EventRecorder)
+		leaderElection := c.GenericComponent.LeaderElection
+		leaderElection.ResourceName = scopedLeaseName(leaderElection.ResourceName, c.Namespaces, c.SetSelector)
+		if s.Shards > 0 {
+			c.Shards, err = makeShardManager(leaderElection, leaderElectionClient, s.Shards)
+		} else {
+			leaderElectionConfig, err = makeLeaderElectionConfig(leaderElection, leaderElectionClient, c.EventRecorder)
+		}
 		if err != nil {
 			return err
 		}
@@ -113,6 +262,41 @@ func (s *ControllerManagerOptions) ApplyTo(c *controllermanagerconfig.Config, us
 func (s *ControllerManagerOptions) Validate() error {
 	var errs []error
 	errs = append(errs, s.GenericComponent.Validate()...)
+	if s.ConcurrentStatefulSetSyncs < 1 {
+		errs = append(errs, fmt.Errorf("the number of concurrent StatefulSet syncs must be positive, got %d", s.ConcurrentStatefulSetSyncs))
+	}
+	if queue := s.StatefulSetQueue; queue.BaseDelay <= 0 || queue.MaxDelay < queue.BaseDelay {
+		errs = append(errs, fmt.Errorf("the base delay %v of StatefulSet sync retries must be positive and not greater than the max delay %v", queue.BaseDelay, queue.MaxDelay))
+	}
+	if queue := s.StatefulSetQueue; queue.QPS <= 0 || queue.Burst < 1 {
+		errs = append(errs, fmt.Errorf("the QPS %v and the burst %d of StatefulSet sync retries must be positive", queue.QPS, queue.Burst))
+	}
+	if s.ShutdownGracePeriod < 0 {
+		errs = append(errs, fmt.Errorf("the shutdown grace period must not be negative, got %v", s.ShutdownGracePeriod))
+	}
+	if _, err := labels.Parse(s.InformerLabelSelector); err != nil {
+		errs = append(errs, fmt.Errorf("invalid informer label selector: %v", err))
+	}
+	if _, err := labels.Parse(s.SetSelector); err != nil {
+		errs = append(errs, fmt.Errorf("invalid set selector: %v", err))
+	}
+	if err := features.DefaultMutableFeatureGate.DeepCopy().SetFromMap(s.FeatureGates); err != nil {
+		errs = append(errs, err)
+	}
+	if s.Shards < 0 {
+		errs = append(errs, fmt.Errorf("the number of shards must not be negative, got %d", s.Shards))
+	}
+	if s.Shards > 0 && !s.GenericComponent.LeaderElection.LeaderElect {
+		errs = append(errs, fmt.Errorf("--shards requires --leader-elect"))
+	}
+	if s.DryRun && s.GenericComponent.LeaderElection.LeaderElect {
+		errs = append(errs, fmt.Errorf("--dry-run cannot be used with --leader-elect, a dry run must not take the lease of the controller"))
+	}
+	for _, ns := range s.Namespaces {
+		for _, msg := range validation.IsDNS1123Label(ns) {
+			errs = append(errs, fmt.Errorf("invalid namespace %q: %s", ns, msg))
+		}
+	}
 	return utilerrors.NewAggregate(errs)
 }
 
@@ -126,6 +310,32 @@ func (s *ControllerManagerOptions) Config() (*controllermanagerconfig.Config, er
 	return c, nil
 }
 
+// normalizeNamespaces returns the sorted and deduplicated namespaces. A nil slice is returned if namespaces is
+// empty, which means all namespaces.
+func normalizeNamespaces(namespaces []string) []string {
+	if len(namespaces) == 0 {
+		return nil
+	}
+	return sets.NewString(namespaces...).List()
+}
+
+// scopedLeaseName returns the name of the leader election lease for the controllers which reconcile the
+// StatefulSets in namespaces and matching setSelector. The lease of a cluster-wide controller is name, other scopes
+// get a lease of their own by suffixing name with a hash of the scope, so that the instances of different scopes do
+// not compete for the same lease.
+func scopedLeaseName(name string, namespaces []string, setSelector labels.Selector) string {
+	if len(namespaces) == 0 && (setSelector == nil || setSelector.Empty()) {
+		return name
+	}
+	scope := strings.Join(normalizeNamespaces(namespaces), ",")
+	if setSelector != nil {
+		scope += "/" + setSelector.String()
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(scope))
+	return fmt.Sprintf("%s-%08x", name, hash.Sum32())
+}
+
 // createRecorder creates event recorder.
 func createRecorder(kubeClient clientset.Interface, userAgent string) record.EventRecorder {
 	eventBroadcaster := record.NewBroadcaster()
@@ -137,12 +347,10 @@ func createRecorder(kubeClient clientset.Interface, userAgent string) record.Eve
 // makeLeaderElectionConfig builds a leader election configuration. It will
 // create a new resource lock associated with the configuration.
 func makeLeaderElectionConfig(config componentbaseconfig.LeaderElectionConfiguration, client clientset.Interface, recorder record.EventRecorder) (*leaderelection.LeaderElectionConfig, error) {
-	hostname, err := os.Hostname()
+	id, err := makeIdentity()
 	if err != nil {
-		return nil, fmt.Errorf("unable to get hostname: %v", err)
+		return nil, err
 	}
-	// add a uniquifier so that two processes on the same host don't accidentally both become active
-	id := hostname + "_" + string(uuid.NewUUID())
 
 	rl, err := resourcelock.New(config.ResourceLock,
 		config.ResourceNamespace,
@@ -166,3 +374,31 @@ func makeLeaderElectionConfig(config componentbaseconfig.LeaderElectionConfigura
 		Name:          "advanced-statefulset-controller-manager",
 	}, nil
 }
+
+// makeShardManager builds a shard manager with the leases and the timing of the leader election configuration.
+func makeShardManager(config componentbaseconfig.LeaderElectionConfiguration, client clientset.Interface, shards int) (*sharding.Manager, error) {
+	id, err := makeIdentity()
+	if err != nil {
+		return nil, err
+	}
+	return sharding.NewManager(sharding.Config{
+		Client:        client.CoordinationV1(),
+		Namespace:     config.ResourceNamespace,
+		Name:          config.ResourceName,
+		Identity:      id,
+		Shards:        shards,
+		LeaseDuration: config.LeaseDuration.Duration,
+		RenewDeadline: config.RenewDeadline.Duration,
+		RetryPeriod:   config.RetryPeriod.Duration,
+	})
+}
+
+// makeIdentity returns the identity of this process in leader election and sharding.
+func makeIdentity() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("unable to get hostname: %v", err)
+	}
+	// add a uniquifier so that two processes on the same host don't accidentally both become active
+	return hostname + "_" + string(uuid.NewUUID()), nil
+}
@@ -0,0 +1,267 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestNormalizeNamespaces(t *testing.T) {
+	tests := []struct {
+		namespaces []string
+		want       []string
+	}{
+		{namespaces: nil, want: nil},
+		{namespaces: []string{}, want: nil},
+		{namespaces: []string{"b", "a"}, want: []string{"a", "b"}},
+		{namespaces: []string{"b", "a", "b"}, want: []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		if got := normalizeNamespaces(tt.namespaces); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("normalizeNamespaces(%q): got %q, want %q", tt.namespaces, got, tt.want)
+		}
+	}
+}
+
+func TestScopedLeaseName(t *testing.T) {
+	selector := func(s string) labels.Selector {
+		selector, err := labels.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return selector
+	}
+	const name = "advanced-statefulset-controller"
+	if got := scopedLeaseName(name, nil, nil); got != name {
+		t.Errorf("a cluster-wide controller: got lease %q, want %q", got, name)
+	}
+	if got := scopedLeaseName(name, []string{}, labels.Everything()); got != name {
+		t.Errorf("a cluster-wide controller with an empty selector: got lease %q, want %q", got, name)
+	}
+
+	tests := []struct {
+		name       string
+		namespaces []string
+		selector   labels.Selector
+		same       [][]string
+	}{
+		{
+			name:       "namespaces",
+			namespaces: []string{"a", "b"},
+			same:       [][]string{{"b", "a"}, {"a", "b", "a"}},
+		},
+		{
+			name:       "namespaces and selector",
+			namespaces: []string{"a", "b"},
+			selector:   selector("shard=a"),
+			same:       [][]string{{"b", "a", "b"}},
+		},
+		{
+			name:     "selector",
+			selector: selector("shard=a"),
+			same:     [][]string{{}},
+		},
+	}
+	names := make(map[string]string)
+	for _, tt := range tests {
+		got := scopedLeaseName(name, tt.namespaces, tt.selector)
+		if !strings.HasPrefix(got, name+"-") {
+			t.Errorf("%s: got lease %q, want a suffix of %q", tt.name, got, name)
+		}
+		for _, namespaces := range tt.same {
+			if same := scopedLeaseName(name, namespaces, tt.selector); same != got {
+				t.Errorf("%s: got lease %q for namespaces %q, want %q", tt.name, same, namespaces, got)
+			}
+		}
+		if other, ok := names[got]; ok {
+			t.Errorf("%s: got lease %q of scope %s", tt.name, got, other)
+		}
+		names[got] = tt.name
+	}
+	if a, b := scopedLeaseName(name, []string{"a"}, nil), scopedLeaseName(name, []string{"b"}, nil); a == b {
+		t.Errorf("got lease %q for different namespaces", a)
+	}
+	if a, b := scopedLeaseName(name, nil, selector("shard=a")), scopedLeaseName(name, nil, selector("shard=b")); a == b {
+		t.Errorf("got lease %q for different selectors", a)
+	}
+}
+
+func TestApplyConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(`
+apiVersion: controllermanager.config.apps.pingcap.com/v1alpha1
+kind: ControllerManagerConfiguration
+kubeAPIQPS: 50
+concurrentStatefulSetSyncs: 8
+setSelector: shard=a
+namespaces: [tenant-a]
+leaderElection:
+  leaderElect: false
+featureGates:
+  AutomaticRollback: true
+  PodEviction: false
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewControllerManagerOptions()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	for _, f := range s.Flags().FlagSets {
+		fs.AddFlagSet(f)
+	}
+	if err := fs.Parse([]string{
+		"--config=" + path,
+		"--concurrent-statefulset-syncs=4",
+		"--namespace=tenant-b",
+		"--feature-gates=PodEviction=true,VolumeClaimUpdates=false",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ApplyConfigFile(fs); err != nil {
+		t.Fatal(err)
+	}
+
+	// the flags set on the command line override the file
+	if s.ConcurrentStatefulSetSyncs != 4 {
+		t.Errorf("got %d concurrent syncs, want 4 of the flag", s.ConcurrentStatefulSetSyncs)
+	}
+	if want := []string{"tenant-b"}; !reflect.DeepEqual(s.Namespaces, want) {
+		t.Errorf("got namespaces %q, want %q of the flag", s.Namespaces, want)
+	}
+	// the other flags take the values of the file, which defaults the fields it does not set
+	if s.GenericComponent.KubeAPIQPS != 50 || s.GenericComponent.KubeAPIBurst != 30 {
+		t.Errorf("got QPS %v and burst %d, want 50 of the file and the default 30",
+			s.GenericComponent.KubeAPIQPS, s.GenericComponent.KubeAPIBurst)
+	}
+	if s.SetSelector != "shard=a" {
+		t.Errorf("got set selector %q, want shard=a of the file", s.SetSelector)
+	}
+	if s.GenericComponent.LeaderElection.LeaderElect {
+		t.Errorf("leader election should be disabled by the file")
+	}
+	// the feature gates of the file and of the flag are merged, the flag wins
+	wantGates := map[string]bool{"AutomaticRollback": true, "PodEviction": true, "VolumeClaimUpdates": false}
+	if !reflect.DeepEqual(s.FeatureGates, wantGates) {
+		t.Errorf("got feature gates %v, want %v", s.FeatureGates, wantGates)
+	}
+	if err := s.Validate(); err != nil {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestApplyConfigFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(`
+apiVersion: controllermanager.config.apps.pingcap.com/v1alpha1
+kind: ControllerManagerConfiguration
+concurrentStatefulSetSync: 8
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := NewControllerManagerOptions()
+	s.ConfigFile = path
+	if err := s.ApplyConfigFile(pflag.NewFlagSet("test", pflag.ContinueOnError)); err == nil {
+		t.Errorf("an unknown field should be rejected")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(s *ControllerManagerOptions)
+		wantErr string
+	}{
+		{
+			name:   "defaults",
+			modify: func(s *ControllerManagerOptions) {},
+		},
+		{
+			name:    "no concurrent syncs",
+			modify:  func(s *ControllerManagerOptions) { s.ConcurrentStatefulSetSyncs = 0 },
+			wantErr: "the number of concurrent StatefulSet syncs must be positive",
+		},
+		{
+			name:    "no min resync period",
+			modify:  func(s *ControllerManagerOptions) { s.GenericComponent.MinResyncPeriod.Duration = 0 },
+			wantErr: "min-resync-period must be positive",
+		},
+		{
+			name:    "negative QPS",
+			modify:  func(s *ControllerManagerOptions) { s.GenericComponent.KubeAPIQPS = -1 },
+			wantErr: "kube-api-qps must not be negative",
+		},
+		{
+			name:    "negative burst",
+			modify:  func(s *ControllerManagerOptions) { s.GenericComponent.KubeAPIBurst = -1 },
+			wantErr: "kube-api-burst must not be negative",
+		},
+		{
+			name: "leader election without lease",
+			modify: func(s *ControllerManagerOptions) {
+				s.GenericComponent.LeaderElection.LeaderElect = true
+			},
+			wantErr: "resourceName is required",
+		},
+		{
+			name:    "invalid informer label selector",
+			modify:  func(s *ControllerManagerOptions) { s.InformerLabelSelector = "a in (" },
+			wantErr: "invalid informer label selector",
+		},
+		{
+			name:    "invalid set selector",
+			modify:  func(s *ControllerManagerOptions) { s.SetSelector = "a in (" },
+			wantErr: "invalid set selector",
+		},
+		{
+			name:    "unknown feature gate",
+			modify:  func(s *ControllerManagerOptions) { s.FeatureGates = map[string]bool{"Unknown": true} },
+			wantErr: "unrecognized feature gate",
+		},
+		{
+			name:   "namespaces",
+			modify: func(s *ControllerManagerOptions) { s.Namespaces = []string{"tenant-a", "tenant-b"} },
+		},
+		{
+			name:    "invalid namespace",
+			modify:  func(s *ControllerManagerOptions) { s.Namespaces = []string{"tenant-a", "Tenant_B"} },
+			wantErr: `invalid namespace "Tenant_B"`,
+		},
+		{
+			name:    "empty namespace",
+			modify:  func(s *ControllerManagerOptions) { s.Namespaces = []string{""} },
+			wantErr: `invalid namespace ""`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewControllerManagerOptions()
+			s.GenericComponent.LeaderElection.LeaderElect = false
+			tt.modify(s)
+			err := s.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("got error %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("got error %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+
+	asv1 "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	asclientset "github.com/pingcap/advanced-statefulset/client/client/clientset/versioned"
+)
+
+// Options are the clients and the namespace the commands of the plugin operate on.
+type Options struct {
+	Namespace  string
+	Client     asclientset.Interface
+	KubeClient kubernetes.Interface
+	Out        io.Writer
+}
+
+// NewCommand returns the kubectl-asts command, which writes its output to out.
+func NewCommand(out io.Writer) *cobra.Command {
+	o := &Options{Out: out}
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	overrides := &clientcmd.ConfigOverrides{}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+
+	cmd := &cobra.Command{
+		Use:          "kubectl-asts",
+		Short:        "Manage the rollouts of Advanced StatefulSets",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.complete(clientConfig)
+		},
+	}
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&loadingRules.ExplicitPath, "kubeconfig", "", "Path to the kubeconfig file to use.")
+	clientcmd.BindOverrideFlags(overrides, flags, clientcmd.RecommendedConfigOverrideFlags(""))
+
+	cmd.AddCommand(
+		newStatusCommand(o),
+		newHistoryCommand(o),
+		newUndoCommand(o),
+		newRestartCommand(o),
+		newDeletePodCommand(o),
+		newPauseCommand(o),
+		newResumeCommand(o),
+	)
+	return cmd
+}
+
+// complete creates the clients from clientConfig.
+func (o *Options) complete(clientConfig clientcmd.ClientConfig) error {
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return err
+	}
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	client, err := asclientset.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	o.Namespace = namespace
+	o.Client = client
+	o.KubeClient = kubeClient
+	return nil
+}
+
+// get returns the StatefulSet name.
+func (o *Options) get(ctx context.Context, name string) (*asv1.StatefulSet, error) {
+	return o.Client.AppsV1().StatefulSets(o.Namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// update applies mutate to the StatefulSet name and updates it, it is retried on conflicts.
+func (o *Options) update(ctx context.Context, name string, mutate func(set *asv1.StatefulSet) error) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		set, err := o.get(ctx, name)
+		if err != nil {
+			return err
+		}
+		if err := mutate(set); err != nil {
+			return err
+		}
+		_, err = o.Client.AppsV1().StatefulSets(o.Namespace).Update(ctx, set, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// parseOrdinal parses the ordinal argument of a command.
+func parseOrdinal(arg string) (int32, error) {
+	ordinal, err := strconv.ParseInt(arg, 10, 32)
+	if err != nil || ordinal < 0 {
+		return 0, fmt.Errorf("invalid ordinal %q", arg)
+	}
+	return int32(ordinal), nil
+}
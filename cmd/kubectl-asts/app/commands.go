@@ -0,0 +1,147 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	asv1 "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+)
+
+// restartedAtAnn is the pod template annotation set by kubectl rollout restart.
+const restartedAtAnn = "kubectl.kubernetes.io/restartedAt"
+
+func newRestartCommand(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restart NAME",
+		Short: "Restart the Pods of a StatefulSet with a rolling update",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.restart(cmd.Context(), args[0], time.Now())
+		},
+	}
+}
+
+func newDeletePodCommand(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete-pod NAME ORDINAL",
+		Short: "Delete the Pod of an ordinal and scale a StatefulSet in by one",
+		Long: `Delete the Pod of an ordinal and scale a StatefulSet in by one.
+
+The ordinal is added to the delete-slots annotation and the replicas are
+decremented in the same update, the other Pods keep their ordinals.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ordinal, err := parseOrdinal(args[1])
+			if err != nil {
+				return err
+			}
+			return o.deletePod(cmd.Context(), args[0], ordinal)
+		},
+	}
+}
+
+func newPauseCommand(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "pause NAME",
+		Short: "Pause the reconciliation of a StatefulSet",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.setPaused(cmd.Context(), args[0], true)
+		},
+	}
+}
+
+func newResumeCommand(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "resume NAME",
+		Short: "Resume the reconciliation of a paused StatefulSet",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.setPaused(cmd.Context(), args[0], false)
+		},
+	}
+}
+
+// restart changes the pod template of the StatefulSet name, so that its Pods are replaced by a rolling update.
+func (o *Options) restart(ctx context.Context, name string, now time.Time) error {
+	err := o.update(ctx, name, func(set *asv1.StatefulSet) error {
+		if set.Spec.UpdateStrategy.Type == asv1.OnDeleteStatefulSetStrategyType {
+			return fmt.Errorf("can not restart statefulset %s with the %s strategy type", name, asv1.OnDeleteStatefulSetStrategyType)
+		}
+		if set.Spec.Template.Annotations == nil {
+			set.Spec.Template.Annotations = make(map[string]string)
+		}
+		set.Spec.Template.Annotations[restartedAtAnn] = now.Format(time.RFC3339)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "statefulset %s restarted\n", name)
+	return nil
+}
+
+// deletePod adds ordinal to the delete slots of the StatefulSet name and decrements its replicas.
+func (o *Options) deletePod(ctx context.Context, name string, ordinal int32) error {
+	err := o.update(ctx, name, func(set *asv1.StatefulSet) error {
+		replicas := int32(1)
+		if set.Spec.Replicas != nil {
+			replicas = *set.Spec.Replicas
+		}
+		if !helper.GetPodOrdinals(replicas, set).Has(ordinal) {
+			return fmt.Errorf("ordinal %d is not a desired ordinal of statefulset %s", ordinal, name)
+		}
+		if err := helper.AddDeleteSlots(set, sets.NewInt32(ordinal)); err != nil {
+			return err
+		}
+		replicas--
+		set.Spec.Replicas = &replicas
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "statefulset %s scaled in, ordinal %d deleted\n", name, ordinal)
+	return nil
+}
+
+// setPaused pauses or resumes the reconciliation of the StatefulSet name.
+func (o *Options) setPaused(ctx context.Context, name string, paused bool) error {
+	err := o.update(ctx, name, func(set *asv1.StatefulSet) error {
+		if helper.GetPausedReconcile(set) == paused {
+			if paused {
+				return fmt.Errorf("statefulset %s is already paused", name)
+			}
+			return fmt.Errorf("statefulset %s is not paused", name)
+		}
+		helper.SetPausedReconcile(set, paused)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if paused {
+		fmt.Fprintf(o.Out, "statefulset %s paused\n", name)
+	} else {
+		fmt.Fprintf(o.Out, "statefulset %s resumed\n", name)
+	}
+	return nil
+}
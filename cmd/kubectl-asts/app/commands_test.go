@@ -0,0 +1,92 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	asv1 "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+)
+
+func getStatefulSet(t *testing.T, o *Options, name string) *asv1.StatefulSet {
+	set, err := o.Client.AppsV1().StatefulSets(o.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return set
+}
+
+func TestRestart(t *testing.T) {
+	set := newStatefulSet(3)
+	o, _ := newTestOptions(set)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := o.restart(context.TODO(), set.Name, now); err != nil {
+		t.Fatal(err)
+	}
+	if got := getStatefulSet(t, o, set.Name).Spec.Template.Annotations[restartedAtAnn]; got != "2026-01-02T03:04:05Z" {
+		t.Errorf("got %s annotation %q", restartedAtAnn, got)
+	}
+
+	set.Spec.UpdateStrategy.Type = asv1.OnDeleteStatefulSetStrategyType
+	o, _ = newTestOptions(set)
+	if err := o.restart(context.TODO(), set.Name, now); err == nil {
+		t.Errorf("a statefulset with the OnDelete strategy should not be restarted")
+	}
+}
+
+func TestDeletePod(t *testing.T) {
+	set := newStatefulSet(3)
+	o, _ := newTestOptions(set)
+	if err := o.deletePod(context.TODO(), set.Name, 1); err != nil {
+		t.Fatal(err)
+	}
+	got := getStatefulSet(t, o, set.Name)
+	if *got.Spec.Replicas != 2 {
+		t.Errorf("got %d replicas, want 2", *got.Spec.Replicas)
+	}
+	if ordinals := helper.GetPodOrdinals(*got.Spec.Replicas, got).List(); len(ordinals) != 2 || ordinals[0] != 0 || ordinals[1] != 2 {
+		t.Errorf("got ordinals %v, want [0 2]", ordinals)
+	}
+	if err := o.deletePod(context.TODO(), set.Name, 1); err == nil {
+		t.Errorf("a deleted ordinal should not be deleted again")
+	}
+	if err := o.deletePod(context.TODO(), set.Name, 3); err == nil {
+		t.Errorf("an ordinal which is not desired should not be deleted")
+	}
+}
+
+func TestPauseAndResume(t *testing.T) {
+	set := newStatefulSet(3)
+	o, _ := newTestOptions(set)
+	if err := o.setPaused(context.TODO(), set.Name, true); err != nil {
+		t.Fatal(err)
+	}
+	if !helper.GetPausedReconcile(getStatefulSet(t, o, set.Name)) {
+		t.Errorf("statefulset should be paused")
+	}
+	if err := o.setPaused(context.TODO(), set.Name, true); err == nil {
+		t.Errorf("a paused statefulset should not be paused again")
+	}
+	if err := o.setPaused(context.TODO(), set.Name, false); err != nil {
+		t.Fatal(err)
+	}
+	if helper.GetPausedReconcile(getStatefulSet(t, o, set.Name)) {
+		t.Errorf("statefulset should be resumed")
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	kubeapps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	asv1 "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+	"github.com/pingcap/advanced-statefulset/pkg/third_party/k8s"
+)
+
+func newHistoryCommand(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "history NAME",
+		Short: "Show the revisions of a StatefulSet",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.history(cmd.Context(), args[0])
+		},
+	}
+}
+
+func newUndoCommand(o *Options) *cobra.Command {
+	toRevision := int64(0)
+	cmd := &cobra.Command{
+		Use:   "undo NAME",
+		Short: "Roll a StatefulSet back to a previous revision",
+		Long: `Roll a StatefulSet back to a previous revision.
+
+The rollback is requested with the rollback-to annotation, the controller
+restores the pod template of the revision and rolls the Pods back.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.undo(cmd.Context(), args[0], toRevision)
+		},
+	}
+	cmd.Flags().Int64Var(&toRevision, "to-revision", toRevision, "The revision to roll back to, zero means the revision before the update revision.")
+	return cmd
+}
+
+// listRevisions returns the ControllerRevisions of set sorted by revision.
+func (o *Options) listRevisions(ctx context.Context, set *asv1.StatefulSet) ([]*kubeapps.ControllerRevision, error) {
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	list, err := o.KubeClient.AppsV1().ControllerRevisions(set.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	var revisions []*kubeapps.ControllerRevision
+	for i := range list.Items {
+		if metav1.IsControlledBy(&list.Items[i], set) {
+			revisions = append(revisions, &list.Items[i])
+		}
+	}
+	k8s.SortControllerRevisions(revisions)
+	return revisions, nil
+}
+
+// history prints the revisions of the StatefulSet name.
+func (o *Options) history(ctx context.Context, name string) error {
+	set, err := o.get(ctx, name)
+	if err != nil {
+		return err
+	}
+	revisions, err := o.listRevisions(ctx, set)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(o.Out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "REVISION\tNAME\tSTATUS")
+	for _, revision := range revisions {
+		var status string
+		switch revision.Name {
+		case set.Status.UpdateRevision:
+			status = "update"
+		case set.Status.CurrentRevision:
+			status = "current"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", revision.Revision, revision.Name, status)
+	}
+	return w.Flush()
+}
+
+// undo rolls the StatefulSet name back to toRevision, or to the revision before its update revision if toRevision is
+// zero.
+func (o *Options) undo(ctx context.Context, name string, toRevision int64) error {
+	set, err := o.get(ctx, name)
+	if err != nil {
+		return err
+	}
+	revisions, err := o.listRevisions(ctx, set)
+	if err != nil {
+		return err
+	}
+	var target *kubeapps.ControllerRevision
+	for _, revision := range revisions {
+		if toRevision == 0 && revision.Name == set.Status.UpdateRevision {
+			break
+		}
+		if toRevision == 0 || revision.Revision == toRevision {
+			target = revision
+		}
+	}
+	if target == nil {
+		if toRevision == 0 {
+			return fmt.Errorf("no revision found to roll statefulset %s back to", name)
+		}
+		return fmt.Errorf("unable to find revision %d of statefulset %s", toRevision, name)
+	}
+	if target.Name == set.Status.UpdateRevision {
+		fmt.Fprintf(o.Out, "statefulset %s skipped rollback (current template already matches revision %d)\n", name, target.Revision)
+		return nil
+	}
+	if _, err := helper.Rollback(ctx, o.Client, o.Namespace, name, strconv.FormatInt(target.Revision, 10)); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "statefulset %s rolled back to revision %d\n", name, target.Revision)
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	kubeapps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+)
+
+func TestHistory(t *testing.T) {
+	set := newStatefulSet(3)
+	first, second := newRevision(set, 1), newRevision(set, 2)
+	other := newRevision(set, 3)
+	other.OwnerReferences = nil
+	set.Status.CurrentRevision = first.Name
+	set.Status.UpdateRevision = second.Name
+	o, out := newTestOptions(set, second, first, other)
+	if err := o.history(context.TODO(), set.Name); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"REVISION  NAME   STATUS",
+		"1         web-1  current",
+		"2         web-2  update",
+	}
+	if got := strings.Split(strings.TrimSpace(out.String()), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got history\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestUndo(t *testing.T) {
+	tests := []struct {
+		name       string
+		toRevision int64
+		want       string
+		wantErr    bool
+	}{
+		{
+			name: "previous revision",
+			want: "2",
+		},
+		{
+			name:       "explicit revision",
+			toRevision: 1,
+			want:       "1",
+		},
+		{
+			name:       "revision not found",
+			toRevision: 5,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := newStatefulSet(3)
+			revisions := []*kubeapps.ControllerRevision{newRevision(set, 1), newRevision(set, 2), newRevision(set, 3)}
+			set.Status.CurrentRevision = revisions[1].Name
+			set.Status.UpdateRevision = revisions[2].Name
+			o, _ := newTestOptions(set, revisions[0], revisions[1], revisions[2])
+			err := o.undo(context.TODO(), set.Name, tt.toRevision)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error: %v", err, tt.wantErr)
+			}
+			got, err := o.Client.AppsV1().StatefulSets(set.Namespace).Get(context.TODO(), set.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rollbackTo, _ := helper.GetRollbackTo(got); rollbackTo != tt.want {
+				t.Errorf("got rollback-to %q, want %q", rollbackTo, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	asv1 "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+)
+
+func newStatusCommand(o *Options) *cobra.Command {
+	watch := true
+	timeout := time.Duration(0)
+	cmd := &cobra.Command{
+		Use:   "status NAME",
+		Short: "Show the status of the rollout of a StatefulSet",
+		Long: `Show the status of the rollout of a StatefulSet.
+
+By default the command watches the rollout until it is complete. The Pod which
+blocks the rollout is reported while it waits.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.status(cmd.Context(), args[0], watch, timeout)
+		},
+	}
+	cmd.Flags().BoolVarP(&watch, "watch", "w", watch, "Watch the status of the rollout until it is complete.")
+	cmd.Flags().DurationVar(&timeout, "timeout", timeout, "The length of time to wait before giving up, zero means forever.")
+	return cmd
+}
+
+// status prints the status of the rollout of the StatefulSet name, until it is complete if watch is true.
+func (o *Options) status(ctx context.Context, name string, watch bool, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	last := ""
+	return wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		set, err := o.get(ctx, name)
+		if err != nil {
+			return false, err
+		}
+		message, done, err := rolloutStatus(set)
+		if err != nil {
+			return false, err
+		}
+		if message != last {
+			fmt.Fprintln(o.Out, message)
+			last = message
+		}
+		return done || !watch, nil
+	})
+}
+
+// rolloutStatus returns a message describing the rollout of set and whether it is complete.
+func rolloutStatus(set *asv1.StatefulSet) (string, bool, error) {
+	if set.Spec.UpdateStrategy.Type != asv1.RollingUpdateStatefulSetStrategyType {
+		return "", false, fmt.Errorf("rollout status is only available for %s strategy type", asv1.RollingUpdateStatefulSetStrategyType)
+	}
+	if set.Status.ObservedGeneration == 0 || set.Generation > set.Status.ObservedGeneration {
+		return "Waiting for statefulset spec update to be observed...", false, nil
+	}
+	message, done := "", true
+	replicas := int32(1)
+	if set.Spec.Replicas != nil {
+		replicas = *set.Spec.Replicas
+	}
+	partition := int32(0)
+	if set.Spec.UpdateStrategy.RollingUpdate != nil && set.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
+		partition = *set.Spec.UpdateStrategy.RollingUpdate.Partition
+	}
+	switch {
+	case set.Status.ReadyReplicas < replicas:
+		message, done = fmt.Sprintf("Waiting for %d pods to be ready...", replicas-set.Status.ReadyReplicas), false
+	case partition > 0:
+		if set.Status.UpdatedReplicas < replicas-partition {
+			message, done = fmt.Sprintf("Waiting for partitioned roll out to finish: %d out of %d new pods have been updated...",
+				set.Status.UpdatedReplicas, replicas-partition), false
+		} else {
+			message = fmt.Sprintf("partitioned roll out complete: %d new pods have been updated...", set.Status.UpdatedReplicas)
+		}
+	case set.Status.UpdateRevision != set.Status.CurrentRevision:
+		message, done = fmt.Sprintf("waiting for statefulset rolling update to complete %d pods at revision %s...",
+			set.Status.UpdatedReplicas, set.Status.UpdateRevision), false
+	default:
+		message = fmt.Sprintf("statefulset rolling update complete %d pods at revision %s...",
+			set.Status.CurrentReplicas, set.Status.CurrentRevision)
+	}
+	if blocked := set.Status.BlockedBy; blocked != nil && !done {
+		message += fmt.Sprintf(" (blocked by pod %s: %s)", blocked.Pod, blocked.Reason)
+	}
+	return message, done, nil
+}
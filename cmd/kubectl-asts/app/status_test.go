@@ -0,0 +1,152 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	kubeapps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	utilpointer "k8s.io/utils/pointer"
+
+	asv1 "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	pcfake "github.com/pingcap/advanced-statefulset/client/client/clientset/versioned/fake"
+)
+
+func newStatefulSet(replicas int32) *asv1.StatefulSet {
+	return &asv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "web",
+			Namespace:  metav1.NamespaceDefault,
+			UID:        "web-uid",
+			Generation: 1,
+		},
+		Spec: asv1.StatefulSetSpec{
+			Replicas: utilpointer.Int32(replicas),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			UpdateStrategy: asv1.StatefulSetUpdateStrategy{
+				Type: asv1.RollingUpdateStatefulSetStrategyType,
+			},
+		},
+		Status: asv1.StatefulSetStatus{
+			ObservedGeneration: 1,
+		},
+	}
+}
+
+func newRevision(set *asv1.StatefulSet, revision int64) *kubeapps.ControllerRevision {
+	return &kubeapps.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            fmt.Sprintf("%s-%d", set.Name, revision),
+			Namespace:       set.Namespace,
+			Labels:          set.Spec.Selector.MatchLabels,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(set, asv1.SchemeGroupVersion.WithKind("StatefulSet"))},
+		},
+		Revision: revision,
+	}
+}
+
+// newTestOptions returns Options with fake clients holding set and objects, and the buffer the commands write to.
+func newTestOptions(set *asv1.StatefulSet, objects ...runtime.Object) (*Options, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	return &Options{
+		Namespace:  set.Namespace,
+		Client:     pcfake.NewSimpleClientset(set),
+		KubeClient: fake.NewSimpleClientset(objects...),
+		Out:        out,
+	}, out
+}
+
+func TestRolloutStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		mutate      func(set *asv1.StatefulSet)
+		wantMessage string
+		wantDone    bool
+		wantErr     bool
+	}{
+		{
+			name: "spec update not observed",
+			mutate: func(set *asv1.StatefulSet) {
+				set.Generation = 2
+			},
+			wantMessage: "Waiting for statefulset spec update to be observed...",
+		},
+		{
+			name: "pods not ready",
+			mutate: func(set *asv1.StatefulSet) {
+				set.Status.ReadyReplicas = 1
+				set.Status.BlockedBy = &asv1.StatefulSetBlockedBy{Pod: "web-1", Ordinal: 1, Reason: "WaitingForReady"}
+			},
+			wantMessage: "Waiting for 2 pods to be ready... (blocked by pod web-1: WaitingForReady)",
+		},
+		{
+			name: "rolling update in progress",
+			mutate: func(set *asv1.StatefulSet) {
+				set.Status.ReadyReplicas = 3
+				set.Status.UpdatedReplicas = 1
+				set.Status.CurrentRevision = "web-1"
+				set.Status.UpdateRevision = "web-2"
+			},
+			wantMessage: "waiting for statefulset rolling update to complete 1 pods at revision web-2...",
+		},
+		{
+			name: "partitioned rolling update complete",
+			mutate: func(set *asv1.StatefulSet) {
+				set.Spec.UpdateStrategy.RollingUpdate = &asv1.RollingUpdateStatefulSetStrategy{Partition: utilpointer.Int32(2)}
+				set.Status.ReadyReplicas = 3
+				set.Status.UpdatedReplicas = 1
+				set.Status.CurrentRevision = "web-1"
+				set.Status.UpdateRevision = "web-2"
+			},
+			wantMessage: "partitioned roll out complete: 1 new pods have been updated...",
+			wantDone:    true,
+		},
+		{
+			name: "rolling update complete",
+			mutate: func(set *asv1.StatefulSet) {
+				set.Status.ReadyReplicas = 3
+				set.Status.CurrentReplicas = 3
+				set.Status.CurrentRevision = "web-2"
+				set.Status.UpdateRevision = "web-2"
+			},
+			wantMessage: "statefulset rolling update complete 3 pods at revision web-2...",
+			wantDone:    true,
+		},
+		{
+			name: "OnDelete strategy",
+			mutate: func(set *asv1.StatefulSet) {
+				set.Spec.UpdateStrategy.Type = asv1.OnDeleteStatefulSetStrategyType
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := newStatefulSet(3)
+			tt.mutate(set)
+			message, done, err := rolloutStatus(set)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error: %v", err, tt.wantErr)
+			}
+			if message != tt.wantMessage || done != tt.wantDone {
+				t.Errorf("got %q (done: %v), want %q (done: %v)", message, done, tt.wantMessage, tt.wantDone)
+			}
+		})
+	}
+}
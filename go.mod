@@ -9,6 +9,7 @@ require (
 	github.com/pingcap/advanced-statefulset/client v0.0.0
 	github.com/spf13/cobra v1.7.0
 	github.com/spf13/pflag v1.0.5
+	golang.org/x/time v0.3.0
 	k8s.io/api v0.29.15
 	k8s.io/apiextensions-apiserver v0.29.15
 	k8s.io/apimachinery v0.29.15
@@ -20,6 +21,7 @@ require (
 	k8s.io/kubectl v0.29.15
 	k8s.io/kubelet v0.29.15
 	k8s.io/utils v0.0.0-20230726121419-3b25d923346b
+	sigs.k8s.io/yaml v1.3.0
 )
 
 require (
@@ -65,7 +67,6 @@ require (
 	golang.org/x/sys v0.18.0 // indirect
 	golang.org/x/term v0.18.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
-	golang.org/x/time v0.3.0 // indirect
 	golang.org/x/tools v0.16.1 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/protobuf v1.33.0 // indirect
@@ -77,7 +78,6 @@ require (
 	k8s.io/kube-openapi v0.0.0-20231010175941-2dd684a91f00 // indirect
 	sigs.k8s.io/json v0.0.0-20221116044647-bc3834ca7abd // indirect
 	sigs.k8s.io/structured-merge-diff/v4 v4.4.1 // indirect
-	sigs.k8s.io/yaml v1.3.0 // indirect
 )
 
 replace github.com/pingcap/advanced-statefulset/client => ./client
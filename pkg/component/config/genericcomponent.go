@@ -33,8 +33,6 @@ type GenericComponentConfiguration struct {
 	KubeAPIQPS float32
 	// kubeAPIBurst is the burst to use while talking with kubernetes apiserver.
 	KubeAPIBurst int32
-	// How long to wait between starting controller managers
-	ControllerStartInterval metav1.Duration
 	// leaderElection defines the configuration of leader election client.
 	LeaderElection componentbaseconfig.LeaderElectionConfiguration
 }
@@ -42,11 +40,10 @@ type GenericComponentConfiguration struct {
 // NewDefaultGenericComponentConfiguration returns default GenericComponentConfiguration.
 func NewDefaultGenericComponentConfiguration() GenericComponentConfiguration {
 	c := GenericComponentConfiguration{
-		MinResyncPeriod:         metav1.Duration{Duration: 12 * time.Hour},
-		ContentType:             "application/vnd.kubernetes.protobuf",
-		KubeAPIQPS:              20,
-		KubeAPIBurst:            30,
-		ControllerStartInterval: metav1.Duration{Duration: 0 * time.Second},
+		MinResyncPeriod: metav1.Duration{Duration: 12 * time.Hour},
+		ContentType:     "application/vnd.kubernetes.protobuf",
+		KubeAPIQPS:      20,
+		KubeAPIBurst:    30,
 	}
 	leaderElection := componentbaseconfigv1alpha1.LeaderElectionConfiguration{
 		// https://github.com/kubernetes/kubernetes/blob/341052f4c7c5dfed0a099607382b43f86bc36067/pkg/scheduler/apis/config/v1/defaults.go#L130-L135
@@ -14,33 +14,36 @@
 package options
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/pingcap/advanced-statefulset/pkg/component/config"
 	"github.com/spf13/pflag"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	componentbaseconfig "k8s.io/component-base/config"
 	"k8s.io/component-base/config/options"
+	"k8s.io/component-base/config/validation"
 )
 
 // GenericComponentOptions holds the options which are generic.
 type GenericComponentOptions struct {
-	MinResyncPeriod         metav1.Duration
-	ContentType             string
-	KubeAPIQPS              float32
-	KubeAPIBurst            int32
-	ControllerStartInterval metav1.Duration
-	LeaderElection          componentbaseconfig.LeaderElectionConfiguration
+	MinResyncPeriod metav1.Duration
+	ContentType     string
+	KubeAPIQPS      float32
+	KubeAPIBurst    int32
+	LeaderElection  componentbaseconfig.LeaderElectionConfiguration
 }
 
 // NewGenericComponentOptions returns generic configuration default
 // values.
 func NewGenericComponentOptions(cfg config.GenericComponentConfiguration) *GenericComponentOptions {
 	o := &GenericComponentOptions{
-		MinResyncPeriod:         cfg.MinResyncPeriod,
-		ContentType:             cfg.ContentType,
-		KubeAPIQPS:              cfg.KubeAPIQPS,
-		KubeAPIBurst:            cfg.KubeAPIBurst,
-		ControllerStartInterval: cfg.ControllerStartInterval,
-		LeaderElection:          cfg.LeaderElection,
+		MinResyncPeriod: cfg.MinResyncPeriod,
+		ContentType:     cfg.ContentType,
+		KubeAPIQPS:      cfg.KubeAPIQPS,
+		KubeAPIBurst:    cfg.KubeAPIBurst,
+		LeaderElection:  cfg.LeaderElection,
 	}
 	return o
 }
@@ -55,7 +58,10 @@ func (o *GenericComponentOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.ContentType, "kube-api-content-type", o.ContentType, "Content type of requests sent to apiserver.")
 	fs.Float32Var(&o.KubeAPIQPS, "kube-api-qps", o.KubeAPIQPS, "QPS to use while talking with kubernetes apiserver.")
 	fs.Int32Var(&o.KubeAPIBurst, "kube-api-burst", o.KubeAPIBurst, "Burst to use while talking with kubernetes apiserver.")
-	fs.DurationVar(&o.ControllerStartInterval.Duration, "controller-start-interval", o.ControllerStartInterval.Duration, "Interval between starting controller managers.")
+	// a single controller is started, the flag is only kept so that existing command lines still work
+	var controllerStartInterval time.Duration
+	fs.DurationVar(&controllerStartInterval, "controller-start-interval", 0, "Interval between starting controller managers.")
+	fs.MarkDeprecated("controller-start-interval", "it has no effect and will be removed in a future release")
 
 	options.BindLeaderElectionFlags(&o.LeaderElection, fs)
 }
@@ -67,6 +73,18 @@ func (o *GenericComponentOptions) Validate() []error {
 	}
 
 	errs := []error{}
+	if o.MinResyncPeriod.Duration <= 0 {
+		errs = append(errs, fmt.Errorf("min-resync-period must be positive, got %v", o.MinResyncPeriod.Duration))
+	}
+	if o.KubeAPIQPS < 0 {
+		errs = append(errs, fmt.Errorf("kube-api-qps must not be negative, got %v", o.KubeAPIQPS))
+	}
+	if o.KubeAPIBurst < 0 {
+		errs = append(errs, fmt.Errorf("kube-api-burst must not be negative, got %d", o.KubeAPIBurst))
+	}
+	for _, err := range validation.ValidateLeaderElectionConfiguration(&o.LeaderElection, field.NewPath("leaderElection")) {
+		errs = append(errs, err)
+	}
 	return errs
 }
 
@@ -80,7 +98,6 @@ func (o *GenericComponentOptions) ApplyTo(cfg *config.GenericComponentConfigurat
 	cfg.ContentType = o.ContentType
 	cfg.KubeAPIQPS = o.KubeAPIQPS
 	cfg.KubeAPIBurst = o.KubeAPIBurst
-	cfg.ControllerStartInterval = o.ControllerStartInterval
 	cfg.LeaderElection = o.LeaderElection
 
 	return nil
@@ -0,0 +1,458 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sharding spreads the StatefulSets across the active replicas of the controller. The keys of the
+// StatefulSets are hashed into a fixed number of shards, and each shard is owned by at most one replica at a time
+// through a Lease. Replicas advertise themselves with a member Lease of their own, and each replica holds an even
+// share of the shards of the replicas it observes.
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coordinationv1client "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// GroupLabel is set on the Leases of the replicas sharing the shards, its value is the Name of the Config.
+	GroupLabel = "apps.pingcap.com/shard-group"
+	// RoleLabel is set on the Leases to tell member Leases from shard Leases.
+	RoleLabel = "apps.pingcap.com/shard-role"
+
+	memberRole = "member"
+	shardRole  = "shard"
+)
+
+// Config configures a Manager.
+type Config struct {
+	// Client is used to get and update the Leases.
+	Client coordinationv1client.LeasesGetter
+	// Namespace is the namespace of the Leases.
+	Namespace string
+	// Name is the prefix of the names of the Leases, the replicas with the same Name share the shards.
+	Name string
+	// Identity is the unique identity of the replica.
+	Identity string
+	// Shards is the number of shards.
+	Shards int
+	// LeaseDuration is the duration after which a Lease which has not been renewed may be taken over.
+	LeaseDuration time.Duration
+	// RenewDeadline is the duration after which a replica which failed to renew the Lease of a shard stops owning
+	// it. It must be less than LeaseDuration.
+	RenewDeadline time.Duration
+	// RetryPeriod is the duration between the renewals of the Leases and the rebalancing of the shards.
+	RetryPeriod time.Duration
+	// Clock is the clock of the Manager, the real clock if nil.
+	Clock clock.Clock
+}
+
+// shard is a shard of the keys.
+type shard struct {
+	// lock is read locked during the syncs of the keys of the shard, and locked to stop owning the shard so that
+	// no sync is in flight once the shard is given up.
+	lock sync.RWMutex
+	// owned is true while the replica holds the Lease of the shard. It is guarded by lock.
+	owned bool
+	// renewed is the last time the Lease of the shard was renewed, in nanoseconds since the epoch. It is written by
+	// Run and read by Lock without holding lock, so that renewals never wait for syncs.
+	renewed atomic.Int64
+}
+
+func (s *shard) setRenewed(t time.Time) {
+	s.renewed.Store(t.UnixNano())
+}
+
+func (s *shard) renewedAt() time.Time {
+	return time.Unix(0, s.renewed.Load())
+}
+
+// observation is the local time at which a record of a Lease was first observed. As in the leader election of
+// client-go, the Leases of other replicas expire based on local observations rather than on their renew time, so
+// that the clocks of the replicas do not need to be synchronized.
+type observation struct {
+	record string
+	time   time.Time
+}
+
+// Manager acquires, renews and releases the Leases of the shards owned by a replica. A nil *Manager owns all keys.
+type Manager struct {
+	config Config
+	clock  clock.Clock
+	shards []*shard
+
+	// observed are the observations of the Leases of other replicas by name. It is only accessed by Run.
+	observed map[string]observation
+
+	handlersLock sync.Mutex
+	// handlers are called when shards are acquired.
+	handlers []func()
+}
+
+// NewManager creates a new Manager from config.
+func NewManager(config Config) (*Manager, error) {
+	if config.Shards < 1 {
+		return nil, fmt.Errorf("the number of shards must be positive, got %d", config.Shards)
+	}
+	if config.Name == "" || config.Identity == "" {
+		return nil, fmt.Errorf("the name and the identity of the shards must not be empty")
+	}
+	if config.LeaseDuration <= config.RenewDeadline || config.RenewDeadline <= 0 || config.RetryPeriod <= 0 {
+		return nil, fmt.Errorf("the lease duration %v must be greater than the renew deadline %v, and both and the retry period %v must be positive",
+			config.LeaseDuration, config.RenewDeadline, config.RetryPeriod)
+	}
+	m := &Manager{
+		config:   config,
+		clock:    config.Clock,
+		shards:   make([]*shard, config.Shards),
+		observed: make(map[string]observation),
+	}
+	if m.clock == nil {
+		m.clock = clock.RealClock{}
+	}
+	for i := range m.shards {
+		m.shards[i] = &shard{}
+	}
+	return m, nil
+}
+
+// Shard returns the shard of key.
+func (m *Manager) Shard(key string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return int(hash.Sum32() % uint32(len(m.shards)))
+}
+
+// Lock returns true if the replica owns the shard of key, in which case the shard is not given up until the
+// returned func is called. The returned func is nil if the shard of key is not owned. A shard whose Lease has not
+// been renewed for RenewDeadline is not owned, even before Run gives it up, so that no key is synced once another
+// replica may take the Lease over.
+func (m *Manager) Lock(key string) (func(), bool) {
+	if m == nil {
+		return func() {}, true
+	}
+	s := m.shards[m.Shard(key)]
+	s.lock.RLock()
+	if !s.owned || m.clock.Since(s.renewedAt()) >= m.config.RenewDeadline {
+		s.lock.RUnlock()
+		return nil, false
+	}
+	return s.lock.RUnlock, true
+}
+
+// AddAcquiredHandler adds a handler called when the replica acquires shards, so that the keys of the acquired
+// shards are synced.
+func (m *Manager) AddAcquiredHandler(handler func()) {
+	if m == nil {
+		return
+	}
+	m.handlersLock.Lock()
+	defer m.handlersLock.Unlock()
+	m.handlers = append(m.handlers, handler)
+}
+
+// Owned returns the shards owned by the replica.
+func (m *Manager) Owned() []int {
+	var owned []int
+	for i, s := range m.shards {
+		s.lock.RLock()
+		if s.owned {
+			owned = append(owned, i)
+		}
+		s.lock.RUnlock()
+	}
+	return owned
+}
+
+// Run renews the member Lease of the replica and rebalances the shards every RetryPeriod until ctx is done. The
+// shards are then given up and their Leases released, so that other replicas can take them over at once.
+func (m *Manager) Run(ctx context.Context) {
+	klog.Infof("Starting shard manager %s/%s with %d shards as %s", m.config.Namespace, m.config.Name, len(m.shards), m.config.Identity)
+	wait.UntilWithContext(ctx, m.reconcile, m.config.RetryPeriod)
+
+	releaseCtx, cancel := context.WithTimeout(context.Background(), m.config.RenewDeadline)
+	defer cancel()
+	for _, i := range m.Owned() {
+		m.release(releaseCtx, i)
+	}
+	err := m.leases().Delete(releaseCtx, m.memberName(), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		klog.Errorf("Failed to delete member lease %s/%s: %v", m.config.Namespace, m.memberName(), err)
+	}
+	klog.Infof("Shutting down shard manager %s/%s", m.config.Namespace, m.config.Name)
+}
+
+// reconcile renews the Leases of the replica, then releases or acquires shards to hold the share of the replica. A
+// pass is bounded by RenewDeadline, so that a hung request to the API server does not stall the renewals.
+func (m *Manager) reconcile(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, m.config.RenewDeadline)
+	defer cancel()
+	now := m.clock.Now()
+	if err := m.renewMember(ctx, now); err != nil {
+		klog.Errorf("Failed to renew member lease %s/%s: %v", m.config.Namespace, m.memberName(), err)
+	}
+	// all owned shards are renewed before any is given up, as giving up a shard waits for the syncs of its keys
+	var lost []int
+	for _, i := range m.Owned() {
+		if !m.renew(ctx, i, now) {
+			lost = append(lost, i)
+		}
+	}
+	for _, i := range lost {
+		m.giveUp(i)
+	}
+
+	list, err := m.leases().List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{GroupLabel: m.config.Name}).String(),
+	})
+	if err != nil {
+		klog.Errorf("Failed to list leases of %s/%s: %v", m.config.Namespace, m.config.Name, err)
+		return
+	}
+	members := []string{m.config.Identity}
+	shardLeases := make(map[string]*coordinationv1.Lease)
+	for i := range list.Items {
+		lease := &list.Items[i]
+		switch lease.Labels[RoleLabel] {
+		case memberRole:
+			holder := holderIdentity(lease)
+			if holder != "" && holder != m.config.Identity && !m.expired(lease, now) {
+				members = append(members, holder)
+			}
+		case shardRole:
+			shardLeases[lease.Name] = lease
+		}
+	}
+	target := m.target(members)
+
+	owned := m.Owned()
+	// give up the last shards first, so that shards move as little as possible
+	for j := len(owned) - 1; j >= target; j-- {
+		m.release(ctx, owned[j])
+	}
+	if len(owned) >= target {
+		return
+	}
+	acquired := false
+	for i := range m.shards {
+		if len(owned) >= target {
+			break
+		}
+		if m.owns(i) {
+			continue
+		}
+		lease := shardLeases[m.shardName(i)]
+		if lease != nil && holderIdentity(lease) != "" && holderIdentity(lease) != m.config.Identity && !m.expired(lease, now) {
+			continue
+		}
+		if m.acquire(ctx, i, lease, now) {
+			owned = append(owned, i)
+			acquired = true
+		}
+	}
+	if acquired {
+		m.handlersLock.Lock()
+		handlers := m.handlers
+		m.handlersLock.Unlock()
+		for _, handler := range handlers {
+			handler()
+		}
+	}
+}
+
+// target returns the number of shards the replica should hold, given the identities of the live members.
+func (m *Manager) target(members []string) int {
+	sort.Strings(members)
+	rank := sort.SearchStrings(members, m.config.Identity)
+	target := len(m.shards) / len(members)
+	if rank < len(m.shards)%len(members) {
+		target++
+	}
+	return target
+}
+
+// expired returns true if lease has not been renewed by its holder for its duration, as observed locally.
+func (m *Manager) expired(lease *coordinationv1.Lease, now time.Time) bool {
+	record := fmt.Sprintf("%s/%s", lease.ResourceVersion, holderIdentity(lease))
+	if lease.Spec.RenewTime != nil {
+		record += "/" + lease.Spec.RenewTime.UTC().Format(time.RFC3339Nano)
+	}
+	obs, ok := m.observed[lease.Name]
+	if !ok || obs.record != record {
+		m.observed[lease.Name] = observation{record: record, time: now}
+		return false
+	}
+	duration := m.config.LeaseDuration
+	if lease.Spec.LeaseDurationSeconds != nil {
+		duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+	return now.Sub(obs.time) > duration
+}
+
+// renewMember creates or renews the member Lease of the replica.
+func (m *Manager) renewMember(ctx context.Context, now time.Time) error {
+	lease, err := m.leases().Get(ctx, m.memberName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lease = m.newLease(m.memberName(), memberRole)
+		m.hold(lease, now)
+		_, err = m.leases().Create(ctx, lease, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	m.hold(lease, now)
+	_, err = m.leases().Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+// acquire takes the Lease of shard i, which is free or expired. lease is nil if the Lease does not exist. It
+// returns true if the shard is acquired.
+func (m *Manager) acquire(ctx context.Context, i int, lease *coordinationv1.Lease, now time.Time) bool {
+	var err error
+	if lease == nil {
+		lease = m.newLease(m.shardName(i), shardRole)
+		m.hold(lease, now)
+		_, err = m.leases().Create(ctx, lease, metav1.CreateOptions{})
+	} else {
+		lease = lease.DeepCopy()
+		m.hold(lease, now)
+		// the update fails with a conflict if another replica acquired the shard since the Lease was listed
+		_, err = m.leases().Update(ctx, lease, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		klog.V(2).Infof("Failed to acquire shard %d of %s/%s: %v", i, m.config.Namespace, m.config.Name, err)
+		return false
+	}
+	s := m.shards[i]
+	s.lock.Lock()
+	s.owned = true
+	s.setRenewed(now)
+	s.lock.Unlock()
+	klog.Infof("Acquired shard %d of %s/%s", i, m.config.Namespace, m.config.Name)
+	return true
+}
+
+// renew renews the Lease of the owned shard i. It returns false if the shard should be given up, because the Lease
+// was taken over or could not be renewed for RenewDeadline.
+func (m *Manager) renew(ctx context.Context, i int, now time.Time) bool {
+	s := m.shards[i]
+	lease, err := m.leases().Get(ctx, m.shardName(i), metav1.GetOptions{})
+	if err == nil {
+		if holderIdentity(lease) != m.config.Identity {
+			klog.Warningf("Shard %d of %s/%s was taken over by %q", i, m.config.Namespace, m.config.Name, holderIdentity(lease))
+			return false
+		}
+		m.hold(lease, now)
+		_, err = m.leases().Update(ctx, lease, metav1.UpdateOptions{})
+	}
+	if err == nil {
+		s.setRenewed(now)
+		return true
+	}
+	klog.Errorf("Failed to renew shard %d of %s/%s: %v", i, m.config.Namespace, m.config.Name, err)
+	return !apierrors.IsNotFound(err) && now.Sub(s.renewedAt()) < m.config.RenewDeadline
+}
+
+// release gives up the owned shard i and releases its Lease.
+func (m *Manager) release(ctx context.Context, i int) {
+	m.giveUp(i)
+	lease, err := m.leases().Get(ctx, m.shardName(i), metav1.GetOptions{})
+	if err == nil && holderIdentity(lease) == m.config.Identity {
+		lease.Spec.HolderIdentity = nil
+		_, err = m.leases().Update(ctx, lease, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		klog.Errorf("Failed to release shard %d of %s/%s: %v", i, m.config.Namespace, m.config.Name, err)
+		return
+	}
+	klog.Infof("Released shard %d of %s/%s", i, m.config.Namespace, m.config.Name)
+}
+
+// giveUp stops owning shard i once the syncs of its keys in flight are done.
+func (m *Manager) giveUp(i int) {
+	s := m.shards[i]
+	s.lock.Lock()
+	s.owned = false
+	s.lock.Unlock()
+}
+
+func (m *Manager) owns(i int) bool {
+	s := m.shards[i]
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.owned
+}
+
+// hold sets the replica as the holder of lease, renewed at now.
+func (m *Manager) hold(lease *coordinationv1.Lease, now time.Time) {
+	renewTime := metav1.NewMicroTime(now)
+	if holderIdentity(lease) != m.config.Identity {
+		lease.Spec.HolderIdentity = &m.config.Identity
+		lease.Spec.AcquireTime = &renewTime
+		transitions := int32(0)
+		if lease.Spec.LeaseTransitions != nil {
+			transitions = *lease.Spec.LeaseTransitions + 1
+		}
+		lease.Spec.LeaseTransitions = &transitions
+	}
+	duration := int32(m.config.LeaseDuration / time.Second)
+	lease.Spec.LeaseDurationSeconds = &duration
+	lease.Spec.RenewTime = &renewTime
+}
+
+func (m *Manager) newLease(name, role string) *coordinationv1.Lease {
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: m.config.Namespace,
+			Name:      name,
+			Labels: map[string]string{
+				GroupLabel: m.config.Name,
+				RoleLabel:  role,
+			},
+		},
+	}
+}
+
+func (m *Manager) leases() coordinationv1client.LeaseInterface {
+	return m.config.Client.Leases(m.config.Namespace)
+}
+
+func (m *Manager) memberName() string {
+	hash := fnv.New32a()
+	hash.Write([]byte(m.config.Identity))
+	return fmt.Sprintf("%s-member-%08x", m.config.Name, hash.Sum32())
+}
+
+func (m *Manager) shardName(i int) string {
+	return fmt.Sprintf("%s-shard-%d", m.config.Name, i)
+}
+
+func holderIdentity(lease *coordinationv1.Lease) string {
+	if lease.Spec.HolderIdentity == nil {
+		return ""
+	}
+	return *lease.Spec.HolderIdentity
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func newTestManager(t *testing.T, client *fake.Clientset, clock *testingclock.FakeClock, identity string) *Manager {
+	m, err := NewManager(Config{
+		Client:        client.CoordinationV1(),
+		Namespace:     "default",
+		Name:          "controller",
+		Identity:      identity,
+		Shards:        4,
+		LeaseDuration: 15 * time.Second,
+		RenewDeadline: 10 * time.Second,
+		RetryPeriod:   2 * time.Second,
+		Clock:         clock,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// keyOfShard returns a key which hashes into shard i of m.
+func keyOfShard(m *Manager, i int) string {
+	for n := 0; ; n++ {
+		key := fmt.Sprintf("default/set-%d", n)
+		if m.Shard(key) == i {
+			return key
+		}
+	}
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	clock := testingclock.NewFakeClock(time.Now())
+	a := newTestManager(t, client, clock, "a")
+	b := newTestManager(t, client, clock, "b")
+	acquired := 0
+	b.AddAcquiredHandler(func() { acquired++ })
+
+	a.reconcile(ctx)
+	if owned := a.Owned(); !reflect.DeepEqual(owned, []int{0, 1, 2, 3}) {
+		t.Fatalf("a single replica should own all shards, got %v", owned)
+	}
+	clock.Step(time.Second)
+	b.reconcile(ctx)
+	if owned := b.Owned(); len(owned) != 0 {
+		t.Fatalf("shards held by a should not be acquired, got %v", owned)
+	}
+
+	// a gives up its last shards for b
+	clock.Step(time.Second)
+	a.reconcile(ctx)
+	clock.Step(time.Second)
+	b.reconcile(ctx)
+	if owned := a.Owned(); !reflect.DeepEqual(owned, []int{0, 1}) {
+		t.Errorf("a should own shards 0 and 1, got %v", owned)
+	}
+	if owned := b.Owned(); !reflect.DeepEqual(owned, []int{2, 3}) {
+		t.Errorf("b should own shards 2 and 3, got %v", owned)
+	}
+	if acquired != 1 {
+		t.Errorf("got %d calls of the acquired handler, want 1", acquired)
+	}
+	for i := 0; i < 4; i++ {
+		key := keyOfShard(a, i)
+		aUnlock, aOwns := a.Lock(key)
+		bUnlock, bOwns := b.Lock(key)
+		if aOwns {
+			aUnlock()
+		}
+		if bOwns {
+			bUnlock()
+		}
+		if aOwns == bOwns {
+			t.Errorf("key %s of shard %d should be owned by exactly one replica, a: %v, b: %v", key, i, aOwns, bOwns)
+		}
+	}
+
+	// the shards of a are taken over once its leases expire
+	clock.Step(20 * time.Second)
+	b.reconcile(ctx)
+	if owned := b.Owned(); !reflect.DeepEqual(owned, []int{0, 1, 2, 3}) {
+		t.Errorf("b should own all shards after a stopped renewing its leases, got %v", owned)
+	}
+
+	// the shards are released on shutdown
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	b.Run(cancelled)
+	if owned := b.Owned(); len(owned) != 0 {
+		t.Errorf("b should own no shards after shutdown, got %v", owned)
+	}
+	if _, err := client.CoordinationV1().Leases("default").Get(ctx, b.memberName(), metav1.GetOptions{}); err == nil {
+		t.Errorf("member lease of b should be deleted on shutdown")
+	}
+	clock.Step(time.Second)
+	a.reconcile(ctx)
+	if owned := a.Owned(); !reflect.DeepEqual(owned, []int{0, 1, 2, 3}) {
+		t.Errorf("a should acquire the released shards at once, got %v", owned)
+	}
+}
+
+func TestManagerLockWaitsForSyncs(t *testing.T) {
+	m := newTestManager(t, fake.NewSimpleClientset(), testingclock.NewFakeClock(time.Now()), "a")
+	m.reconcile(context.Background())
+	key := keyOfShard(m, 0)
+	unlock, owned := m.Lock(key)
+	if !owned {
+		t.Fatalf("key %s should be owned", key)
+	}
+	done := make(chan struct{})
+	go func() {
+		m.giveUp(0)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatalf("shard should not be given up while a sync is in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	<-done
+	if _, owned := m.Lock(key); owned {
+		t.Errorf("key %s should not be owned after its shard is given up", key)
+	}
+
+	var none *Manager
+	if _, owned := none.Lock(key); !owned {
+		t.Errorf("a nil manager should own all keys")
+	}
+}
+
+func TestManagerLockFailsBeforeTakeOver(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	clock := testingclock.NewFakeClock(time.Now())
+	a := newTestManager(t, client, clock, "a")
+	b := newTestManager(t, client, clock, "b")
+	a.reconcile(ctx)
+	b.reconcile(ctx)
+	key := keyOfShard(a, 0)
+
+	// the Leases of a can no longer be renewed
+	client.PrependReactor("update", "leases", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lease := action.(k8stesting.UpdateAction).GetObject().(*coordinationv1.Lease)
+		if holderIdentity(lease) == "a" {
+			return true, nil, errors.New("unavailable")
+		}
+		return false, nil, nil
+	})
+	clock.Step(5 * time.Second)
+	a.reconcile(ctx)
+	if unlock, owned := a.Lock(key); !owned {
+		t.Fatalf("key %s should be owned until the renew deadline", key)
+	} else {
+		unlock()
+	}
+
+	// the renew deadline passes without a reconcile of a
+	clock.Step(5 * time.Second)
+	if _, owned := a.Lock(key); owned {
+		t.Errorf("key %s should not be owned once the renew deadline passed", key)
+	}
+	b.reconcile(ctx)
+	if _, owned := b.Lock(key); owned {
+		t.Fatalf("the lease of shard 0 should not expire before the renew deadline of a")
+	}
+
+	// the Leases of a expire and are taken over
+	clock.Step(10 * time.Second)
+	b.reconcile(ctx)
+	clock.Step(10 * time.Second)
+	b.reconcile(ctx)
+	if owned := b.Owned(); !reflect.DeepEqual(owned, []int{0, 1, 2, 3}) {
+		t.Errorf("b should take over the expired shards, got %v", owned)
+	}
+	if _, owned := a.Lock(key); owned {
+		t.Errorf("key %s should not be owned by a after b took it over", key)
+	}
+}
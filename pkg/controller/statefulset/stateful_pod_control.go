@@ -18,13 +18,16 @@ package statefulset
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
 	statefulsetlisters "github.com/pingcap/advanced-statefulset/client/client/listers/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	errorutils "k8s.io/apimachinery/pkg/util/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -32,6 +35,7 @@ import (
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
 )
 
 // StatefulPodControlInterface defines the interface that StatefulSetController uses to create, update, and delete Pods,
@@ -47,9 +51,29 @@ type StatefulPodControlInterface interface {
 	// pod is an in-out parameter, and any updates made to the pod are reflected as mutations to this parameter. If
 	// the create is successful, the returned error is nil.
 	UpdateStatefulPod(set *apps.StatefulSet, pod *v1.Pod) error
-	// DeleteStatefulPod deletes a Pod in a StatefulSet. The pods PVCs are not deleted. If the delete is successful,
-	// the returned error is nil.
+	// DeleteStatefulPod deletes a Pod in a StatefulSet. The pods PVCs are not deleted. The delete is preconditioned
+	// on the UID and resourceVersion of pod, if it fails because they do not match, an error for which
+	// isPreconditionFailed is true is returned. If the delete is successful, the returned error is nil.
 	DeleteStatefulPod(set *apps.StatefulSet, pod *v1.Pod) error
+	// EvictStatefulPod deletes a Pod in a StatefulSet with the Eviction API. The pods PVCs are not deleted. The
+	// eviction is preconditioned like DeleteStatefulPod. If the eviction would violate a PodDisruptionBudget, an
+	// error for which apierrors.IsTooManyRequests is true is returned. If the eviction is successful, the returned
+	// error is nil.
+	EvictStatefulPod(set *apps.StatefulSet, pod *v1.Pod) error
+	// UpdateStatefulPodRevision sets the revision of a Pod in a StatefulSet to revision without recreating it. It is
+	// used for Pods whose template does not change at revision. If the update is successful, the returned error is nil.
+	UpdateStatefulPodRevision(set *apps.StatefulSet, pod *v1.Pod, revision string) error
+	// ListPersistentVolumeClaims returns the existing PersistentVolumeClaims of a Pod in a StatefulSet keyed by the
+	// name of their volumeClaimTemplate. Claims that do not exist are omitted.
+	ListPersistentVolumeClaims(set *apps.StatefulSet, pod *v1.Pod) (map[string]*v1.PersistentVolumeClaim, error)
+	// UpdatePersistentVolumeClaims expands the storage requests of the existing PersistentVolumeClaims of a Pod in a
+	// StatefulSet to the requests of the StatefulSet's volumeClaimTemplates. Claims are never shrunk. If all claims
+	// have been updated, the returned error is nil.
+	UpdatePersistentVolumeClaims(set *apps.StatefulSet, pod *v1.Pod) error
+	// DeletePersistentVolumeClaims deletes the existing PersistentVolumeClaims of a Pod in a StatefulSet. The Pod is
+	// not deleted. The deletes are preconditioned like DeleteStatefulPod on the cached claims. If the delete is
+	// successful, the returned error is nil.
+	DeletePersistentVolumeClaims(set *apps.StatefulSet, pod *v1.Pod) error
 }
 
 func NewRealStatefulPodControl(
@@ -58,8 +82,9 @@ func NewRealStatefulPodControl(
 	podLister corelisters.PodLister,
 	pvcLister corelisters.PersistentVolumeClaimLister,
 	recorder record.EventRecorder,
+	expectations *controllerExpectations,
 ) StatefulPodControlInterface {
-	return &realStatefulPodControl{client, setLister, podLister, pvcLister, recorder}
+	return &realStatefulPodControl{client, setLister, podLister, pvcLister, recorder, expectations}
 }
 
 // realStatefulPodControl implements StatefulPodControlInterface using a clientset.Interface to communicate with the
@@ -70,6 +95,9 @@ type realStatefulPodControl struct {
 	podLister corelisters.PodLister
 	pvcLister corelisters.PersistentVolumeClaimLister
 	recorder  record.EventRecorder
+	// expectations records the creates and deletes of Pods and PersistentVolumeClaims until they are observed by
+	// the informers of the StatefulSetController, it may be nil.
+	expectations *controllerExpectations
 }
 
 func (spc *realStatefulPodControl) CreateStatefulPod(set *apps.StatefulSet, pod *v1.Pod) error {
@@ -79,7 +107,11 @@ func (spc *realStatefulPodControl) CreateStatefulPod(set *apps.StatefulSet, pod
 		return err
 	}
 	// If we created the PVCs attempt to create the Pod
+	spc.expectations.expect(statefulSetKey(set), podKind, pod, true)
 	_, err := spc.client.CoreV1().Pods(set.Namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+	if err != nil {
+		spc.expectations.forget(podKind, pod)
+	}
 	// sink already exists errors
 	if apierrors.IsAlreadyExists(err) {
 		return err
@@ -136,11 +168,196 @@ func (spc *realStatefulPodControl) UpdateStatefulPod(set *apps.StatefulSet, pod
 }
 
 func (spc *realStatefulPodControl) DeleteStatefulPod(set *apps.StatefulSet, pod *v1.Pod) error {
-	err := spc.client.CoreV1().Pods(set.Namespace).Delete(context.TODO(), pod.Name, metav1.DeleteOptions{})
+	spc.expectations.expect(statefulSetKey(set), podKind, pod, false)
+	err := newDeleteError(spc.client.CoreV1().Pods(set.Namespace).Delete(context.TODO(), pod.Name, newPreconditionedDeleteOptions(pod)))
+	if err != nil {
+		spc.expectations.forget(podKind, pod)
+	}
+	if isPreconditionFailed(err) {
+		klog.V(4).Infof("StatefulSet %s/%s did not delete Pod %s as it changed: %v", set.Namespace, set.Name, pod.Name, err)
+		return err
+	}
 	spc.recordPodEvent("delete", set, pod, err)
 	return err
 }
 
+func (spc *realStatefulPodControl) EvictStatefulPod(set *apps.StatefulSet, pod *v1.Pod) error {
+	deleteOptions := newPreconditionedDeleteOptions(pod)
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+		DeleteOptions: &deleteOptions,
+	}
+	spc.expectations.expect(statefulSetKey(set), podKind, pod, false)
+	err := newDeleteError(spc.client.PolicyV1().Evictions(set.Namespace).Evict(context.TODO(), eviction))
+	if err != nil {
+		spc.expectations.forget(podKind, pod)
+	}
+	if isPreconditionFailed(err) {
+		klog.V(4).Infof("StatefulSet %s/%s did not evict Pod %s as it changed: %v", set.Namespace, set.Name, pod.Name, err)
+		return err
+	}
+	spc.recordPodEvent("evict", set, pod, err)
+	return err
+}
+
+func (spc *realStatefulPodControl) UpdateStatefulPodRevision(set *apps.StatefulSet, pod *v1.Pod, revision string) error {
+	// Make a deep copy so we don't mutate the shared cache
+	pod = pod.DeepCopy()
+	setPodRevision(pod, revision)
+	_, err := spc.client.CoreV1().Pods(set.Namespace).Update(context.TODO(), pod, metav1.UpdateOptions{})
+	spc.recordPodEvent("update", set, pod, err)
+	return err
+}
+
+func (spc *realStatefulPodControl) ListPersistentVolumeClaims(set *apps.StatefulSet, pod *v1.Pod) (map[string]*v1.PersistentVolumeClaim, error) {
+	claims := make(map[string]*v1.PersistentVolumeClaim)
+	for name, claim := range getPersistentVolumeClaims(set, pod) {
+		existing, err := spc.pvcLister.PersistentVolumeClaims(claim.Namespace).Get(claim.Name)
+		switch {
+		case apierrors.IsNotFound(err):
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("failed to retrieve PVC %s: %s", claim.Name, err)
+		}
+		claims[name] = existing
+	}
+	return claims, nil
+}
+
+func (spc *realStatefulPodControl) UpdatePersistentVolumeClaims(set *apps.StatefulSet, pod *v1.Pod) error {
+	claims, err := spc.ListPersistentVolumeClaims(set, pod)
+	if err != nil {
+		return err
+	}
+	templates := getPersistentVolumeClaims(set, pod)
+	var errs []error
+	for name, claim := range claims {
+		template := templates[name]
+		if !claimNeedsExpansion(&template, claim) {
+			continue
+		}
+		// Make a deep copy so we don't mutate the shared cache
+		claim = claim.DeepCopy()
+		if claim.Spec.Resources.Requests == nil {
+			claim.Spec.Resources.Requests = v1.ResourceList{}
+		}
+		request := template.Spec.Resources.Requests[v1.ResourceStorage]
+		claim.Spec.Resources.Requests[v1.ResourceStorage] = request
+		_, err := spc.client.CoreV1().PersistentVolumeClaims(claim.Namespace).Update(context.TODO(), claim, metav1.UpdateOptions{})
+		if err != nil {
+			errs = append(errs, newVolumeClaimExpansionError(claim, request, err))
+		}
+		spc.recordClaimEvent("update", set, pod, claim, err)
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+// volumeClaimExpansionRefusedError is returned by UpdatePersistentVolumeClaims for a claim whose expansion is refused
+// by the API server, e.g. because its StorageClass does not allow volume expansion. Retrying it fails the same way.
+type volumeClaimExpansionRefusedError struct {
+	claim   *v1.PersistentVolumeClaim
+	request resource.Quantity
+	err     error
+}
+
+func (e *volumeClaimExpansionRefusedError) Error() string {
+	return fmt.Sprintf("expansion of PVC %s to %s refused: %s", e.claim.Name, e.request.String(), e.err)
+}
+
+func (e *volumeClaimExpansionRefusedError) Unwrap() error {
+	return e.err
+}
+
+// newVolumeClaimExpansionError returns the error of the expansion of claim to request which failed with err.
+func newVolumeClaimExpansionError(claim *v1.PersistentVolumeClaim, request resource.Quantity, err error) error {
+	if apierrors.IsForbidden(err) || apierrors.IsInvalid(err) {
+		return &volumeClaimExpansionRefusedError{claim: claim, request: request, err: err}
+	}
+	return fmt.Errorf("failed to update PVC %s: %s", claim.Name, err)
+}
+
+func (spc *realStatefulPodControl) DeletePersistentVolumeClaims(set *apps.StatefulSet, pod *v1.Pod) error {
+	claims, err := spc.ListPersistentVolumeClaims(set, pod)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	var preconditionErr error
+	for _, claim := range claims {
+		if claim.DeletionTimestamp != nil {
+			continue
+		}
+		spc.expectations.expect(statefulSetKey(set), claimKind, claim, false)
+		err := newDeleteError(spc.client.CoreV1().PersistentVolumeClaims(claim.Namespace).Delete(context.TODO(), claim.Name, newPreconditionedDeleteOptions(claim)))
+		if err != nil {
+			spc.expectations.forget(claimKind, claim)
+		}
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if isPreconditionFailed(err) {
+			klog.V(4).Infof("StatefulSet %s/%s did not delete PVC %s as it changed: %v", set.Namespace, set.Name, claim.Name, err)
+			preconditionErr = err
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete PVC %s: %s", claim.Name, err))
+		}
+		spc.recordClaimEvent("delete", set, pod, claim, err)
+	}
+	if len(errs) == 0 {
+		return preconditionErr
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+// newPreconditionedDeleteOptions returns DeleteOptions whose preconditions are the UID and resourceVersion of obj, so
+// that an object which changed or was recreated with the same name since obj was cached is not deleted. Unset fields
+// of obj are not preconditioned.
+func newPreconditionedDeleteOptions(obj metav1.Object) metav1.DeleteOptions {
+	preconditions := &metav1.Preconditions{}
+	if uid := obj.GetUID(); uid != "" {
+		preconditions.UID = &uid
+	}
+	if resourceVersion := obj.GetResourceVersion(); resourceVersion != "" {
+		preconditions.ResourceVersion = &resourceVersion
+	}
+	return metav1.DeleteOptions{Preconditions: preconditions}
+}
+
+// preconditionFailedError is returned for a delete whose preconditions do not match the object. It means that the
+// cache of the controller is stale and the object is synced again once the cache catches up, so it is not a failure.
+type preconditionFailedError struct {
+	err error
+}
+
+func (e *preconditionFailedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *preconditionFailedError) Unwrap() error {
+	return e.err
+}
+
+// newDeleteError returns err, the error of a preconditioned delete, as a preconditionFailedError if the
+// preconditions do not match the object.
+func newDeleteError(err error) error {
+	if apierrors.IsConflict(err) {
+		return &preconditionFailedError{err: err}
+	}
+	return err
+}
+
+// isPreconditionFailed returns true if err is a preconditionFailedError, i.e. it is returned for a delete whose
+// preconditions do not match the object.
+func isPreconditionFailed(err error) bool {
+	var preconditionErr *preconditionFailedError
+	return errors.As(err, &preconditionErr)
+}
+
 // recordPodEvent records an event for verb applied to a Pod in a StatefulSet. If err is nil the generated event will
 // have a reason of v1.EventTypeNormal. If err is not nil the generated event will have a reason of v1.EventTypeWarning.
 func (spc *realStatefulPodControl) recordPodEvent(verb string, set *apps.StatefulSet, pod *v1.Pod, err error) {
@@ -181,11 +398,13 @@ func (spc *realStatefulPodControl) recordClaimEvent(verb string, set *apps.State
 func (spc *realStatefulPodControl) createPersistentVolumeClaims(set *apps.StatefulSet, pod *v1.Pod) error {
 	var errs []error
 	for _, claim := range getPersistentVolumeClaims(set, pod) {
-		_, err := spc.pvcLister.PersistentVolumeClaims(claim.Namespace).Get(claim.Name)
+		existing, err := spc.pvcLister.PersistentVolumeClaims(claim.Namespace).Get(claim.Name)
 		switch {
 		case apierrors.IsNotFound(err):
+			spc.expectations.expect(statefulSetKey(set), claimKind, &claim, true)
 			_, err := spc.client.CoreV1().PersistentVolumeClaims(claim.Namespace).Create(context.TODO(), &claim, metav1.CreateOptions{})
 			if err != nil {
+				spc.expectations.forget(claimKind, &claim)
 				errs = append(errs, fmt.Errorf("failed to create PVC %s: %s", claim.Name, err))
 			}
 			if err == nil || !apierrors.IsAlreadyExists(err) {
@@ -194,8 +413,12 @@ func (spc *realStatefulPodControl) createPersistentVolumeClaims(set *apps.Statef
 		case err != nil:
 			errs = append(errs, fmt.Errorf("failed to retrieve PVC %s: %s", claim.Name, err))
 			spc.recordClaimEvent("create", set, pod, &claim, err)
+		case existing.DeletionTimestamp != nil:
+			// the claim is recreated once it is gone, until then the Pod must not use it
+			errs = append(errs, fmt.Errorf("PVC %s is being deleted", claim.Name))
 		}
-		// TODO: Check resource requirements and accessmodes, update if necessary
+		// storage requests are expanded by UpdatePersistentVolumeClaims
+		// TODO: Check accessmodes, update if necessary
 	}
 	return errorutils.NewAggregate(errs)
 }
@@ -22,15 +22,21 @@ import (
 	"testing"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 
 	core "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/client-go/kubernetes/fake"
 	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/utils/clock"
 )
 
 func TestStatefulPodControlCreatesPods(t *testing.T) {
@@ -40,7 +46,7 @@ func TestStatefulPodControlCreatesPods(t *testing.T) {
 	fakeClient := &fake.Clientset{}
 	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
 	pvcLister := corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
-	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder, nil)
 	fakeClient.AddReactor("get", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
 		return true, nil, apierrors.NewNotFound(action.GetResource().GroupResource(), action.GetResource().Resource)
 	})
@@ -78,7 +84,7 @@ func TestStatefulPodControlCreatePodExists(t *testing.T) {
 		pvcIndexer.Add(&pvc)
 	}
 	pvcLister := corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
-	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder, nil)
 	fakeClient.AddReactor("create", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
 		create := action.(core.CreateAction)
 		return true, create.GetObject(), nil
@@ -105,7 +111,7 @@ func TestStatefulPodControlCreatePodPvcCreateFailure(t *testing.T) {
 	fakeClient := &fake.Clientset{}
 	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
 	pvcLister := corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
-	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder, nil)
 	fakeClient.AddReactor("create", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
 		return true, nil, apierrors.NewInternalError(errors.New("API server down"))
 	})
@@ -143,7 +149,7 @@ func TestStatefulPodControlCreatePodPvcGetFailure(t *testing.T) {
 	fakeClient := &fake.Clientset{}
 	pvcIndexer := &fakeIndexer{getError: errors.New("API server down")}
 	pvcLister := corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
-	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder, nil)
 	fakeClient.AddReactor("create", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
 		return true, nil, apierrors.NewInternalError(errors.New("API server down"))
 	})
@@ -165,6 +171,33 @@ func TestStatefulPodControlCreatePodPvcGetFailure(t *testing.T) {
 	}
 }
 
+func TestStatefulPodControlCreatePodPvcTerminating(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	set := newStatefulSet(3)
+	pod := newStatefulSetPod(set, 0)
+	fakeClient := &fake.Clientset{}
+	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	pvcLister := corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder, nil)
+	for _, claim := range getPersistentVolumeClaims(set, pod) {
+		deleted := metav1.Now()
+		claim.DeletionTimestamp = &deleted
+		pvcIndexer.Add(claim.DeepCopy())
+	}
+	podCreated := false
+	fakeClient.AddReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		podCreated = true
+		create := action.(core.CreateAction)
+		return true, create.GetObject(), nil
+	})
+	if err := control.CreateStatefulPod(set, pod); err == nil {
+		t.Error("Failed to produce error on terminating PVC")
+	}
+	if podCreated {
+		t.Error("Pod should not be created while its PVC is terminating")
+	}
+}
+
 func TestStatefulPodControlCreatePodFailed(t *testing.T) {
 	recorder := record.NewFakeRecorder(10)
 	set := newStatefulSet(3)
@@ -172,7 +205,7 @@ func TestStatefulPodControlCreatePodFailed(t *testing.T) {
 	fakeClient := &fake.Clientset{}
 	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
 	pvcLister := corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
-	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder, nil)
 	fakeClient.AddReactor("create", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
 		create := action.(core.CreateAction)
 		return true, create.GetObject(), nil
@@ -195,12 +228,60 @@ func TestStatefulPodControlCreatePodFailed(t *testing.T) {
 	}
 }
 
+func TestStatefulPodControlExpectations(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	set := newStatefulSet(3)
+	pod := newStatefulSetPod(set, 0)
+	fakeClient := &fake.Clientset{}
+	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	pvcLister := corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
+	expectations := newControllerExpectations(clock.RealClock{})
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder, expectations)
+	fakeClient.AddReactor("create", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
+		create := action.(core.CreateAction)
+		return true, create.GetObject(), nil
+	})
+	var podCreateErr error = apierrors.NewInternalError(errors.New("API server down"))
+	fakeClient.AddReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		return true, nil, podCreateErr
+	})
+	fakeClient.AddReactor("delete", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+	key := statefulSetKey(set)
+
+	// the claims are expected, the pod whose create failed is not
+	if err := control.CreateStatefulPod(set, pod); err == nil {
+		t.Error("Failed to produce error on Pod creation failure")
+	}
+	if setKey, _ := expectations.observePod(pod, true); setKey != "" {
+		t.Errorf("failed create of pod should not be expected")
+	}
+	for _, claim := range getPersistentVolumeClaims(set, pod) {
+		if setKey, _ := expectations.observeClaim(&claim, true); setKey != key {
+			t.Errorf("create of claim %s should be expected", claim.Name)
+		}
+	}
+
+	podCreateErr = nil
+	if err := control.CreateStatefulPod(set, pod); err != nil {
+		t.Fatal(err)
+	}
+	if err := control.DeleteStatefulPod(set, pod); err != nil {
+		t.Fatal(err)
+	}
+	// the delete replaced the create
+	if setKey, _ := expectations.observePod(pod, false); setKey != key {
+		t.Errorf("delete of pod should be expected")
+	}
+}
+
 func TestStatefulPodControlNoOpUpdate(t *testing.T) {
 	recorder := record.NewFakeRecorder(10)
 	set := newStatefulSet(3)
 	pod := newStatefulSetPod(set, 0)
 	fakeClient := &fake.Clientset{}
-	control := NewRealStatefulPodControl(fakeClient, nil, nil, nil, recorder)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, nil, recorder, nil)
 	fakeClient.AddReactor("*", "*", func(action core.Action) (bool, runtime.Object, error) {
 		t.Error("no-op update should not make any client invocation")
 		return true, nil, apierrors.NewInternalError(errors.New("if we are here we have a problem"))
@@ -219,7 +300,7 @@ func TestStatefulPodControlUpdatesIdentity(t *testing.T) {
 	set := newStatefulSet(3)
 	pod := newStatefulSetPod(set, 0)
 	fakeClient := fake.NewSimpleClientset(pod)
-	control := NewRealStatefulPodControl(fakeClient, nil, nil, nil, recorder)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, nil, recorder, nil)
 	var updated *v1.Pod
 	fakeClient.PrependReactor("update", "pods", func(action core.Action) (bool, runtime.Object, error) {
 		update := action.(core.UpdateAction)
@@ -251,7 +332,7 @@ func TestStatefulPodControlUpdateIdentityFailure(t *testing.T) {
 	gooPod.Name = "goo-0"
 	indexer.Add(gooPod)
 	podLister := corelisters.NewPodLister(indexer)
-	control := NewRealStatefulPodControl(fakeClient, nil, podLister, nil, recorder)
+	control := NewRealStatefulPodControl(fakeClient, nil, podLister, nil, recorder, nil)
 	fakeClient.AddReactor("update", "pods", func(action core.Action) (bool, runtime.Object, error) {
 		pod.Name = "goo-0"
 		return true, nil, apierrors.NewInternalError(errors.New("API server down"))
@@ -278,7 +359,7 @@ func TestStatefulPodControlUpdatesPodStorage(t *testing.T) {
 	fakeClient := &fake.Clientset{}
 	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
 	pvcLister := corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
-	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder, nil)
 	pvcs := getPersistentVolumeClaims(set, pod)
 	volumes := make([]v1.Volume, 0, len(pod.Spec.Volumes))
 	for i := range pod.Spec.Volumes {
@@ -325,7 +406,7 @@ func TestStatefulPodControlUpdatePodStorageFailure(t *testing.T) {
 	fakeClient := &fake.Clientset{}
 	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
 	pvcLister := corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
-	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder, nil)
 	pvcs := getPersistentVolumeClaims(set, pod)
 	volumes := make([]v1.Volume, 0, len(pod.Spec.Volumes))
 	for i := range pod.Spec.Volumes {
@@ -365,7 +446,7 @@ func TestStatefulPodControlUpdatePodConflictSuccess(t *testing.T) {
 	gooPod.Name = "goo-0"
 	indexer.Add(gooPod)
 	podLister := corelisters.NewPodLister(indexer)
-	control := NewRealStatefulPodControl(fakeClient, nil, podLister, nil, recorder)
+	control := NewRealStatefulPodControl(fakeClient, nil, podLister, nil, recorder, nil)
 	conflict := false
 	fakeClient.AddReactor("update", "pods", func(action core.Action) (bool, runtime.Object, error) {
 		update := action.(core.UpdateAction)
@@ -396,7 +477,7 @@ func TestStatefulPodControlDeletesStatefulPod(t *testing.T) {
 	set := newStatefulSet(3)
 	pod := newStatefulSetPod(set, 0)
 	fakeClient := &fake.Clientset{}
-	control := NewRealStatefulPodControl(fakeClient, nil, nil, nil, recorder)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, nil, recorder, nil)
 	fakeClient.AddReactor("delete", "pods", func(action core.Action) (bool, runtime.Object, error) {
 		return true, nil, nil
 	})
@@ -411,12 +492,55 @@ func TestStatefulPodControlDeletesStatefulPod(t *testing.T) {
 	}
 }
 
+func TestStatefulPodControlEvictsStatefulPod(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	set := newStatefulSet(3)
+	pod := newStatefulSetPod(set, 0)
+	fakeClient := &fake.Clientset{}
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, nil, recorder, nil)
+	fakeClient.AddReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			t.Errorf("got subresource %q, want eviction", action.GetSubresource())
+		}
+		return true, nil, nil
+	})
+	if err := control.EvictStatefulPod(set, pod); err != nil {
+		t.Errorf("Error returned on successful eviction: %s", err)
+	}
+	events := collectEvents(recorder.Events)
+	if eventCount := len(events); eventCount != 1 {
+		t.Errorf("evict successful: got %d events, but want 1", eventCount)
+	} else if !strings.Contains(events[0], v1.EventTypeNormal) {
+		t.Errorf("Found unexpected non-normal event %s", events[0])
+	}
+}
+
+func TestStatefulPodControlEvictBlocked(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	set := newStatefulSet(3)
+	pod := newStatefulSetPod(set, 0)
+	fakeClient := &fake.Clientset{}
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, nil, recorder, nil)
+	fakeClient.AddReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+	})
+	if err := control.EvictStatefulPod(set, pod); !apierrors.IsTooManyRequests(err) {
+		t.Errorf("got error %v on blocked eviction, want TooManyRequests", err)
+	}
+	events := collectEvents(recorder.Events)
+	if eventCount := len(events); eventCount != 1 {
+		t.Errorf("evict failed: got %d events, but want 1", eventCount)
+	} else if !strings.Contains(events[0], v1.EventTypeWarning) {
+		t.Errorf("Found unexpected non-warning event %s", events[0])
+	}
+}
+
 func TestStatefulPodControlDeleteFailure(t *testing.T) {
 	recorder := record.NewFakeRecorder(10)
 	set := newStatefulSet(3)
 	pod := newStatefulSetPod(set, 0)
 	fakeClient := &fake.Clientset{}
-	control := NewRealStatefulPodControl(fakeClient, nil, nil, nil, recorder)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, nil, recorder, nil)
 	fakeClient.AddReactor("delete", "pods", func(action core.Action) (bool, runtime.Object, error) {
 		return true, nil, apierrors.NewInternalError(errors.New("API server down"))
 	})
@@ -431,6 +555,173 @@ func TestStatefulPodControlDeleteFailure(t *testing.T) {
 	}
 }
 
+func TestStatefulPodControlUpdatesPersistentVolumeClaims(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	set := newStatefulSet(3)
+	pod := newStatefulSetPod(set, 0)
+	fakeClient := &fake.Clientset{}
+	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	pvcLister := corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder, nil)
+	for _, claim := range getPersistentVolumeClaims(set, pod) {
+		pvcIndexer.Add(claim.DeepCopy())
+	}
+	size := resource.MustParse("2Gi")
+	set.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[v1.ResourceStorage] = size
+	var updated *v1.PersistentVolumeClaim
+	fakeClient.AddReactor("update", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
+		update := action.(core.UpdateAction)
+		updated = update.GetObject().(*v1.PersistentVolumeClaim)
+		return true, update.GetObject(), nil
+	})
+	if err := control.UpdatePersistentVolumeClaims(set, pod); err != nil {
+		t.Errorf("Successful update returned an error: %s", err)
+	}
+	events := collectEvents(recorder.Events)
+	if eventCount := len(events); eventCount != 1 {
+		t.Errorf("Claim update successful: got %d events, but want 1", eventCount)
+	}
+	for i := range events {
+		if !strings.Contains(events[i], v1.EventTypeNormal) {
+			t.Errorf("Found unexpected non-normal event %s", events[i])
+		}
+	}
+	if updated == nil {
+		t.Fatal("Claim was not updated")
+	}
+	if request := updated.Spec.Resources.Requests[v1.ResourceStorage]; request.Cmp(size) != 0 {
+		t.Errorf("Claim storage request: got %s, want %s", request.String(), size.String())
+	}
+
+	// claims are never shrunk
+	pvcIndexer.Update(updated)
+	updated = nil
+	set.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[v1.ResourceStorage] = resource.MustParse("1")
+	if err := control.UpdatePersistentVolumeClaims(set, pod); err != nil {
+		t.Errorf("Successful update returned an error: %s", err)
+	}
+	if updated != nil {
+		t.Errorf("Claim should not be shrunk")
+	}
+}
+
+func TestStatefulPodControlDeletesPersistentVolumeClaims(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	set := newStatefulSet(3)
+	pod := newStatefulSetPod(set, 0)
+	fakeClient := &fake.Clientset{}
+	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	pvcLister := corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder, nil)
+	for _, claim := range getPersistentVolumeClaims(set, pod) {
+		pvcIndexer.Add(claim.DeepCopy())
+	}
+	var deleted []string
+	fakeClient.AddReactor("delete", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
+		deleted = append(deleted, action.(core.DeleteAction).GetName())
+		return true, nil, nil
+	})
+	if err := control.DeletePersistentVolumeClaims(set, pod); err != nil {
+		t.Errorf("Error returned on successful delete: %s", err)
+	}
+	if len(deleted) != len(set.Spec.VolumeClaimTemplates) {
+		t.Errorf("Claims deleted: got %v, want one claim per template", deleted)
+	}
+	events := collectEvents(recorder.Events)
+	if eventCount := len(events); eventCount != len(deleted) {
+		t.Errorf("delete successful: got %d events, but want %d", eventCount, len(deleted))
+	}
+	for i := range events {
+		if !strings.Contains(events[i], v1.EventTypeNormal) {
+			t.Errorf("Found unexpected non-normal event %s", events[i])
+		}
+	}
+}
+
+func TestStatefulPodControlDeletePreconditions(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	set := newStatefulSet(3)
+	pod := newStatefulSetPod(set, 0)
+	pod.UID = "pod-uid"
+	pod.ResourceVersion = "1"
+	fakeClient := &fake.Clientset{}
+	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	pvcLister := corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder, nil)
+	for _, claim := range getPersistentVolumeClaims(set, pod) {
+		claim := claim.DeepCopy()
+		claim.UID = types.UID(claim.Name + "-uid")
+		claim.ResourceVersion = "2"
+		pvcIndexer.Add(claim)
+	}
+	checkPreconditions := func(kind string, options *metav1.DeleteOptions, uid types.UID, resourceVersion string) {
+		if options == nil || options.Preconditions == nil ||
+			options.Preconditions.UID == nil || *options.Preconditions.UID != uid ||
+			options.Preconditions.ResourceVersion == nil || *options.Preconditions.ResourceVersion != resourceVersion {
+			t.Errorf("%s: got delete options %v, want preconditions on UID %s and resourceVersion %s",
+				kind, options, uid, resourceVersion)
+		}
+	}
+	fakeClient.AddReactor("delete", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		options := action.(core.DeleteActionImpl).DeleteOptions
+		checkPreconditions("delete", &options, pod.UID, pod.ResourceVersion)
+		return true, nil, nil
+	})
+	fakeClient.AddReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		eviction := action.(core.CreateAction).GetObject().(*policyv1.Eviction)
+		checkPreconditions("evict", eviction.DeleteOptions, pod.UID, pod.ResourceVersion)
+		return true, nil, nil
+	})
+	fakeClient.AddReactor("delete", "persistentvolumeclaims", func(action core.Action) (bool, runtime.Object, error) {
+		name := action.(core.DeleteActionImpl).GetName()
+		options := action.(core.DeleteActionImpl).DeleteOptions
+		checkPreconditions("delete claim", &options, types.UID(name+"-uid"), "2")
+		return true, nil, nil
+	})
+	if err := control.DeleteStatefulPod(set, pod); err != nil {
+		t.Errorf("Error returned on successful delete: %s", err)
+	}
+	if err := control.EvictStatefulPod(set, pod); err != nil {
+		t.Errorf("Error returned on successful eviction: %s", err)
+	}
+	if err := control.DeletePersistentVolumeClaims(set, pod); err != nil {
+		t.Errorf("Error returned on successful delete: %s", err)
+	}
+}
+
+func TestStatefulPodControlDeletePreconditionFailed(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	set := newStatefulSet(3)
+	pod := newStatefulSetPod(set, 0)
+	pod.UID = "stale-uid"
+	fakeClient := &fake.Clientset{}
+	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	pvcLister := corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
+	control := NewRealStatefulPodControl(fakeClient, nil, nil, pvcLister, recorder, nil)
+	for _, claim := range getPersistentVolumeClaims(set, pod) {
+		pvcIndexer.Add(claim.DeepCopy())
+	}
+	conflict := func(action core.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: action.GetResource().Resource}, pod.Name,
+			errors.New("Precondition failed: UID in precondition: stale-uid, UID in object meta: fresh-uid"))
+	}
+	fakeClient.AddReactor("delete", "pods", conflict)
+	fakeClient.AddReactor("create", "pods", conflict)
+	fakeClient.AddReactor("delete", "persistentvolumeclaims", conflict)
+	if err := control.DeleteStatefulPod(set, pod); !isPreconditionFailed(err) {
+		t.Errorf("delete: got error %v, want a failed precondition", err)
+	}
+	if err := control.EvictStatefulPod(set, pod); !isPreconditionFailed(err) {
+		t.Errorf("evict: got error %v, want a failed precondition", err)
+	}
+	if err := control.DeletePersistentVolumeClaims(set, pod); !isPreconditionFailed(err) {
+		t.Errorf("delete claims: got error %v, want a failed precondition", err)
+	}
+	if events := collectEvents(recorder.Events); len(events) != 0 {
+		t.Errorf("failed preconditions should not record events, got %v", events)
+	}
+}
+
 func collectEvents(source <-chan string) []string {
 	done := false
 	events := make([]string, 0)
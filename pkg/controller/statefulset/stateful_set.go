@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sync"
 	"time"
 
 	kubeapps "k8s.io/api/apps/v1"
@@ -29,16 +30,18 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
-	kubeappsinformers "k8s.io/client-go/informers/apps/v1"
 	coreinformers "k8s.io/client-go/informers/core/v1"
+	policyinformers "k8s.io/client-go/informers/policy/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
+	policylisters "k8s.io/client-go/listers/policy/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
@@ -46,6 +49,7 @@ import (
 	asscheme "github.com/pingcap/advanced-statefulset/client/client/clientset/versioned/scheme"
 	appsinformers "github.com/pingcap/advanced-statefulset/client/client/informers/externalversions/apps/v1"
 	appslisters "github.com/pingcap/advanced-statefulset/client/client/listers/apps/v1"
+	"github.com/pingcap/advanced-statefulset/pkg/controller/sharding"
 	"github.com/pingcap/advanced-statefulset/pkg/third_party/k8s"
 )
 
@@ -66,8 +70,13 @@ type StatefulSetController struct {
 	control StatefulSetControlInterface
 	// podControl is used for patching pods.
 	podControl k8s.PodControlInterface
+	// recorder is used to record events on stateful sets.
+	recorder record.EventRecorder
 	// podLister is able to list/get pods from a shared informer's store
 	podLister corelisters.PodLister
+	// podIndexer indexes the pods of the shared informer by controller and by parent name. It is nil if the indexes
+	// could not be added.
+	podIndexer cache.Indexer
 	// podListerSynced returns true if the pod shared informer has synced at least once
 	podListerSynced cache.InformerSynced
 	// setLister is able to list/get stateful sets from a shared informer's store
@@ -78,6 +87,20 @@ type StatefulSetController struct {
 	pvcListerSynced cache.InformerSynced
 	// revListerSynced returns true if the rev shared informer has synced at least once
 	revListerSynced cache.InformerSynced
+	// pdbLister is able to list/get pod disruption budgets from a shared informer's store
+	pdbLister policylisters.PodDisruptionBudgetLister
+	// pdbListerSynced returns true if the pod disruption budget shared informer has synced at least once
+	pdbListerSynced cache.InformerSynced
+	// informerSelector is the label selector the pod, pvc and rev shared informers are restricted to
+	informerSelector labels.Selector
+	// shards are the shards of the StatefulSets owned by this replica, all StatefulSets are owned if nil.
+	shards *sharding.Manager
+	// expectations records the creates and deletes of Pods and PersistentVolumeClaims which are not observed yet.
+	expectations *controllerExpectations
+	// outsideInformerFilter records the generations of the StatefulSets which are not synced because their Pods or
+	// PersistentVolumeClaims do not match informerSelector, so that they are warned about once per generation.
+	outsideInformerFilterLock sync.Mutex
+	outsideInformerFilter     map[string]int64
 	// StatefulSets that need to be synced.
 	queue workqueue.RateLimitingInterface
 }
@@ -86,12 +109,19 @@ func init() {
 	scheme.AddToScheme(asscheme.Scheme)
 }
 
-// NewStatefulSetController creates a new statefulset controller.
+// NewStatefulSetController creates a new statefulset controller. If dryRun is true, the creates, updates and deletes
+// of Pods and PersistentVolumeClaims and the updates of the Status of StatefulSets are recorded as logs and events
+// instead of being performed. The other writes of the controller go through kubeClient and pcClient.
 func NewStatefulSetController(
 	podInformer coreinformers.PodInformer,
 	setInformer appsinformers.StatefulSetInformer,
 	pvcInformer coreinformers.PersistentVolumeClaimInformer,
-	revInformer kubeappsinformers.ControllerRevisionInformer,
+	revInformer cache.SharedIndexInformer,
+	pdbInformer policyinformers.PodDisruptionBudgetInformer,
+	informerSelector labels.Selector,
+	shards *sharding.Manager,
+	queueConfig QueueConfig,
+	dryRun bool,
 	kubeClient kubernetes.Interface,
 	pcClient clientset.Interface,
 ) *StatefulSetController {
@@ -99,26 +129,41 @@ func NewStatefulSetController(
 	eventBroadcaster.StartLogging(klog.Infof)
 	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
 	recorder := eventBroadcaster.NewRecorder(asscheme.Scheme, v1.EventSource{Component: "statefulset-controller"})
+	expectations := newControllerExpectations(clock.RealClock{})
+
+	podControl := NewRealStatefulPodControl(
+		kubeClient,
+		setInformer.Lister(),
+		podInformer.Lister(),
+		pvcInformer.Lister(),
+		recorder,
+		expectations)
+	statusUpdater := NewRealStatefulSetStatusUpdater(pcClient, setInformer.Lister())
+	if dryRun {
+		podControl = NewDryRunStatefulPodControl(pvcInformer.Lister(), recorder)
+		statusUpdater = NewDryRunStatefulSetStatusUpdater(recorder)
+	}
 
 	ssc := &StatefulSetController{
 		kubeClient: kubeClient,
 		pcClient:   pcClient,
 		control: NewDefaultStatefulSetControl(
-			NewRealStatefulPodControl(
-				kubeClient,
-				setInformer.Lister(),
-				podInformer.Lister(),
-				pvcInformer.Lister(),
-				recorder),
-			NewRealStatefulSetStatusUpdater(pcClient, setInformer.Lister()),
+			podControl,
+			statusUpdater,
 			kubeClient.AppsV1(),
 			recorder,
 		),
 		pvcListerSynced: pvcInformer.Informer().HasSynced,
-		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "statefulset"),
+		queue:           newStatefulSetQueue(queueConfig),
 		podControl:      k8s.RealPodControl{KubeClient: kubeClient, Recorder: recorder},
+		recorder:        recorder,
+		expectations:    expectations,
+
+		revListerSynced:  revInformer.HasSynced,
+		informerSelector: informerSelector,
+		shards:           shards,
 
-		revListerSynced: revInformer.Informer().HasSynced,
+		outsideInformerFilter: make(map[string]int64),
 	}
 
 	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -131,6 +176,11 @@ func NewStatefulSetController(
 	})
 	ssc.podLister = podInformer.Lister()
 	ssc.podListerSynced = podInformer.Informer().HasSynced
+	if err := addPodIndexers(podInformer.Informer()); err != nil {
+		klog.Warningf("Failed to add pod indexers, pods are listed by namespace: %v", err)
+	} else {
+		ssc.podIndexer = podInformer.Informer().GetIndexer()
+	}
 
 	setInformer.Informer().AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
@@ -149,11 +199,34 @@ func NewStatefulSetController(
 	ssc.setLister = setInformer.Lister()
 	ssc.setListerSynced = setInformer.Informer().HasSynced
 
+	pdbInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, cur interface{}) {
+			ssc.enqueuePodDisruptionBudgetOwner(cur)
+		},
+		DeleteFunc: ssc.enqueuePodDisruptionBudgetOwner,
+	})
+	ssc.pdbLister = pdbInformer.Lister()
+	ssc.pdbListerSynced = pdbInformer.Informer().HasSynced
+
+	pvcInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: ssc.addPVC,
+		UpdateFunc: func(old, cur interface{}) {
+			if cur.(*v1.PersistentVolumeClaim).DeletionTimestamp != nil {
+				ssc.deletePVC(cur)
+			}
+		},
+		DeleteFunc: ssc.deletePVC,
+	})
+
+	// the StatefulSets of acquired shards may have changed while they were owned by another replica
+	shards.AddAcquiredHandler(ssc.enqueueAllStatefulSets)
+
 	// TODO: Watch volumes
 	return ssc
 }
 
-// Run runs the statefulset controller.
+// Run runs the statefulset controller. Once stopCh is closed, no new sync is started, and Run returns when the syncs
+// in flight are done.
 func (ssc *StatefulSetController) Run(workers int, stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
 	defer ssc.queue.ShutDown()
@@ -161,15 +234,22 @@ func (ssc *StatefulSetController) Run(workers int, stopCh <-chan struct{}) {
 	klog.Infof("Starting stateful set controller")
 	defer klog.Infof("Shutting down statefulset controller")
 
-	if !cache.WaitForNamedCacheSync("stateful set", stopCh, ssc.podListerSynced, ssc.setListerSynced, ssc.pvcListerSynced, ssc.revListerSynced) {
+	if !cache.WaitForNamedCacheSync("stateful set", stopCh, ssc.podListerSynced, ssc.setListerSynced, ssc.pvcListerSynced, ssc.revListerSynced, ssc.pdbListerSynced) {
 		return
 	}
 
+	var workersDone sync.WaitGroup
 	for i := 0; i < workers; i++ {
-		go wait.Until(ssc.worker, time.Second, stopCh)
+		workersDone.Add(1)
+		go func() {
+			defer workersDone.Done()
+			wait.Until(ssc.worker, time.Second, stopCh)
+		}()
 	}
 
 	<-stopCh
+	ssc.queue.ShutDown()
+	workersDone.Wait()
 }
 
 // addPod adds the statefulset for the pod to the sync queue
@@ -182,6 +262,7 @@ func (ssc *StatefulSetController) addPod(obj interface{}) {
 		ssc.deletePod(pod)
 		return
 	}
+	ssc.expectations.observePod(pod, true)
 
 	// If it has a ControllerRef, that's all that matters.
 	if controllerRef := metav1.GetControllerOf(pod); controllerRef != nil {
@@ -215,6 +296,10 @@ func (ssc *StatefulSetController) updatePod(old, cur interface{}) {
 		// Two different versions of the same pod will always have different RVs.
 		return
 	}
+	// a Pod is deleted once it is terminating
+	if curPod.DeletionTimestamp != nil {
+		ssc.expectations.observePod(curPod, false)
+	}
 
 	labelChanged := !reflect.DeepEqual(curPod.Labels, oldPod.Labels)
 
@@ -272,6 +357,7 @@ func (ssc *StatefulSetController) deletePod(obj interface{}) {
 			return
 		}
 	}
+	ssc.expectations.observePod(pod, false)
 
 	controllerRef := metav1.GetControllerOf(pod)
 	if controllerRef == nil {
@@ -286,6 +372,40 @@ func (ssc *StatefulSetController) deletePod(obj interface{}) {
 	ssc.enqueueStatefulSet(set)
 }
 
+// addPVC records the create of the PersistentVolumeClaim and enqueues the statefulset which created it once all of its
+// creates and deletes are observed.
+func (ssc *StatefulSetController) addPVC(obj interface{}) {
+	claim := obj.(*v1.PersistentVolumeClaim)
+	if claim.DeletionTimestamp != nil {
+		ssc.deletePVC(claim)
+		return
+	}
+	if key, satisfied := ssc.expectations.observeClaim(claim, true); satisfied {
+		ssc.queue.Add(key)
+	}
+}
+
+// deletePVC records the delete of the PersistentVolumeClaim, accounting for deletion tombstones, and enqueues the
+// statefulset which deleted it once all of its creates and deletes are observed.
+func (ssc *StatefulSetController) deletePVC(obj interface{}) {
+	claim, ok := obj.(*v1.PersistentVolumeClaim)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("couldn't get object from tombstone %+v", obj))
+			return
+		}
+		claim, ok = tombstone.Obj.(*v1.PersistentVolumeClaim)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("tombstone contained object that is not a PersistentVolumeClaim %+v", obj))
+			return
+		}
+	}
+	if key, satisfied := ssc.expectations.observeClaim(claim, false); satisfied {
+		ssc.queue.Add(key)
+	}
+}
+
 // getPodsForStatefulSet returns the Pods that a given StatefulSet should manage.
 // It also reconciles ControllerRef by adopting/orphaning.
 //
@@ -293,9 +413,9 @@ func (ssc *StatefulSetController) deletePod(obj interface{}) {
 //
 //	If you need to modify one, you need to copy it first.
 func (ssc *StatefulSetController) getPodsForStatefulSet(set *apps.StatefulSet, selector labels.Selector) ([]*v1.Pod, error) {
-	// List all pods to include the pods that don't match the selector anymore but
-	// has a ControllerRef pointing to this StatefulSet.
-	pods, err := ssc.podLister.Pods(set.Namespace).List(labels.Everything())
+	// List the pods with a ControllerRef pointing to this StatefulSet, including the pods that don't match the
+	// selector anymore, and the orphans which may be adopted.
+	pods, err := ssc.listPodsForStatefulSet(set)
 	if err != nil {
 		return nil, err
 	}
@@ -322,6 +442,31 @@ func (ssc *StatefulSetController) getPodsForStatefulSet(set *apps.StatefulSet, s
 	return cm.ClaimPods(context.TODO(), pods, filter)
 }
 
+// listPodsForStatefulSet returns the pods which are controlled by set and the orphaned pods whose parent name is the
+// name of set, which are the only pods ClaimPods may claim for set. Without the pod indexes, all pods in the
+// namespace of set are returned.
+func (ssc *StatefulSetController) listPodsForStatefulSet(set *apps.StatefulSet) ([]*v1.Pod, error) {
+	if ssc.podIndexer == nil {
+		return ssc.podLister.Pods(set.Namespace).List(labels.Everything())
+	}
+	owned, err := ssc.podIndexer.ByIndex(podControllerUIDIndex, string(set.UID))
+	if err != nil {
+		return nil, err
+	}
+	orphans, err := ssc.podIndexer.ByIndex(orphanPodParentIndex, set.Namespace+"/"+set.Name)
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]*v1.Pod, 0, len(owned)+len(orphans))
+	for _, obj := range owned {
+		pods = append(pods, obj.(*v1.Pod))
+	}
+	for _, obj := range orphans {
+		pods = append(pods, obj.(*v1.Pod))
+	}
+	return pods, nil
+}
+
 func shouldSyncLabels(revision *kubeapps.ControllerRevision) bool {
 	labels := revision.ObjectMeta.Labels
 	if labels == nil {
@@ -429,6 +574,28 @@ func (ssc *StatefulSetController) enqueueStatefulSet(obj interface{}) {
 	ssc.queue.Add(key)
 }
 
+// enqueueAllStatefulSets enqueues all statefulsets in the work queue.
+func (ssc *StatefulSetController) enqueueAllStatefulSets() {
+	sets, err := ssc.setLister.List(labels.Everything())
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't list statefulsets: %v", err))
+		return
+	}
+	for _, set := range sets {
+		ssc.enqueueStatefulSet(set)
+	}
+}
+
+// enqueueStatefulSetAfter enqueues the given statefulset in the work queue after the given duration.
+func (ssc *StatefulSetController) enqueueStatefulSetAfter(obj interface{}, after time.Duration) {
+	key, err := keyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Couldn't get key for object %+v: %v", obj, err))
+		return
+	}
+	ssc.queue.AddAfter(key, after)
+}
+
 // processNextWorkItem dequeues items, processes them, and marks them done. It enforces that the syncHandler is never
 // invoked concurrently with the same key.
 func (ssc *StatefulSetController) processNextWorkItem() bool {
@@ -437,8 +604,19 @@ func (ssc *StatefulSetController) processNextWorkItem() bool {
 		return false
 	}
 	defer ssc.queue.Done(key)
+	// the queue hands out the remaining keys after it is shut down, they are not synced so that the controller
+	// stops promptly
+	if ssc.queue.ShuttingDown() {
+		return false
+	}
 	if err := ssc.sync(key.(string)); err != nil {
-		utilruntime.HandleError(fmt.Errorf("Error syncing StatefulSet %v, requeuing: %v", key.(string), err))
+		// a StatefulSet blocked by a PodDisruptionBudget reports it in its DisruptionBlocked condition, it is only
+		// retried with backoff
+		if err == errDisruptionBlocked {
+			klog.V(4).Infof("StatefulSet %v is blocked by a PodDisruptionBudget, requeuing", key.(string))
+		} else {
+			utilruntime.HandleError(fmt.Errorf("Error syncing StatefulSet %v, requeuing: %v", key.(string), err))
+		}
 		ssc.queue.AddRateLimited(key)
 	} else {
 		ssc.queue.Forget(key)
@@ -459,6 +637,15 @@ func (ssc *StatefulSetController) sync(key string) error {
 		klog.V(4).Infof("Finished syncing statefulset %q (%v)", key, time.Since(startTime))
 	}()
 
+	// The StatefulSets of the shards owned by other replicas are synced by them. The shard is not given up until the
+	// sync is done, so that a StatefulSet is never synced by two replicas at once.
+	unlock, owned := ssc.shards.Lock(key)
+	if !owned {
+		klog.V(4).Infof("StatefulSet %v is not in the shards of this replica, skipping", key)
+		return nil
+	}
+	defer unlock()
+
 	namespace, name, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
 		return err
@@ -466,6 +653,9 @@ func (ssc *StatefulSetController) sync(key string) error {
 	set, err := ssc.setLister.StatefulSets(namespace).Get(name)
 	if errors.IsNotFound(err) {
 		klog.Infof("StatefulSet has been deleted %v", key)
+		ssc.expectations.deleteExpectations(key)
+		ssc.control.ForgetStatefulSet(key)
+		ssc.forgetOutsideInformerFilter(key)
 		return nil
 	}
 	klog.Infof("sts %q found\n", set.Name)
@@ -480,6 +670,28 @@ func (ssc *StatefulSetController) sync(key string) error {
 		return nil
 	}
 
+	// The Pods and claims of a set which do not match the informer selector are not seen by the controller, syncing
+	// the set would create them again and again.
+	if !informerSelectorMatches(set, ssc.informerSelector) {
+		klog.V(2).Infof("StatefulSet %s/%s is not synced as its Pods or PersistentVolumeClaims do not match the informer label selector %q",
+			set.Namespace, set.Name, ssc.informerSelector.String())
+		if ssc.observeOutsideInformerFilter(key, set.Generation) {
+			ssc.recorder.Eventf(set, v1.EventTypeWarning, "SelectorOutsideInformerFilter",
+				"StatefulSet %s/%s is not synced as its Pods or PersistentVolumeClaims do not match the informer label selector %q",
+				set.Namespace, set.Name, ssc.informerSelector.String())
+		}
+		return nil
+	}
+	ssc.forgetOutsideInformerFilter(key)
+
+	// Wait for the creates and deletes of the previous sync to be observed, so that they are not issued again from a
+	// stale cache. The set is enqueued when they are observed, or after they timed out.
+	if satisfied, wait := ssc.expectations.satisfied(key); !satisfied {
+		klog.V(4).Infof("StatefulSet %v/%v is waiting for its creates and deletes to be observed", set.Namespace, set.Name)
+		ssc.queue.AddAfter(key, wait)
+		return nil
+	}
+
 	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
 	if err != nil {
 		utilruntime.HandleError(fmt.Errorf("error converting StatefulSet %v selector: %v", key, err))
@@ -492,6 +704,22 @@ func (ssc *StatefulSetController) sync(key string) error {
 		return err
 	}
 
+	// A rollback only changes the spec of the set, the rolling update itself is done by the sync triggered by
+	// the update.
+	if rolledBack, err := ssc.rollback(set); err != nil || rolledBack {
+		if err != nil {
+			klog.Errorf("rollback: %v\n", err)
+		}
+		return err
+	}
+
+	// Pruning the delete slots only changes the annotations of the set, the sync goes on with the pruned set.
+	set, err = ssc.pruneDeleteSlots(set)
+	if err != nil {
+		klog.Errorf("pruneDeleteSlots: %v\n", err)
+		return err
+	}
+
 	pods, err := ssc.getPodsForStatefulSet(set, selector)
 	if err != nil {
 		klog.Errorf("getPodsForStatefulSet: %v\n", err)
@@ -501,13 +729,53 @@ func (ssc *StatefulSetController) sync(key string) error {
 	return ssc.syncStatefulSet(set, pods)
 }
 
+// observeOutsideInformerFilter records that generation of the StatefulSet with key does not match the informer
+// selector. It returns true if this generation has not been observed yet.
+func (ssc *StatefulSetController) observeOutsideInformerFilter(key string, generation int64) bool {
+	ssc.outsideInformerFilterLock.Lock()
+	defer ssc.outsideInformerFilterLock.Unlock()
+	if observed, ok := ssc.outsideInformerFilter[key]; ok && observed == generation {
+		return false
+	}
+	ssc.outsideInformerFilter[key] = generation
+	return true
+}
+
+// forgetOutsideInformerFilter forgets the StatefulSet with key, which matches the informer selector or is deleted.
+func (ssc *StatefulSetController) forgetOutsideInformerFilter(key string) {
+	ssc.outsideInformerFilterLock.Lock()
+	defer ssc.outsideInformerFilterLock.Unlock()
+	delete(ssc.outsideInformerFilter, key)
+}
+
 // syncStatefulSet syncs a tuple of (statefulset, []*v1.Pod).
 func (ssc *StatefulSetController) syncStatefulSet(set *apps.StatefulSet, pods []*v1.Pod) error {
 	klog.V(4).Infof("Syncing StatefulSet %v/%v with %d pods", set.Namespace, set.Name, len(pods))
 	// TODO: investigate where we mutate the set during the update as it is not obvious.
-	if err := ssc.control.UpdateStatefulSet(set.DeepCopy(), pods); err != nil {
+	status, err := ssc.control.UpdateStatefulSet(set.DeepCopy(), pods)
+	if err != nil {
 		return err
 	}
+	if err := ssc.syncPodDisruptionBudget(set); err != nil {
+		return err
+	}
+	if after := progressDeadlineRequeueAfter(set, status, time.Now()); after > 0 {
+		ssc.enqueueStatefulSetAfter(set, after)
+	}
+	if after := recreateBackoffRequeueAfter(status, time.Now()); after > 0 {
+		ssc.enqueueStatefulSetAfter(set, after)
+	}
+	if needsAutoRollback(set, status) {
+		klog.V(2).Infof("StatefulSet %s/%s exceeded its progress deadline, rolling back to revision %s",
+			set.Namespace, set.Name, status.CurrentRevision)
+		if _, err := helper.Rollback(context.TODO(), ssc.pcClient, set.Namespace, set.Name, status.CurrentRevision); err != nil {
+			return err
+		}
+	}
+	// a blocked eviction is retried with the backoff of the queue
+	if isDisruptionBlocked(status) {
+		return errDisruptionBlocked
+	}
 	klog.V(4).Infof("Successfully synced StatefulSet %s/%s successful", set.Namespace, set.Name)
 	return nil
 }
@@ -0,0 +1,241 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+
+	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+)
+
+const (
+	// initialRecreateBackoff is the delay before a Pod which failed or succeeded after it has been recreated is
+	// recreated again. It doubles with every attempt up to maxRecreateBackoff.
+	initialRecreateBackoff = 10 * time.Second
+	// maxRecreateBackoff is the maximum delay between two recreations of the Pod of an ordinal.
+	maxRecreateBackoff = 5 * time.Minute
+
+	// maxRecreateAttemptsExceededReason is added to the ReplicaFailure condition and recorded as an event when the
+	// Pod of an ordinal has been recreated spec.maxRecreateAttempts times.
+	maxRecreateAttemptsExceededReason = "MaxRecreateAttemptsExceeded"
+)
+
+// recreateAttempts records the recreations of the Pod of an ordinal.
+type recreateAttempts struct {
+	// attempts is the number of recreations since the Pod was last Running and Ready.
+	attempts int32
+	// revision is the update revision of the set when the attempts were made. The attempts are reset when the
+	// update revision changes, as the new revision may fix the Pod.
+	revision string
+	// next is the earliest time the Pod is recreated again.
+	next time.Time
+}
+
+// setRecreateAttempts records the recreations of the ordinals of a StatefulSet.
+type setRecreateAttempts struct {
+	// uid is the UID of the StatefulSet. The recreations are forgotten when a StatefulSet is recreated with the same
+	// name, as its revisions may have the same names.
+	uid types.UID
+	// ordinals maps the ordinals to their recreations.
+	ordinals map[int]*recreateAttempts
+}
+
+// recreateBackoff tracks the recreations of failed and succeeded Pods per ordinal of each StatefulSet in memory, so
+// that a Pod which terminates immediately is recreated with an exponential backoff. It is safe for concurrent use.
+type recreateBackoff struct {
+	clock clock.Clock
+	lock  sync.Mutex
+	// attempts maps the key of a StatefulSet to the recreations of its ordinals.
+	attempts map[string]*setRecreateAttempts
+}
+
+func newRecreateBackoff(clock clock.Clock) *recreateBackoff {
+	return &recreateBackoff{clock: clock, attempts: make(map[string]*setRecreateAttempts)}
+}
+
+// ordinals returns the recreations of the ordinals of set, the recreations of a previous StatefulSet with the same
+// key are forgotten. If create is true, an empty map is recorded for set if it has no recreations, otherwise nil is
+// returned.
+func (b *recreateBackoff) ordinals(set *apps.StatefulSet, create bool) map[int]*recreateAttempts {
+	key := statefulSetKey(set)
+	attempts := b.attempts[key]
+	if attempts != nil && attempts.uid != set.UID {
+		delete(b.attempts, key)
+		attempts = nil
+	}
+	if attempts == nil {
+		if !create {
+			return nil
+		}
+		attempts = &setRecreateAttempts{uid: set.UID, ordinals: make(map[int]*recreateAttempts)}
+		b.attempts[key] = attempts
+	}
+	return attempts.ordinals
+}
+
+// get returns the recreations of ordinal in set at updateRevision. nil is returned if there are none.
+func (b *recreateBackoff) get(set *apps.StatefulSet, ordinal int, updateRevision string) *recreateAttempts {
+	attempts := b.ordinals(set, false)[ordinal]
+	if attempts == nil || attempts.revision != updateRevision {
+		return nil
+	}
+	return attempts
+}
+
+// wait returns the time to wait before the Pod of ordinal in set may be recreated. The returned bool is true if
+// the Pod must not be recreated anymore because it reached set's MaxRecreateAttempts.
+func (b *recreateBackoff) wait(set *apps.StatefulSet, ordinal int, updateRevision string) (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	attempts := b.get(set, ordinal, updateRevision)
+	if attempts == nil {
+		return 0, false
+	}
+	if set.Spec.MaxRecreateAttempts != nil && attempts.attempts >= *set.Spec.MaxRecreateAttempts {
+		return 0, true
+	}
+	if wait := attempts.next.Sub(b.clock.Now()); wait > 0 {
+		return wait, false
+	}
+	return 0, false
+}
+
+// recreated records a recreation of the Pod of ordinal in set and doubles the delay before the next one.
+func (b *recreateBackoff) recreated(set *apps.StatefulSet, ordinal int, updateRevision string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	ordinals := b.ordinals(set, true)
+	attempts := b.get(set, ordinal, updateRevision)
+	if attempts == nil {
+		attempts = &recreateAttempts{revision: updateRevision}
+		ordinals[ordinal] = attempts
+	}
+	delay := initialRecreateBackoff
+	for i := int32(0); i < attempts.attempts && delay < maxRecreateBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRecreateBackoff {
+		delay = maxRecreateBackoff
+	}
+	attempts.attempts++
+	attempts.next = b.clock.Now().Add(delay)
+}
+
+// reset forgets the recreations of the Pod of ordinal in set.
+func (b *recreateBackoff) reset(set *apps.StatefulSet, ordinal int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	ordinals := b.ordinals(set, false)
+	delete(ordinals, ordinal)
+	if len(ordinals) == 0 {
+		delete(b.attempts, statefulSetKey(set))
+	}
+}
+
+// forget forgets the recreations of the ordinals of the StatefulSet with key, it is called when the StatefulSet is
+// deleted.
+func (b *recreateBackoff) forget(key string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.attempts, key)
+}
+
+// status returns the recreations of the ordinals of set at updateRevision sorted by ordinal. Recreations of
+// ordinals which are not replicas of set anymore are forgotten.
+func (b *recreateBackoff) status(set *apps.StatefulSet, updateRevision string) []apps.RecreateBackoff {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	replicas := helper.GetPodOrdinals(*set.Spec.Replicas, set)
+	ordinals := b.ordinals(set, false)
+	var backoffs []apps.RecreateBackoff
+	for ordinal := range ordinals {
+		if !replicas.Has(int32(ordinal)) {
+			delete(ordinals, ordinal)
+			continue
+		}
+		attempts := b.get(set, ordinal, updateRevision)
+		if attempts == nil {
+			continue
+		}
+		backoff := apps.RecreateBackoff{Ordinal: int32(ordinal), Attempts: attempts.attempts}
+		if set.Spec.MaxRecreateAttempts == nil || attempts.attempts < *set.Spec.MaxRecreateAttempts {
+			// the status is stored with a precision of seconds
+			next := metav1.NewTime(attempts.next).Rfc3339Copy()
+			backoff.NextRecreateTime = &next
+		}
+		backoffs = append(backoffs, backoff)
+	}
+	if len(ordinals) == 0 {
+		delete(b.attempts, statefulSetKey(set))
+	}
+	sort.Slice(backoffs, func(i, j int) bool { return backoffs[i].Ordinal < backoffs[j].Ordinal })
+	return backoffs
+}
+
+// recreateBackoffRequeueAfter returns the duration after which set needs to be synced again to recreate a Pod whose
+// backoff expires. Zero is returned if no Pod is waiting.
+func recreateBackoffRequeueAfter(status *apps.StatefulSetStatus, now time.Time) time.Duration {
+	var after time.Duration
+	for _, backoff := range status.RecreateBackoffs {
+		if backoff.NextRecreateTime == nil {
+			continue
+		}
+		// add a second as the time is rounded down to seconds
+		wait := backoff.NextRecreateTime.Sub(now) + time.Second
+		if wait <= 0 {
+			continue
+		}
+		if after == 0 || wait < after {
+			after = wait
+		}
+	}
+	return after
+}
+
+// updateReplicaFailureCondition maintains the ReplicaFailure condition of status if set has MaxRecreateAttempts. The
+// returned bool is true if the condition has become true in this call.
+func updateReplicaFailureCondition(set *apps.StatefulSet, status *apps.StatefulSetStatus) bool {
+	var exceeded []int32
+	for _, backoff := range status.RecreateBackoffs {
+		if set.Spec.MaxRecreateAttempts != nil && backoff.Attempts >= *set.Spec.MaxRecreateAttempts {
+			exceeded = append(exceeded, backoff.Ordinal)
+		}
+	}
+	if len(exceeded) == 0 {
+		status.Conditions = filterOutCondition(status.Conditions, apps.StatefulSetReplicaFailure)
+		return false
+	}
+	cond := getStatefulSetCondition(*status, apps.StatefulSetReplicaFailure)
+	message := fmt.Sprintf("Pods of ordinals %v have been recreated %d times without becoming Running and Ready",
+		exceeded, *set.Spec.MaxRecreateAttempts)
+	if cond != nil && cond.Status == v1.ConditionTrue && cond.Message == message {
+		return false
+	}
+	failure := newStatefulSetCondition(apps.StatefulSetReplicaFailure, v1.ConditionTrue, maxRecreateAttemptsExceededReason, message)
+	if cond != nil && cond.Status == v1.ConditionTrue {
+		failure.LastTransitionTime = cond.LastTransitionTime
+	}
+	status.Conditions = filterOutCondition(status.Conditions, apps.StatefulSetReplicaFailure)
+	setStatefulSetCondition(status, failure)
+	return cond == nil || cond.Status != v1.ConditionTrue
+}
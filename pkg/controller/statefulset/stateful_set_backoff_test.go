@@ -0,0 +1,230 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	testingclock "k8s.io/utils/clock/testing"
+	utilpointer "k8s.io/utils/pointer"
+
+	pcfake "github.com/pingcap/advanced-statefulset/client/client/clientset/versioned/fake"
+
+	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+)
+
+func TestRecreateBackoff(t *testing.T) {
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	backoff := newRecreateBackoff(fakeClock)
+	set := newStatefulSet(3)
+	set.Spec.MaxRecreateAttempts = utilpointer.Int32Ptr(3)
+
+	if wait, exceeded := backoff.wait(set, 1, "rev-1"); wait != 0 || exceeded {
+		t.Errorf("first recreation should not wait, got %v (exceeded: %v)", wait, exceeded)
+	}
+	for i, want := range []time.Duration{initialRecreateBackoff, 2 * initialRecreateBackoff} {
+		backoff.recreated(set, 1, "rev-1")
+		if wait, exceeded := backoff.wait(set, 1, "rev-1"); wait != want || exceeded {
+			t.Errorf("attempt %d: got wait %v (exceeded: %v), want %v", i+1, wait, exceeded, want)
+		}
+		fakeClock.Step(want)
+	}
+	backoff.recreated(set, 1, "rev-1")
+	if _, exceeded := backoff.wait(set, 1, "rev-1"); !exceeded {
+		t.Errorf("max recreate attempts should be exceeded")
+	}
+	backoffs := backoff.status(set, "rev-1")
+	if len(backoffs) != 1 || backoffs[0].Ordinal != 1 || backoffs[0].Attempts != 3 || backoffs[0].NextRecreateTime != nil {
+		t.Errorf("unexpected status %v", backoffs)
+	}
+
+	// a new revision resets the attempts
+	if wait, exceeded := backoff.wait(set, 1, "rev-2"); wait != 0 || exceeded {
+		t.Errorf("new revision should not wait, got %v (exceeded: %v)", wait, exceeded)
+	}
+	if backoffs := backoff.status(set, "rev-2"); len(backoffs) != 0 {
+		t.Errorf("got status %v for a new revision, want none", backoffs)
+	}
+
+	backoff.reset(set, 1)
+	if backoffs := backoff.status(set, "rev-1"); len(backoffs) != 0 {
+		t.Errorf("got status %v after reset, want none", backoffs)
+	}
+
+	// the delay is capped
+	set.Spec.MaxRecreateAttempts = nil
+	for i := 0; i < 10; i++ {
+		backoff.recreated(set, 2, "rev-1")
+	}
+	if wait, _ := backoff.wait(set, 2, "rev-1"); wait != maxRecreateBackoff {
+		t.Errorf("got wait %v, want %v", wait, maxRecreateBackoff)
+	}
+
+	// ordinals which are scaled in are forgotten
+	set.Spec.Replicas = utilpointer.Int32Ptr(2)
+	if backoffs := backoff.status(set, "rev-1"); len(backoffs) != 0 {
+		t.Errorf("got status %v after scale in, want none", backoffs)
+	}
+}
+
+func TestRecreateBackoffDeletedStatefulSet(t *testing.T) {
+	set := newStatefulSet(3)
+	set.Spec.MaxRecreateAttempts = utilpointer.Int32Ptr(1)
+	ssc, _ := newFakeStatefulSetController()
+	backoff := ssc.control.(*defaultStatefulSetControl).recreateBackoff
+	backoff.recreated(set, 1, "rev-1")
+	if _, exceeded := backoff.wait(set, 1, "rev-1"); !exceeded {
+		t.Fatalf("max recreate attempts should be exceeded")
+	}
+
+	// a StatefulSet recreated with the same name and template does not inherit the attempts
+	recreated := set.DeepCopy()
+	recreated.UID = "recreated"
+	if _, exceeded := backoff.wait(recreated, 1, "rev-1"); exceeded {
+		t.Errorf("max recreate attempts should not be exceeded for a recreated StatefulSet")
+	}
+	if backoffs := backoff.status(recreated, "rev-1"); len(backoffs) != 0 {
+		t.Errorf("got status %v for a recreated StatefulSet, want none", backoffs)
+	}
+
+	// the attempts of a deleted StatefulSet are forgotten
+	backoff.recreated(recreated, 1, "rev-1")
+	if err := ssc.sync(statefulSetKey(recreated)); err != nil {
+		t.Fatal(err)
+	}
+	if len(backoff.attempts) != 0 {
+		t.Errorf("got attempts %v for a deleted StatefulSet, want none", backoff.attempts)
+	}
+}
+
+func TestRecreateBackoffRequeueAfter(t *testing.T) {
+	now := time.Now()
+	next := metav1.NewTime(now.Add(time.Minute))
+	soon := metav1.NewTime(now.Add(10 * time.Second))
+	past := metav1.NewTime(now.Add(-time.Minute))
+	status := &apps.StatefulSetStatus{
+		RecreateBackoffs: []apps.RecreateBackoff{
+			{Ordinal: 0, Attempts: 1, NextRecreateTime: &next},
+			{Ordinal: 1, Attempts: 2, NextRecreateTime: &soon},
+			{Ordinal: 2, Attempts: 3, NextRecreateTime: &past},
+			{Ordinal: 3, Attempts: 4},
+		},
+	}
+	if after := recreateBackoffRequeueAfter(status, now); after != 11*time.Second {
+		t.Errorf("got %v, want %v", after, 11*time.Second)
+	}
+	if after := recreateBackoffRequeueAfter(&apps.StatefulSetStatus{}, now); after != 0 {
+		t.Errorf("got %v, want 0", after)
+	}
+}
+
+func TestStatefulSetControlRecreateBackoff(t *testing.T) {
+	set := newStatefulSet(3)
+	set.Spec.MaxRecreateAttempts = utilpointer.Int32Ptr(2)
+	client := fake.NewSimpleClientset()
+	pcClient := pcfake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(pcClient, client)
+	defer close(stop)
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	ssc.(*defaultStatefulSetControl).recreateBackoff = newRecreateBackoff(fakeClock)
+	if err := scaleUpStatefulSetControl(set, ssc, spc, assertMonotonicInvariants); err != nil {
+		t.Fatal(err)
+	}
+	set, err := spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set = set.DeepCopy()
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	getPod := func() *v1.Pod {
+		pod, err := spc.podsLister.Pods(set.Namespace).Get(getPodName(set, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pod.DeepCopy()
+	}
+	failPod := func() {
+		pod := getPod()
+		pod.Status.Phase = v1.PodFailed
+		spc.podsIndexer.Update(pod)
+	}
+	update := func() *apps.StatefulSetStatus {
+		pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		status, err := ssc.UpdateStatefulSet(set, pods)
+		if err != nil {
+			t.Fatal(err)
+		}
+		set.Status = *status
+		return status
+	}
+
+	// the first recreation is immediate
+	failPod()
+	update()
+	if getPod().Status.Phase == v1.PodFailed {
+		t.Fatalf("failed pod should be recreated")
+	}
+
+	// the second one waits for the backoff
+	failPod()
+	status := update()
+	if getPod().Status.Phase != v1.PodFailed {
+		t.Fatalf("failed pod should not be recreated before the backoff expired")
+	}
+	if len(status.RecreateBackoffs) != 1 || status.RecreateBackoffs[0].Attempts != 1 || status.RecreateBackoffs[0].NextRecreateTime == nil {
+		t.Errorf("unexpected recreate backoffs %v", status.RecreateBackoffs)
+	}
+	fakeClock.Step(initialRecreateBackoff)
+	update()
+	if getPod().Status.Phase == v1.PodFailed {
+		t.Fatalf("failed pod should be recreated after the backoff expired")
+	}
+
+	// max recreate attempts exceeded
+	failPod()
+	fakeClock.Step(2 * initialRecreateBackoff)
+	status = update()
+	if getPod().Status.Phase != v1.PodFailed {
+		t.Fatalf("failed pod should not be recreated after max recreate attempts")
+	}
+	cond := getStatefulSetCondition(*status, apps.StatefulSetReplicaFailure)
+	if cond == nil || cond.Status != v1.ConditionTrue || cond.Reason != maxRecreateAttemptsExceededReason {
+		t.Errorf("ReplicaFailure condition should be set, got %v", cond)
+	}
+
+	// the attempts are reset once the pod is running and ready
+	pod := getPod()
+	pod.Status.Phase = v1.PodRunning
+	spc.podsIndexer.Update(pod)
+	if _, err := spc.setPodReady(set, 1); err != nil {
+		t.Fatal(err)
+	}
+	status = update()
+	if len(status.RecreateBackoffs) != 0 {
+		t.Errorf("got recreate backoffs %v, want none", status.RecreateBackoffs)
+	}
+	if cond := getStatefulSetCondition(*status, apps.StatefulSetReplicaFailure); cond != nil {
+		t.Errorf("ReplicaFailure condition should be removed, got %v", cond)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"errors"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+
+	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+)
+
+// volumeClaimExpansionRefusedReason is recorded as an event when the expansion of a PersistentVolumeClaim is refused
+// by the API server.
+const volumeClaimExpansionRefusedReason = "VolumeClaimExpansionRefused"
+
+// refusedClaimExpansions tracks the PersistentVolumeClaims of each StatefulSet whose expansion has been refused in
+// memory, so that they are skipped instead of blocking the rollout of the StatefulSet. A claim is expanded again once
+// the storage request of its volumeClaimTemplate changes. It is safe for concurrent use.
+type refusedClaimExpansions struct {
+	lock sync.Mutex
+	// requests maps the key of a StatefulSet to the refused storage requests of its claims by UID.
+	requests map[string]map[types.UID]resource.Quantity
+}
+
+func newRefusedClaimExpansions() *refusedClaimExpansions {
+	return &refusedClaimExpansions{requests: make(map[string]map[types.UID]resource.Quantity)}
+}
+
+// refuse records that the expansion of claim of set to request has been refused.
+func (r *refusedClaimExpansions) refuse(set *apps.StatefulSet, claim *v1.PersistentVolumeClaim, request resource.Quantity) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	key := statefulSetKey(set)
+	if r.requests[key] == nil {
+		r.requests[key] = make(map[types.UID]resource.Quantity)
+	}
+	r.requests[key][claim.UID] = request
+}
+
+// refused returns true if the expansion of claim of set to request has been refused.
+func (r *refusedClaimExpansions) refused(set *apps.StatefulSet, claim *v1.PersistentVolumeClaim, request resource.Quantity) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	refused, ok := r.requests[statefulSetKey(set)][claim.UID]
+	return ok && refused.Cmp(request) == 0
+}
+
+// forget forgets the refused expansions of the StatefulSet with key, it is called when the StatefulSet is deleted.
+func (r *refusedClaimExpansions) forget(key string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.requests, key)
+}
+
+// refusedVolumeClaimExpansions splits err, returned by UpdatePersistentVolumeClaims, into the refused expansions and
+// the other errors.
+func refusedVolumeClaimExpansions(err error) ([]*volumeClaimExpansionRefusedError, error) {
+	errs := []error{err}
+	if agg, ok := err.(errorutils.Aggregate); ok {
+		errs = errorutils.Flatten(agg).Errors()
+	}
+	var refused []*volumeClaimExpansionRefusedError
+	var others []error
+	for _, err := range errs {
+		var refusedErr *volumeClaimExpansionRefusedError
+		if errors.As(err, &refusedErr) {
+			refused = append(refused, refusedErr)
+		} else {
+			others = append(others, err)
+		}
+	}
+	return refused, errorutils.NewAggregate(others)
+}
@@ -22,13 +22,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
-	"math"
-	"sort"
 	"strconv"
+	"time"
 
 	kubeapps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -38,9 +38,11 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+	"github.com/pingcap/advanced-statefulset/pkg/features"
 	k8s "github.com/pingcap/advanced-statefulset/pkg/third_party/k8s"
 )
 
@@ -52,13 +54,17 @@ type StatefulSetControlInterface interface {
 	// If an implementation returns a non-nil error, the invocation will be retried using a rate-limited strategy.
 	// Implementors should sink any errors that they do not wish to trigger a retry, and they may feel free to
 	// exit exceptionally at any point provided they wish the update to be re-run at a later point in time.
-	UpdateStatefulSet(set *apps.StatefulSet, pods []*v1.Pod) error
+	// If the returned error is nil, the returned status is the status recorded for set.
+	UpdateStatefulSet(set *apps.StatefulSet, pods []*v1.Pod) (*apps.StatefulSetStatus, error)
 	// ListRevisions returns a array of the ControllerRevisions that represent the revisions of set. If the returned
 	// error is nil, the returns slice of ControllerRevisions is valid.
 	ListRevisions(set *apps.StatefulSet) ([]*kubeapps.ControllerRevision, error)
 	// AdoptOrphanRevisions adopts any orphaned ControllerRevisions that match set's Selector. If all adoptions are
 	// successful the returned error is nil.
 	AdoptOrphanRevisions(set *apps.StatefulSet, revisions []*kubeapps.ControllerRevision) error
+	// ForgetStatefulSet forgets the state kept in memory for the StatefulSet with key, it is called when the
+	// StatefulSet is deleted.
+	ForgetStatefulSet(key string)
 }
 
 // NewDefaultStatefulSetControl returns a new instance of the default implementation StatefulSetControlInterface that
@@ -71,14 +77,18 @@ func NewDefaultStatefulSetControl(
 	statusUpdater StatefulSetStatusUpdaterInterface,
 	csAppsV1 appsv1.AppsV1Interface,
 	recorder record.EventRecorder) StatefulSetControlInterface {
-	return &defaultStatefulSetControl{podControl, statusUpdater, csAppsV1, recorder}
+	return &defaultStatefulSetControl{podControl, statusUpdater, csAppsV1, recorder, newRecreateBackoff(clock.RealClock{}),
+		newRefusedClaimExpansions()}
 }
 
 type defaultStatefulSetControl struct {
-	podControl    StatefulPodControlInterface
-	statusUpdater StatefulSetStatusUpdaterInterface
-	csAppsV1      appsv1.AppsV1Interface
-	recorder      record.EventRecorder
+	podControl      StatefulPodControlInterface
+	statusUpdater   StatefulSetStatusUpdaterInterface
+	csAppsV1        appsv1.AppsV1Interface
+	recorder        record.EventRecorder
+	recreateBackoff *recreateBackoff
+	// refusedClaimExpansions are the PersistentVolumeClaims whose expansion has been refused.
+	refusedClaimExpansions *refusedClaimExpansions
 }
 
 // UpdateStatefulSet executes the core logic loop for a stateful set, applying the predictable and
@@ -87,31 +97,34 @@ type defaultStatefulSetControl struct {
 // strategy allows these constraints to be relaxed - pods will be created and deleted eagerly and
 // in no particular order. Clients using the burst strategy should be careful to ensure they
 // understand the consistency implications of having unpredictable numbers of pods available.
-func (ssc *defaultStatefulSetControl) UpdateStatefulSet(set *apps.StatefulSet, pods []*v1.Pod) error {
+func (ssc *defaultStatefulSetControl) UpdateStatefulSet(set *apps.StatefulSet, pods []*v1.Pod) (*apps.StatefulSetStatus, error) {
 
 	// list all revisions and sort them
 	revisions, err := ssc.ListRevisions(set)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	k8s.SortControllerRevisions(revisions)
 
 	// get the current, and update revisions
 	currentRevision, updateRevision, collisionCount, err := ssc.getStatefulSetRevisions(set, revisions)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// perform the main update function and get the status
 	status, err := ssc.updateStatefulSet(set, currentRevision, updateRevision, collisionCount, pods)
-	if err != nil {
-		return err
+	if isPreconditionFailed(err) && status != nil {
+		// a Pod or claim changed since it was cached, the set is synced again when the cache catches up
+		klog.V(4).Infof("StatefulSet %s/%s is waiting for its cache to be synced: %v", set.Namespace, set.Name, err)
+	} else if err != nil {
+		return nil, err
 	}
 
 	// update the set's status
 	err = ssc.updateStatefulSetStatus(set, status)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	klog.V(4).Infof("StatefulSet %s/%s pod status replicas=%d ready=%d current=%d updated=%d",
@@ -129,7 +142,15 @@ func (ssc *defaultStatefulSetControl) UpdateStatefulSet(set *apps.StatefulSet, p
 		status.UpdateRevision)
 
 	// maintain the set's revision history limit
-	return ssc.truncateHistory(set, pods, revisions, currentRevision, updateRevision)
+	if err := ssc.truncateHistory(set, pods, revisions, currentRevision, updateRevision); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (ssc *defaultStatefulSetControl) ForgetStatefulSet(key string) {
+	ssc.recreateBackoff.forget(key)
+	ssc.refusedClaimExpansions.forget(key)
 }
 
 func (ssc *defaultStatefulSetControl) ListRevisions(set *apps.StatefulSet) ([]*kubeapps.ControllerRevision, error) {
@@ -284,8 +305,9 @@ func (ssc *defaultStatefulSetControl) getStatefulSetRevisions(
 // If the UpdateStrategy.Type for the set is OnDeleteStatefulSetStrategyType, the target state implies nothing about
 // the revisions of Pods in the set. If the UpdateStrategy.Type for the set is PartitionStatefulSetStrategyType, then
 // all Pods with ordinal less than UpdateStrategy.Partition.Ordinal must be at Status.CurrentRevision and all other
-// Pods must be at Status.UpdateRevision. If the returned error is nil, the returned StatefulSetStatus is valid and the
-// update must be recorded. If the error is not nil, the method should be retried until successful.
+// Pods must be at Status.UpdateRevision. The Pods are changed by executing the Plan of the set. If the returned error
+// is nil, the returned StatefulSetStatus is valid and the update must be recorded. If the error is not nil, the
+// method should be retried until successful.
 func (ssc *defaultStatefulSetControl) updateStatefulSet(
 	set *apps.StatefulSet,
 	currentRevision *kubeapps.ControllerRevision,
@@ -301,6 +323,11 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 	if err != nil {
 		return nil, err
 	}
+	if err := validateOrdinalOverrides(updateSet); err != nil {
+		ssc.recorder.Eventf(set, v1.EventTypeWarning, "InvalidOrdinalOverrides", "StatefulSet %s/%s: %v",
+			set.Namespace, set.Name, err)
+		return nil, err
+	}
 
 	// set the generation, and revisions in the returned status
 	status := apps.StatefulSetStatus{}
@@ -309,27 +336,41 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 	status.UpdateRevision = updateRevision.Name
 	status.CollisionCount = new(int32)
 	*status.CollisionCount = collisionCount
+	// conditions are carried over and only changed by the controller when they transition
+	status.Conditions = append([]apps.StatefulSetCondition(nil), set.Status.Conditions...)
+	// the DisruptionBlocked condition is set again if an eviction is still refused
+	status.Conditions = filterOutCondition(status.Conditions, apps.StatefulSetDisruptionBlocked)
+	// the surge Pods of a rolling update with maxSurge are tracked in status and are not replicas
+	if getMaxSurge(set) > 0 {
+		status.Surges = append([]apps.StatefulSetSurge(nil), set.Status.Surges...)
+	}
 
 	// desired replica slots: [0, replicaCount) - [delete slots]
-	deleteSlots := helper.GetDeleteSlots(set)
-	_replicaCount, deleteSlots := helper.GetMaxReplicaCountAndDeleteSlots(*set.Spec.Replicas, deleteSlots)
+	_replicaCount, deleteSlots := helper.GetMaxReplicaCountAndDeleteSlots(*set.Spec.Replicas, helper.GetDeleteSlots(set))
 	replicaCount := int(_replicaCount)
-
-	// slice that will contain all Pods such that 0 <= getOrdinal(pod) < set.Spec.Replicas and not in deleteSlots
-	replicas := make([]*v1.Pod, replicaCount)
-	// slice that will contain all Pods such that set.Spec.Replicas <= getOrdinal(pod) or exist in deleteSlots
-	condemned := make([]*v1.Pod, 0, len(pods))
-	unhealthy := 0
-	firstUnhealthyOrdinal := math.MaxInt32
-	var firstUnhealthyPod *v1.Pod
-
-	// First we partition pods into two lists valid replicas and condemned Pods
+	status.Ordinals = helper.GetPodOrdinalsFromReplicasAndDeleteSlots(*set.Spec.Replicas, deleteSlots).List()
+	if deleteSlots.Len() > 0 {
+		status.DeleteSlots = deleteSlots.List()
+	}
+	if ignored := helper.GetIgnoredDeleteSlots(*set.Spec.Replicas, helper.GetDeleteSlots(set)); ignored.Len() > 0 {
+		status.IgnoredDeleteSlots = ignored.List()
+	}
+	// the claims of the replicas keyed by Pod name
+	claims := make(map[string]map[string]*v1.PersistentVolumeClaim)
 	for i := range pods {
+		ord := getOrdinal(pods[i])
+		if isSurgeOrdinal(status.Surges, ord) {
+			continue
+		}
+
 		status.Replicas++
 
 		// count the number of running and ready replicas
 		if isRunningAndReady(pods[i]) {
 			status.ReadyReplicas++
+			if ord >= 0 {
+				ssc.recreateBackoff.reset(set, ord)
+			}
 		}
 
 		// count the number of current and update replicas
@@ -342,255 +383,229 @@ func (ssc *defaultStatefulSetControl) updateStatefulSet(
 			}
 		}
 
-		if ord := getOrdinal(pods[i]); 0 <= ord && ord < replicaCount && !deleteSlots.Has(int32(ord)) {
-			// if the ordinal of the pod is within the range of the current number of replicas,
-			// insert it at the indirection of its ordinal
-			replicas[ord] = pods[i]
-
-		} else if ord >= replicaCount || deleteSlots.Has(int32(ord)) {
-			// if the ordinal is greater than the number of replicas add it to the condemned list
-			condemned = append(condemned, pods[i])
-		}
-		// If the ordinal could not be parsed (ord < 0), ignore the Pod.
-	}
-
-	// for any empty indices in the sequence [0,set.Spec.Replicas) and do not exist in deleteSlots create a new Pod at the correct revision
-	for ord := 0; ord < replicaCount; ord++ {
-		if deleteSlots.Has(int32(ord)) {
-			continue
-		}
-		if replicas[ord] == nil {
-			replicas[ord] = newVersionedStatefulSetPod(
-				currentSet,
-				updateSet,
-				currentRevision.Name,
-				updateRevision.Name, ord)
-		}
-	}
-
-	// sort the condemned Pods by their ordinals
-	sort.Sort(ascendingOrdinal(condemned))
-
-	// find the first unhealthy Pod
-	for i := range replicas {
-		if replicas[i] == nil {
-			continue
-		}
-		if !isHealthy(replicas[i]) {
-			unhealthy++
-			if ord := getOrdinal(replicas[i]); ord < firstUnhealthyOrdinal {
-				firstUnhealthyOrdinal = ord
-				firstUnhealthyPod = replicas[i]
+		// count the number of replicas whose claims provide the storage requested by the templates
+		if 0 <= ord && ord < replicaCount && !deleteSlots.Has(int32(ord)) && isCreated(pods[i]) && !isTerminating(pods[i]) {
+			podClaims, err := ssc.podControl.ListPersistentVolumeClaims(set, pods[i])
+			if err != nil {
+				return &status, err
 			}
-		}
-	}
-
-	for i := range condemned {
-		if !isHealthy(condemned[i]) {
-			unhealthy++
-			if ord := getOrdinal(condemned[i]); ord < firstUnhealthyOrdinal {
-				firstUnhealthyOrdinal = ord
-				firstUnhealthyPod = condemned[i]
+			claims[pods[i].Name] = podClaims
+			if volumeClaimsUpdated(set, pods[i], podClaims) {
+				status.UpdatedVolumeClaimReplicas++
 			}
 		}
 	}
-
-	if unhealthy > 0 {
-		klog.V(4).Infof("StatefulSet %s/%s has %d unhealthy Pods starting with %s",
-			set.Namespace,
-			set.Name,
-			unhealthy,
-			firstUnhealthyPod.Name)
+	if frozen := getFrozenOrdinals(set, pods, status.Surges); frozen.Len() > 0 {
+		status.FrozenOrdinals = frozen.List()
 	}
+	status.Pods = getPodSummaries(pods)
 
-	// If the StatefulSet is being deleted, don't do anything other than updating
-	// status.
-	if set.DeletionTimestamp != nil {
-		return &status, nil
+	plan := planStatefulSet(&PlanInput{
+		Set:                set,
+		Pods:               pods,
+		CurrentRevision:    currentRevision,
+		UpdateRevision:     updateRevision,
+		VolumeClaims:       claims,
+		VolumeClaimUpdates: features.DefaultFeatureGate.Enabled(features.VolumeClaimUpdates),
+		RecreateBackoff: func(ordinal int) (time.Duration, bool) {
+			return ssc.recreateBackoff.wait(set, ordinal, updateRevision.Name)
+		},
+		VolumeClaimExpansionRefused: func(claim *v1.PersistentVolumeClaim, request resource.Quantity) bool {
+			return ssc.refusedClaimExpansions.refused(set, claim, request)
+		},
+	}, currentSet, updateSet)
+	if len(plan.Actions) > 0 {
+		klog.V(4).Infof("StatefulSet %s/%s plan: %v", set.Namespace, set.Name, plan.Actions)
 	}
+	if done, err := ssc.executePlan(set, updateSet, &status, plan, currentRevision.Name, updateRevision.Name); err != nil || !done {
+		return &status, err
+	}
+	status.Surges = plan.Surges
+	if blocked := plan.Blocked(); blocked != nil {
+		status.BlockedBy = newBlockedBy(blocked.Pod, string(blocked.Reason))
+	}
+	return &status, nil
+}
 
-	monotonic := !allowsBurst(set)
-
-	// Examine each replica with respect to its ordinal
-	for i := range replicas {
-		if replicas[i] == nil {
-			continue
-		}
-		// Delete and recreate pods which finished running.
-		//
-		// Note that pods with phase Succeeded will also trigger this event. This is
-		// because final pod phase of evicted or otherwise forcibly stopped pods
-		// (e.g. terminated on node reboot) is determined by the exit code of the
-		// container, not by the reason for pod termination. We should restart the pod
-		// regardless of the exit code.
-		if isFailed(replicas[i]) || isSucceeded(replicas[i]) {
-			if isFailed(replicas[i]) {
-				ssc.recorder.Eventf(set, v1.EventTypeWarning, "RecreatingFailedPod",
-					"StatefulSet %s/%s is recreating failed Pod %s",
-					set.Namespace,
-					set.Name,
-					replicas[i].Name)
-			} else {
-				ssc.recorder.Eventf(set, v1.EventTypeNormal, "RecreatingTerminatedPod",
-					"StatefulSet %s/%s is recreating terminated Pod %s",
+// executePlan executes the Actions of plan in order, and accounts for the created and deleted replicas in status. It
+// stops at the first Action which fails, and at the first refused eviction of a Pod unless it is replaced by a surge
+// Pod. The returned bool is true if all Actions have been executed.
+func (ssc *defaultStatefulSetControl) executePlan(
+	set *apps.StatefulSet,
+	updateSet *apps.StatefulSet,
+	status *apps.StatefulSetStatus,
+	plan *Plan,
+	currentRevision string,
+	updateRevision string) (bool, error) {
+	for _, action := range plan.Actions {
+		pod := action.Pod
+		switch action.Type {
+		case ActionWait:
+			klog.V(4).Infof("StatefulSet %s/%s is waiting for Pod %s: %s",
+				set.Namespace,
+				set.Name,
+				pod.Name,
+				action.Reason)
+		case ActionCreatePod:
+			if action.Reason == ReasonSurge {
+				klog.V(2).Infof("StatefulSet %s/%s creating surge Pod %s",
 					set.Namespace,
 					set.Name,
-					replicas[i].Name)
+					pod.Name)
 			}
-			if err := ssc.podControl.DeleteStatefulPod(set, replicas[i]); err != nil {
-				return &status, err
+			if err := ssc.podControl.CreateStatefulPod(set, pod); err != nil {
+				return false, err
 			}
-			if getPodRevision(replicas[i]) == currentRevision.Name {
-				status.CurrentReplicas--
+			// surge Pods are not replicas
+			if action.Reason != ReasonSurge {
+				status.Replicas++
+				countReplicaRevision(status, pod, currentRevision, updateRevision, 1)
 			}
-			if getPodRevision(replicas[i]) == updateRevision.Name {
-				status.UpdatedReplicas--
-			}
-			status.Replicas--
-			replicas[i] = newVersionedStatefulSetPod(
-				currentSet,
-				updateSet,
-				currentRevision.Name,
-				updateRevision.Name,
-				i)
-		}
-		// If we find a Pod that has not been created we create the Pod
-		if !isCreated(replicas[i]) {
-			if err := ssc.podControl.CreateStatefulPod(set, replicas[i]); err != nil {
-				return &status, err
+		case ActionUpdatePod:
+			// Make a deep copy so we don't mutate the shared cache
+			if err := ssc.podControl.UpdateStatefulPod(updateSet, pod.DeepCopy()); err != nil {
+				return false, err
 			}
-			status.Replicas++
-			if getPodRevision(replicas[i]) == currentRevision.Name {
-				status.CurrentReplicas++
-			}
-			if getPodRevision(replicas[i]) == updateRevision.Name {
-				status.UpdatedReplicas++
-			}
-
-			// if the set does not allow bursting, return immediately
-			if monotonic {
-				return &status, nil
+		case ActionUpdatePodRevision:
+			klog.V(2).Infof("StatefulSet %s/%s updating revision of Pod %s",
+				set.Namespace,
+				set.Name,
+				pod.Name)
+			if err := ssc.podControl.UpdateStatefulPodRevision(set, pod, updateRevision); err != nil {
+				return false, err
 			}
-			// pod created, no more work possible for this round
-			continue
-		}
-		// If we find a Pod that is currently terminating, we must wait until graceful deletion
-		// completes before we continue to make progress.
-		if isTerminating(replicas[i]) && monotonic {
-			klog.V(4).Infof(
-				"StatefulSet %s/%s is waiting for Pod %s to Terminate",
+			status.CurrentReplicas--
+			status.UpdatedReplicas++
+		case ActionExpandVolumeClaims:
+			klog.V(2).Infof("StatefulSet %s/%s expanding PersistentVolumeClaims of Pod %s",
 				set.Namespace,
 				set.Name,
-				replicas[i].Name)
-			return &status, nil
-		}
-		// If we have a Pod that has been created but is not running and ready we can not make progress.
-		// We must ensure that all for each Pod, when we create it, all of its predecessors, with respect to its
-		// ordinal, are Running and Ready.
-		if !isRunningAndReady(replicas[i]) && monotonic {
-			klog.V(4).Infof(
-				"StatefulSet %s/%s is waiting for Pod %s to be Running and Ready",
+				pod.Name)
+			if err := ssc.podControl.UpdatePersistentVolumeClaims(set, pod); err != nil {
+				// a refused expansion is not retried, so that it does not block the rollout
+				refused, err := refusedVolumeClaimExpansions(err)
+				for _, r := range refused {
+					ssc.refusedClaimExpansions.refuse(set, r.claim, r.request)
+					ssc.recorder.Eventf(set, v1.EventTypeWarning, volumeClaimExpansionRefusedReason,
+						"PersistentVolumeClaim %s of Pod %s is not expanded until its volumeClaimTemplate changes: %v",
+						r.claim.Name, pod.Name, r.err)
+				}
+				if err != nil {
+					return false, err
+				}
+			}
+		case ActionDeleteVolumeClaims:
+			klog.V(2).Infof("StatefulSet %s/%s deleting PersistentVolumeClaims of Pod %s (%s)",
 				set.Namespace,
 				set.Name,
-				replicas[i].Name)
-			return &status, nil
-		}
-		// Enforce the StatefulSet invariants
-		if identityMatches(set, replicas[i]) && storageMatches(set, replicas[i]) {
-			continue
-		}
-		// Make a deep copy so we don't mutate the shared cache
-		replica := replicas[i].DeepCopy()
-		if err := ssc.podControl.UpdateStatefulPod(updateSet, replica); err != nil {
-			return &status, err
+				pod.Name,
+				action.Reason)
+			if err := ssc.podControl.DeletePersistentVolumeClaims(set, pod); err != nil {
+				return false, err
+			}
+		case ActionDeletePod:
+			if next, err := ssc.executeDeletePod(set, status, action, currentRevision, updateRevision); err != nil || !next {
+				return false, err
+			}
 		}
 	}
+	return true, nil
+}
 
-	// At this point, all of the current Replicas are Running and Ready, we can consider termination.
-	// We will wait for all predecessors to be Running and Ready prior to attempting a deletion.
-	// We will terminate Pods in a monotonically decreasing order over [len(pods),set.Spec.Replicas).
-	// Note that we do not resurrect Pods in this interval. Also note that scaling will take precedence over
-	// updates.
-	for target := len(condemned) - 1; target >= 0; target-- {
-		// wait for terminating pods to expire
-		if isTerminating(condemned[target]) {
-			klog.V(4).Infof(
-				"StatefulSet %s/%s is waiting for Pod %s to Terminate prior to scale down",
+// executeDeletePod executes an ActionDeletePod and accounts for the deleted replica in status. The returned bool is
+// true if the next Actions of the Plan can be executed.
+func (ssc *defaultStatefulSetControl) executeDeletePod(
+	set *apps.StatefulSet,
+	status *apps.StatefulSetStatus,
+	action Action,
+	currentRevision string,
+	updateRevision string) (bool, error) {
+	pod := action.Pod
+	switch action.Reason {
+	case ReasonFailed, ReasonSucceeded, ReasonRollbackBlocked:
+		if isFailed(pod) {
+			ssc.recorder.Eventf(set, v1.EventTypeWarning, "RecreatingFailedPod",
+				"StatefulSet %s/%s is recreating failed Pod %s",
 				set.Namespace,
 				set.Name,
-				condemned[target].Name)
-			// block if we are in monotonic mode
-			if monotonic {
-				return &status, nil
-			}
-			continue
-		}
-		// if we are in monotonic mode and the condemned target is not the first unhealthy Pod block
-		if !isRunningAndReady(condemned[target]) && monotonic && condemned[target] != firstUnhealthyPod {
-			klog.V(4).Infof(
-				"StatefulSet %s/%s is waiting for Pod %s to be Running and Ready prior to scale down",
+				pod.Name)
+		} else if action.Reason == ReasonRollbackBlocked {
+			ssc.recorder.Eventf(set, v1.EventTypeNormal, "RecreatingUnreadyPod",
+				"StatefulSet %s/%s is recreating unready Pod %s for rollback",
+				set.Namespace,
+				set.Name,
+				pod.Name)
+		} else {
+			ssc.recorder.Eventf(set, v1.EventTypeNormal, "RecreatingTerminatedPod",
+				"StatefulSet %s/%s is recreating terminated Pod %s",
 				set.Namespace,
 				set.Name,
-				firstUnhealthyPod.Name)
-			return &status, nil
+				pod.Name)
 		}
+		if err := ssc.podControl.DeleteStatefulPod(set, pod); err != nil {
+			return false, err
+		}
+		if action.Reason != ReasonRollbackBlocked {
+			ssc.recreateBackoff.recreated(set, action.Ordinal, updateRevision)
+		}
+		status.Replicas--
+		countReplicaRevision(status, pod, currentRevision, updateRevision, -1)
+		return true, nil
+	case ReasonSurgeRetired:
+		// surge Pods are in excess of the replicas, so they are deleted directly
+		klog.V(2).Infof("StatefulSet %s/%s terminating surge Pod %s",
+			set.Namespace,
+			set.Name,
+			pod.Name)
+		return true, ssc.podControl.DeleteStatefulPod(set, pod)
+	case ReasonScaleIn:
 		klog.V(2).Infof("StatefulSet %s/%s terminating Pod %s for scale down",
 			set.Namespace,
 			set.Name,
-			condemned[target].Name)
-
-		if err := ssc.podControl.DeleteStatefulPod(set, condemned[target]); err != nil {
-			return &status, err
-		}
-		if getPodRevision(condemned[target]) == currentRevision.Name {
-			status.CurrentReplicas--
-		}
-		if getPodRevision(condemned[target]) == updateRevision.Name {
-			status.UpdatedReplicas--
-		}
-		if monotonic {
-			return &status, nil
-		}
+			pod.Name)
+	case ReasonUpdate:
+		klog.V(2).Infof("StatefulSet %s/%s terminating Pod %s for update",
+			set.Namespace,
+			set.Name,
+			pod.Name)
+	case ReasonSurgeReplaced:
+		klog.V(2).Infof("StatefulSet %s/%s terminating Pod %s for update, it is replaced by a surge Pod",
+			set.Namespace,
+			set.Name,
+			pod.Name)
+	case ReasonFileSystemResize:
+		klog.V(2).Infof("StatefulSet %s/%s restarting Pod %s to resize the file system of its volumes",
+			set.Namespace,
+			set.Name,
+			pod.Name)
 	}
-
-	// for the OnDelete strategy we short circuit. Pods will be updated when they are manually deleted.
-	if set.Spec.UpdateStrategy.Type == apps.OnDeleteStatefulSetStrategyType {
-		return &status, nil
+	deleted, err := ssc.disruptStatefulPod(set, status, pod)
+	if err != nil {
+		return false, err
 	}
-
-	// we compute the minimum ordinal of the target sequence for a destructive update based on the strategy.
-	updateMin := 0
-	if set.Spec.UpdateStrategy.RollingUpdate != nil {
-		updateMin = int(*set.Spec.UpdateStrategy.RollingUpdate.Partition)
+	if !deleted {
+		// the eviction is refused until the PodDisruptionBudget allows it
+		status.BlockedBy = newBlockedBy(pod, evictionBlockedReason)
 	}
-	// we terminate the Pod with the largest ordinal that does not match the update revision.
-	for target := len(replicas) - 1; target >= updateMin; target-- {
-		if replicas[target] == nil {
-			continue
-		}
-		// delete the Pod if it is not already terminating and does not match the update revision.
-		if getPodRevision(replicas[target]) != updateRevision.Name && !isTerminating(replicas[target]) {
-			klog.V(2).Infof("StatefulSet %s/%s terminating Pod %s for update",
-				set.Namespace,
-				set.Name,
-				replicas[target].Name)
-			err := ssc.podControl.DeleteStatefulPod(set, replicas[target])
+	if deleted {
+		if action.Reason == ReasonUpdate || action.Reason == ReasonSurgeReplaced {
 			status.CurrentReplicas--
-			return &status, err
-		}
-
-		// wait for unhealthy Pods on update
-		if !isHealthy(replicas[target]) {
-			klog.V(4).Infof(
-				"StatefulSet %s/%s is waiting for Pod %s to update",
-				set.Namespace,
-				set.Name,
-				replicas[target].Name)
-			return &status, nil
+		} else {
+			countReplicaRevision(status, pod, currentRevision, updateRevision, -1)
 		}
+	}
+	// a Pod replaced by a surge Pod does not block the other surges
+	return deleted || action.Reason == ReasonSurgeReplaced, nil
+}
 
+// countReplicaRevision adds delta to the current and updated replicas of status according to the revision of pod.
+func countReplicaRevision(status *apps.StatefulSetStatus, pod *v1.Pod, currentRevision, updateRevision string, delta int32) {
+	if getPodRevision(pod) == currentRevision {
+		status.CurrentReplicas += delta
+	}
+	if getPodRevision(pod) == updateRevision {
+		status.UpdatedReplicas += delta
 	}
-	return &status, nil
 }
 
 // updateStatefulSetStatus updates set's Status to be equal to status. If status indicates a complete update, it is
@@ -602,6 +617,22 @@ func (ssc *defaultStatefulSetControl) updateStatefulSetStatus(
 
 	// complete any in progress rolling update if necessary
 	completeRollingUpdate(set, status)
+	completeRollback(status)
+	status.RecreateBackoffs = ssc.recreateBackoff.status(set, status.UpdateRevision)
+	if updateReplicaFailureCondition(set, status) {
+		ssc.recorder.Eventf(set, v1.EventTypeWarning, maxRecreateAttemptsExceededReason,
+			"StatefulSet %s/%s stopped recreating Pods after %d attempts",
+			set.Namespace,
+			set.Name,
+			*set.Spec.MaxRecreateAttempts)
+	}
+	if updateProgressingCondition(set, status, time.Now()) {
+		ssc.recorder.Eventf(set, v1.EventTypeWarning, progressDeadlineExceededReason,
+			"StatefulSet %s/%s has not made progress for %d seconds",
+			set.Namespace,
+			set.Name,
+			*set.Spec.ProgressDeadlineSeconds)
+	}
 
 	// if the status is not inconsistent do not perform an update
 	if !inconsistentStatus(set, status) {
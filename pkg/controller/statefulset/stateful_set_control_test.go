@@ -31,8 +31,13 @@ import (
 	kubeapps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	kuberuntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
@@ -40,13 +45,18 @@ import (
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	featuregatetesting "k8s.io/component-base/featuregate/testing"
+	"k8s.io/utils/clock"
+	utilpointer "k8s.io/utils/pointer"
 
 	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	clientset "github.com/pingcap/advanced-statefulset/client/client/clientset/versioned"
 	pcfake "github.com/pingcap/advanced-statefulset/client/client/clientset/versioned/fake"
 	pcinformers "github.com/pingcap/advanced-statefulset/client/client/informers/externalversions"
 	appsinformers "github.com/pingcap/advanced-statefulset/client/client/informers/externalversions/apps/v1"
 	appslisters "github.com/pingcap/advanced-statefulset/client/client/listers/apps/v1"
+	"github.com/pingcap/advanced-statefulset/pkg/features"
 	k8s "github.com/pingcap/advanced-statefulset/pkg/third_party/k8s"
 )
 
@@ -231,7 +241,7 @@ func ReplacesPods(t *testing.T, set *apps.StatefulSet, invariants invariantFunc)
 		if err != nil {
 			t.Error(err)
 		}
-		if err = ssc.UpdateStatefulSet(set, pods); err != nil {
+		if _, err = ssc.UpdateStatefulSet(set, pods); err != nil {
 			t.Errorf("Failed to update StatefulSet : %s", err)
 		}
 		set, err = spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
@@ -241,7 +251,7 @@ func ReplacesPods(t *testing.T, set *apps.StatefulSet, invariants invariantFunc)
 		if pods, err = spc.setPodRunning(set, i); err != nil {
 			t.Error(err)
 		}
-		if err = ssc.UpdateStatefulSet(set, pods); err != nil {
+		if _, err = ssc.UpdateStatefulSet(set, pods); err != nil {
 			t.Errorf("Failed to update StatefulSet : %s", err)
 		}
 		set, err = spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
@@ -256,7 +266,7 @@ func ReplacesPods(t *testing.T, set *apps.StatefulSet, invariants invariantFunc)
 	if err != nil {
 		t.Error(err)
 	}
-	if err := ssc.UpdateStatefulSet(set, pods); err != nil {
+	if _, err := ssc.UpdateStatefulSet(set, pods); err != nil {
 		t.Errorf("Failed to update StatefulSet : %s", err)
 	}
 	set, err = spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
@@ -281,7 +291,7 @@ func recreatesPod(t *testing.T, set *apps.StatefulSet, invariants invariantFunc,
 	if err != nil {
 		t.Error(err)
 	}
-	if err := ssc.UpdateStatefulSet(set, pods); err != nil {
+	if _, err := ssc.UpdateStatefulSet(set, pods); err != nil {
 		t.Errorf("Error updating StatefulSet %s", err)
 	}
 	if err := invariants(set, spc); err != nil {
@@ -293,7 +303,7 @@ func recreatesPod(t *testing.T, set *apps.StatefulSet, invariants invariantFunc,
 	}
 	pods[0].Status.Phase = phase
 	spc.podsIndexer.Update(pods[0])
-	if err := ssc.UpdateStatefulSet(set, pods); err != nil {
+	if _, err := ssc.UpdateStatefulSet(set, pods); err != nil {
 		t.Errorf("Error updating StatefulSet %s", err)
 	}
 	if err := invariants(set, spc); err != nil {
@@ -384,7 +394,7 @@ func UpdatePodFailure(t *testing.T, set *apps.StatefulSet, invariants invariantF
 	spc.podsIndexer.Update(pods[0])
 
 	// now it should fail
-	if err := ssc.UpdateStatefulSet(set, pods); !apierrors.IsInternalError(err) {
+	if _, err := ssc.UpdateStatefulSet(set, pods); !apierrors.IsInternalError(err) {
 		t.Errorf("StatefulSetControl did not return InternalError found %s", err)
 	}
 }
@@ -432,7 +442,7 @@ func PodRecreateDeleteFailure(t *testing.T, set *apps.StatefulSet, invariants in
 	if err != nil {
 		t.Error(err)
 	}
-	if err := ssc.UpdateStatefulSet(set, pods); err != nil {
+	if _, err := ssc.UpdateStatefulSet(set, pods); err != nil {
 		t.Errorf("Error updating StatefulSet %s", err)
 	}
 	if err := invariants(set, spc); err != nil {
@@ -445,13 +455,13 @@ func PodRecreateDeleteFailure(t *testing.T, set *apps.StatefulSet, invariants in
 	pods[0].Status.Phase = v1.PodFailed
 	spc.podsIndexer.Update(pods[0])
 	spc.SetDeleteStatefulPodError(apierrors.NewInternalError(errors.New("API server failed")), 0)
-	if err := ssc.UpdateStatefulSet(set, pods); !apierrors.IsInternalError(err) {
+	if _, err := ssc.UpdateStatefulSet(set, pods); !apierrors.IsInternalError(err) {
 		t.Errorf("StatefulSet failed to %s", err)
 	}
 	if err := invariants(set, spc); err != nil {
 		t.Error(err)
 	}
-	if err := ssc.UpdateStatefulSet(set, pods); err != nil {
+	if _, err := ssc.UpdateStatefulSet(set, pods); err != nil {
 		t.Errorf("Error updating StatefulSet %s", err)
 	}
 	if err := invariants(set, spc); err != nil {
@@ -525,7 +535,7 @@ func TestStatefulSetControl_getSetRevisions(t *testing.T) {
 		ssu := newFakeStatefulSetStatusUpdater(informerFactory.Apps().V1().StatefulSets())
 		_ = kubeInformerFactory.Apps().V1().ControllerRevisions().Lister() // add informer to the factory
 		recorder := record.NewFakeRecorder(10)
-		ssc := defaultStatefulSetControl{spc, ssu, kubeClient.AppsV1(), recorder}
+		ssc := defaultStatefulSetControl{spc, ssu, kubeClient.AppsV1(), recorder, newRecreateBackoff(clock.RealClock{}), newRefusedClaimExpansions()}
 
 		stop := make(chan struct{})
 		defer close(stop)
@@ -1270,7 +1280,7 @@ func TestStatefulSetControlLimitsHistory(t *testing.T) {
 			if err != nil {
 				t.Fatalf("%s: %s", test.name, err)
 			}
-			err = ssc.UpdateStatefulSet(set, pods)
+			_, err = ssc.UpdateStatefulSet(set, pods)
 			if err != nil {
 				t.Fatalf("%s: %s", test.name, err)
 			}
@@ -1562,6 +1572,852 @@ func TestStatefulSetControlRollback(t *testing.T) {
 	}
 }
 
+func TestStatefulSetControlOrdinalOverrides(t *testing.T) {
+	set := newStatefulSet(3)
+	client := fake.NewSimpleClientset()
+	pcClient := pcfake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(pcClient, client)
+	defer close(stop)
+	if err := scaleUpStatefulSetControl(set, ssc, spc, assertMonotonicInvariants); err != nil {
+		t.Fatal(err)
+	}
+	set, err := spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// mark the existing pods to find out which pods are recreated
+	pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pod := range pods {
+		pod = pod.DeepCopy()
+		pod.Annotations = map[string]string{"original": "true"}
+		spc.podsIndexer.Update(pod)
+	}
+
+	set = set.DeepCopy()
+	set.Spec.OrdinalOverrides = []apps.OrdinalOverride{{
+		Start: 1,
+		Patch: kuberuntime.RawExtension{Raw: []byte(`{"spec":{"containers":[{"name":"nginx","image":"nginx:debug"}]}}`)},
+	}}
+	var status *apps.StatefulSetStatus
+	for i := 0; i < 10; i++ {
+		pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status, err = ssc.UpdateStatefulSet(set, pods); err != nil {
+			t.Fatal(err)
+		}
+		set.Status = *status
+		for ord := 0; ord < 3; ord++ {
+			pod, err := spc.podsLister.Pods(set.Namespace).Get(getPodName(set, ord))
+			if apierrors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if !isRunningAndReady(pod) {
+				if _, err := spc.setPodRunning(set, ord); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := spc.setPodReady(set, ord); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	if status.UpdatedReplicas != 3 {
+		t.Errorf("got %d updated replicas, want 3", status.UpdatedReplicas)
+	}
+	for ord, want := range []struct {
+		image     string
+		recreated bool
+	}{
+		{image: "nginx"},
+		{image: "nginx:debug", recreated: true},
+		{image: "nginx"},
+	} {
+		pod, err := spc.podsLister.Pods(set.Namespace).Get(getPodName(set, ord))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if revision := getPodRevision(pod); revision != status.UpdateRevision {
+			t.Errorf("pod %d: got revision %s, want %s", ord, revision, status.UpdateRevision)
+		}
+		if image := pod.Spec.Containers[0].Image; image != want.image {
+			t.Errorf("pod %d: got image %q, want %q", ord, image, want.image)
+		}
+		if recreated := pod.Annotations["original"] != "true"; recreated != want.recreated {
+			t.Errorf("pod %d: got recreated %v, want %v", ord, recreated, want.recreated)
+		}
+	}
+}
+
+func TestStatefulSetControlFrozenOrdinals(t *testing.T) {
+	set := newStatefulSet(3)
+	client := fake.NewSimpleClientset()
+	pcClient := pcfake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(pcClient, client)
+	defer close(stop)
+	if err := scaleUpStatefulSetControl(set, ssc, spc, assertMonotonicInvariants); err != nil {
+		t.Fatal(err)
+	}
+	set, err := spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	getPod := func(ordinal int) *v1.Pod {
+		pod, err := spc.podsLister.Pods(set.Namespace).Get(getPodName(set, ordinal))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pod.DeepCopy()
+	}
+	update := func() *apps.StatefulSetStatus {
+		pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		status, err := ssc.UpdateStatefulSet(set, pods)
+		if err != nil {
+			t.Fatal(err)
+		}
+		set.Status = *status
+		return status
+	}
+	currentRevision := set.Status.CurrentRevision
+
+	// ordinal 1 is frozen by the set and ordinal 2 by its pod
+	set = set.DeepCopy()
+	set.Spec.UpdateStrategy.RollingUpdate = &apps.RollingUpdateStatefulSetStrategy{Partition: utilpointer.Int32Ptr(0)}
+	if err := helper.SetFrozenOrdinals(set, sets.NewInt32(1)); err != nil {
+		t.Fatal(err)
+	}
+	pod := getPod(1)
+	pod.Status.Phase = v1.PodFailed
+	spc.podsIndexer.Update(pod)
+	pod = getPod(2)
+	helper.SetFrozen(pod, true)
+	spc.podsIndexer.Update(pod)
+
+	set.Spec.Template.Spec.Containers[0].Image = "foo"
+	var status *apps.StatefulSetStatus
+	for i := 0; i < 5; i++ {
+		status = update()
+		if _, err := spc.podsLister.Pods(set.Namespace).Get(getPodName(set, 0)); err == nil {
+			if _, err := spc.setPodRunning(set, 0); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := spc.setPodReady(set, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if !reflect.DeepEqual(status.FrozenOrdinals, []int32{1, 2}) {
+		t.Errorf("got frozen ordinals %v, want [1 2]", status.FrozenOrdinals)
+	}
+	if revision := getPodRevision(getPod(0)); revision != status.UpdateRevision {
+		t.Errorf("pod 0: got revision %s, want %s", revision, status.UpdateRevision)
+	}
+	if pod := getPod(1); pod.Status.Phase != v1.PodFailed || getPodRevision(pod) != currentRevision {
+		t.Errorf("frozen pod 1 should neither be recreated nor updated")
+	}
+	if revision := getPodRevision(getPod(2)); revision != currentRevision {
+		t.Errorf("frozen pod 2 should not be updated, got revision %s", revision)
+	}
+
+	// the failed pod is recreated once it is unfrozen
+	set = set.DeepCopy()
+	if err := helper.SetFrozenOrdinals(set, sets.NewInt32()); err != nil {
+		t.Fatal(err)
+	}
+	if status := update(); !reflect.DeepEqual(status.FrozenOrdinals, []int32{2}) {
+		t.Errorf("got frozen ordinals %v, want [2]", status.FrozenOrdinals)
+	}
+	if getPod(1).Status.Phase == v1.PodFailed {
+		t.Errorf("unfrozen pod 1 should be recreated")
+	}
+}
+
+func TestStatefulSetControlSurgeUpdate(t *testing.T) {
+	set := newStatefulSet(3)
+	set.Spec.UpdateStrategy.RollingUpdate = &apps.RollingUpdateStatefulSetStrategy{
+		Partition: utilpointer.Int32Ptr(0),
+		MaxSurge:  utilpointer.Int32Ptr(1),
+	}
+	client := fake.NewSimpleClientset()
+	pcClient := pcfake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(pcClient, client)
+	defer close(stop)
+	if err := scaleUpStatefulSetControl(set, ssc, spc, assertMonotonicInvariants); err != nil {
+		t.Fatal(err)
+	}
+	set, err := spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set = set.DeepCopy()
+	set.Spec.Template.Spec.Containers[0].Image = "foo"
+	var status *apps.StatefulSetStatus
+	surged := false
+	for i := 0; i < 30; i++ {
+		pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status, err = ssc.UpdateStatefulSet(set, pods); err != nil {
+			t.Fatal(err)
+		}
+		set.Status = *status
+		if len(status.Surges) > 1 {
+			t.Fatalf("got %d surges, want at most 1", len(status.Surges))
+		}
+		surged = surged || len(status.Surges) == 1
+		pods, err = spc.podsLister.Pods(set.Namespace).List(selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ready := 0
+		for _, pod := range pods {
+			if isRunningAndReady(pod) {
+				ready++
+				continue
+			}
+			if _, err := spc.setPodRunning(set, getOrdinal(pod)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := spc.setPodReady(set, getOrdinal(pod)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if ready < 3 {
+			t.Fatalf("got %d ready pods during the update, want at least 3", ready)
+		}
+	}
+	if !surged {
+		t.Errorf("pods should be replaced by surge pods")
+	}
+	pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 3 {
+		t.Fatalf("got %d pods, want 3", len(pods))
+	}
+	for _, pod := range pods {
+		if getOrdinal(pod) >= 3 {
+			t.Errorf("surge pod %s should be deleted", pod.Name)
+		}
+		if revision := getPodRevision(pod); revision != status.UpdateRevision {
+			t.Errorf("pod %s: got revision %s, want %s", pod.Name, revision, status.UpdateRevision)
+		}
+	}
+	if len(status.Surges) != 0 {
+		t.Errorf("got surges %v, want none", status.Surges)
+	}
+	if status.CurrentRevision != status.UpdateRevision {
+		t.Errorf("rolling update should be complete")
+	}
+	claims, err := spc.claimsLister.PersistentVolumeClaims(set.Namespace).List(labels.Everything())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, claim := range claims {
+		if !strings.HasSuffix(claim.Name, "-0") && !strings.HasSuffix(claim.Name, "-1") && !strings.HasSuffix(claim.Name, "-2") {
+			t.Errorf("claim %s of a surge pod should be deleted", claim.Name)
+		}
+	}
+	if len(claims) != 3 {
+		t.Errorf("got %d claims, want 3", len(claims))
+	}
+}
+
+func TestStatefulSetControlSurgeWithoutPartition(t *testing.T) {
+	set := newStatefulSet(3)
+	set.Spec.UpdateStrategy.RollingUpdate = &apps.RollingUpdateStatefulSetStrategy{MaxSurge: utilpointer.Int32Ptr(1)}
+	client := fake.NewSimpleClientset()
+	pcClient := pcfake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(pcClient, client)
+	defer close(stop)
+	if err := scaleUpStatefulSetControl(set, ssc, spc, assertMonotonicInvariants); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStatefulSetControlScaleBatchSize(t *testing.T) {
+	for _, policy := range []apps.PodManagementPolicyType{apps.OrderedReadyPodManagement, apps.ParallelPodManagement} {
+		set := newStatefulSet(5)
+		set.Spec.PodManagementPolicy = policy
+		set.Spec.ScaleBatchSize = utilpointer.Int32Ptr(2)
+		client := fake.NewSimpleClientset()
+		pcClient := pcfake.NewSimpleClientset(set)
+		spc, _, ssc, stop := setupController(pcClient, client)
+		defer close(stop)
+		selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		update := func() []*v1.Pod {
+			pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			status, err := ssc.UpdateStatefulSet(set, pods)
+			if err != nil {
+				t.Fatal(err)
+			}
+			set.Status = *status
+			pods, err = spc.podsLister.Pods(set.Namespace).List(selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Sort(ascendingOrdinal(pods))
+			return pods
+		}
+		setReady := func(pods []*v1.Pod) {
+			for _, pod := range pods {
+				if _, err := spc.setPodRunning(set, getOrdinal(pod)); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := spc.setPodReady(set, getOrdinal(pod)); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		// scale out creates a batch of pods and waits for it to be ready
+		if pods := update(); len(pods) != 2 {
+			t.Fatalf("%s: got %d pods after the first batch, want 2", policy, len(pods))
+		}
+		if pods := update(); len(pods) != 2 {
+			t.Fatalf("%s: got %d pods while the first batch is not ready, want 2", policy, len(pods))
+		}
+		setReady(update())
+		pods := update()
+		if len(pods) != 4 {
+			t.Fatalf("%s: got %d pods after the second batch, want 4", policy, len(pods))
+		}
+		setReady(pods)
+		setReady(update())
+
+		// scale in deletes a batch of pods
+		set = set.DeepCopy()
+		set.Spec.Replicas = utilpointer.Int32Ptr(1)
+		pods = update()
+		if len(pods) != 3 || getOrdinal(pods[len(pods)-1]) != 2 {
+			t.Fatalf("%s: got %d pods after the first scale in batch, want pods 0 to 2", policy, len(pods))
+		}
+		if pods := update(); len(pods) != 1 {
+			t.Fatalf("%s: got %d pods after the second scale in batch, want 1", policy, len(pods))
+		}
+	}
+}
+
+func TestStatefulSetControlEvictionBlocked(t *testing.T) {
+	set := newStatefulSet(3)
+	set.Spec.PodDeletionPolicy = apps.EvictPodDeletionPolicy
+	client := fake.NewSimpleClientset()
+	pcClient := pcfake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(pcClient, client)
+	defer close(stop)
+	if err := scaleUpStatefulSetControl(set, ssc, spc, assertMonotonicInvariants); err != nil {
+		t.Fatal(err)
+	}
+	set, err := spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set = set.DeepCopy()
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := func() *apps.StatefulSetStatus {
+		pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		status, err := ssc.UpdateStatefulSet(set, pods)
+		if err != nil {
+			t.Fatal(err)
+		}
+		set.Status = *status
+		return status
+	}
+	podExists := func(ordinal int) bool {
+		_, err := spc.podsLister.Pods(set.Namespace).Get(getPodName(set, ordinal))
+		return err == nil
+	}
+	tooManyRequests := apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+
+	// failed pods are deleted directly
+	spc.SetEvictStatefulPodError(tooManyRequests, 0)
+	pod, err := spc.podsLister.Pods(set.Namespace).Get(getPodName(set, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod = pod.DeepCopy()
+	pod.Status.Phase = v1.PodFailed
+	spc.podsIndexer.Update(pod)
+	update()
+	if spc.evictPodTracker.requests != 0 {
+		t.Errorf("failed pod should not be evicted")
+	}
+	if pod, err := spc.podsLister.Pods(set.Namespace).Get(getPodName(set, 1)); err != nil || pod.Status.Phase == v1.PodFailed {
+		t.Fatalf("failed pod should be recreated")
+	}
+	if _, err := spc.setPodRunning(set, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := spc.setPodReady(set, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// scale in is blocked by the refused eviction
+	set.Spec.Replicas = utilpointer.Int32Ptr(2)
+	status := update()
+	if !podExists(2) {
+		t.Fatalf("pod 2 should not be deleted while its eviction is refused")
+	}
+	cond := getStatefulSetCondition(*status, apps.StatefulSetDisruptionBlocked)
+	if cond == nil || cond.Status != v1.ConditionTrue || cond.Reason != evictionBlockedReason {
+		t.Errorf("DisruptionBlocked condition should be set, got %v", cond)
+	}
+	want := &apps.StatefulSetBlockedBy{Pod: getPodName(set, 2), Ordinal: 2, Reason: evictionBlockedReason}
+	if !reflect.DeepEqual(status.BlockedBy, want) {
+		t.Errorf("got blockedBy %+v, want %+v", status.BlockedBy, want)
+	}
+
+	// the eviction is retried
+	status = update()
+	if podExists(2) {
+		t.Errorf("pod 2 should be evicted")
+	}
+	if isDisruptionBlocked(status) {
+		t.Errorf("DisruptionBlocked condition should be removed, got %v", status.Conditions)
+	}
+	if status.BlockedBy != nil {
+		t.Errorf("blockedBy should be cleared, got %+v", status.BlockedBy)
+	}
+}
+
+func TestStatefulSetControlBlockedByAndPodSummaries(t *testing.T) {
+	set := newStatefulSet(3)
+	client := fake.NewSimpleClientset()
+	pcClient := pcfake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(pcClient, client)
+	defer close(stop)
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := func() *apps.StatefulSetStatus {
+		pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		status, err := ssc.UpdateStatefulSet(set, pods)
+		if err != nil {
+			t.Fatal(err)
+		}
+		set.Status = *status
+		return status
+	}
+
+	update()
+	if _, err := spc.setPodPending(set, 0); err != nil {
+		t.Fatal(err)
+	}
+	status := update()
+	want := &apps.StatefulSetBlockedBy{Pod: getPodName(set, 0), Ordinal: 0, Reason: string(ReasonWaitingForReady)}
+	if !reflect.DeepEqual(status.BlockedBy, want) {
+		t.Errorf("got blockedBy %+v, want %+v", status.BlockedBy, want)
+	}
+	summaries := []apps.StatefulSetPodSummary{{Ordinal: 0, Revision: status.UpdateRevision}}
+	if !reflect.DeepEqual(status.Pods, summaries) {
+		t.Errorf("got pods %+v, want %+v", status.Pods, summaries)
+	}
+
+	if _, err := spc.setPodRunning(set, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := spc.setPodReady(set, 0); err != nil {
+		t.Fatal(err)
+	}
+	update()
+	if _, err := spc.setPodPending(set, 1); err != nil {
+		t.Fatal(err)
+	}
+	status = update()
+	want = &apps.StatefulSetBlockedBy{Pod: getPodName(set, 1), Ordinal: 1, Reason: string(ReasonWaitingForReady)}
+	if !reflect.DeepEqual(status.BlockedBy, want) {
+		t.Errorf("got blockedBy %+v, want %+v", status.BlockedBy, want)
+	}
+	summaries = []apps.StatefulSetPodSummary{
+		{Ordinal: 0, Revision: status.UpdateRevision, Ready: true},
+		{Ordinal: 1, Revision: status.UpdateRevision},
+	}
+	if !reflect.DeepEqual(status.Pods, summaries) {
+		t.Errorf("got pods %+v, want %+v", status.Pods, summaries)
+	}
+}
+
+func TestStatefulSetControlPodEvictionDisabled(t *testing.T) {
+	defer featuregatetesting.SetFeatureGateDuringTest(t, features.DefaultFeatureGate, features.PodEviction, false)()
+	set := newStatefulSet(3)
+	set.Spec.PodDeletionPolicy = apps.EvictPodDeletionPolicy
+	client := fake.NewSimpleClientset()
+	pcClient := pcfake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(pcClient, client)
+	defer close(stop)
+	if err := scaleUpStatefulSetControl(set, ssc, spc, assertMonotonicInvariants); err != nil {
+		t.Fatal(err)
+	}
+	set, err := spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set = set.DeepCopy()
+	set.Spec.Replicas = utilpointer.Int32Ptr(2)
+	if err := scaleDownStatefulSetControl(set, ssc, spc, assertMonotonicInvariants); err != nil {
+		t.Fatal(err)
+	}
+	if spc.evictPodTracker.requests != 0 {
+		t.Errorf("pods should be deleted without the Eviction API when PodEviction is disabled, got %d evictions", spc.evictPodTracker.requests)
+	}
+}
+
+func TestStatefulSetControlDeletePreconditionFailed(t *testing.T) {
+	set := newStatefulSet(3)
+	client := fake.NewSimpleClientset()
+	pcClient := pcfake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(pcClient, client)
+	defer close(stop)
+	if err := scaleUpStatefulSetControl(set, ssc, spc, assertMonotonicInvariants); err != nil {
+		t.Fatal(err)
+	}
+	set, err := spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set = set.DeepCopy()
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set.Spec.Replicas = utilpointer.Int32Ptr(2)
+	spc.SetDeleteStatefulPodError(newDeleteError(apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, getPodName(set, 2),
+		errors.New("Precondition failed"))), 0)
+	status, err := ssc.UpdateStatefulSet(set, pods)
+	if err != nil {
+		t.Fatalf("a failed precondition should not be an error, got %v", err)
+	}
+	if status.Replicas != 3 {
+		t.Errorf("got %d replicas, want 3 as pod 2 was not deleted", status.Replicas)
+	}
+	if _, err := spc.podsLister.Pods(set.Namespace).Get(getPodName(set, 2)); err != nil {
+		t.Errorf("pod 2 should not be deleted: %v", err)
+	}
+
+	// other conflicts are errors
+	spc.SetDeleteStatefulPodError(apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, getPodName(set, 2),
+		errors.New("conflict")), 0)
+	if _, err := ssc.UpdateStatefulSet(set, pods); !apierrors.IsConflict(err) {
+		t.Errorf("got error %v, want a conflict", err)
+	}
+}
+
+func TestStatefulSetControlInPlaceVolumeClaimUpdate(t *testing.T) {
+	set := newStatefulSet(3)
+	client := fake.NewSimpleClientset()
+	pcClient := pcfake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(pcClient, client)
+	defer close(stop)
+	if err := scaleUpStatefulSetControl(set, ssc, spc, assertMonotonicInvariants); err != nil {
+		t.Fatal(err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	getClaim := func(ordinal int) *v1.PersistentVolumeClaim {
+		name := getPersistentVolumeClaimName(set, &set.Spec.VolumeClaimTemplates[0], ordinal)
+		claim, err := spc.claimsLister.PersistentVolumeClaims(set.Namespace).Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return claim.DeepCopy()
+	}
+	// resize simulates the resize of the volume of a claim to its requested storage
+	resize := func(ordinal int, conditions ...v1.PersistentVolumeClaimCondition) {
+		claim := getClaim(ordinal)
+		claim.Status.Phase = v1.ClaimBound
+		claim.Status.Capacity = v1.ResourceList{v1.ResourceStorage: claim.Spec.Resources.Requests[v1.ResourceStorage]}
+		claim.Status.Conditions = conditions
+		spc.claimsIndexer.Update(claim)
+	}
+	update := func() *apps.StatefulSetStatus {
+		pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		status, err := ssc.UpdateStatefulSet(set, pods)
+		if err != nil {
+			t.Fatal(err)
+		}
+		set.Status = *status
+		return status
+	}
+	for ord := 0; ord < 3; ord++ {
+		resize(ord)
+	}
+	if status := update(); status.UpdatedVolumeClaimReplicas != 3 {
+		t.Errorf("got %d updated volume claim replicas, want 3", status.UpdatedVolumeClaimReplicas)
+	}
+
+	set = set.DeepCopy()
+	set.Spec.VolumeClaimUpdateStrategy = apps.StatefulSetVolumeClaimUpdateStrategy{
+		Type:                                apps.InPlaceVolumeClaimUpdateStrategyType,
+		RestartPodOnFileSystemResizePending: true,
+	}
+	size := resource.MustParse("2Gi")
+	set.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[v1.ResourceStorage] = size
+	if status := update(); status.UpdatedVolumeClaimReplicas != 0 {
+		t.Errorf("got %d updated volume claim replicas, want 0", status.UpdatedVolumeClaimReplicas)
+	}
+	for ord, want := range []bool{false, false, true} {
+		request := getClaim(ord).Spec.Resources.Requests[v1.ResourceStorage]
+		if expanded := request.Cmp(size) == 0; expanded != want {
+			t.Errorf("claim of pod %d: got expanded %v, want %v", ord, expanded, want)
+		}
+	}
+
+	// the next pod is not expanded while the resize is in progress
+	update()
+	if request := getClaim(1).Spec.Resources.Requests[v1.ResourceStorage]; request.Cmp(size) == 0 {
+		t.Errorf("claim of pod 1 should not be expanded while claim of pod 2 is resizing")
+	}
+
+	// the pod is restarted if its file system needs to be resized
+	resize(2, v1.PersistentVolumeClaimCondition{Type: v1.PersistentVolumeClaimFileSystemResizePending, Status: v1.ConditionTrue})
+	update()
+	if _, err := spc.podsLister.Pods(set.Namespace).Get(getPodName(set, 2)); !apierrors.IsNotFound(err) {
+		t.Errorf("pod 2 should be deleted to resize its file system, got %v", err)
+	}
+
+	resize(2)
+	update()
+	if _, err := spc.setPodPending(set, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := spc.setPodRunning(set, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := spc.setPodReady(set, 2); err != nil {
+		t.Fatal(err)
+	}
+	if status := update(); status.UpdatedVolumeClaimReplicas != 1 {
+		t.Errorf("got %d updated volume claim replicas, want 1", status.UpdatedVolumeClaimReplicas)
+	}
+	if request := getClaim(1).Spec.Resources.Requests[v1.ResourceStorage]; request.Cmp(size) != 0 {
+		t.Errorf("claim of pod 1 should be expanded after claim of pod 2 has been resized")
+	}
+}
+
+func TestStatefulSetControlRefusedVolumeClaimExpansion(t *testing.T) {
+	set := newStatefulSet(3)
+	client := fake.NewSimpleClientset()
+	pcClient := pcfake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(pcClient, client)
+	defer close(stop)
+	if err := scaleUpStatefulSetControl(set, ssc, spc, assertMonotonicInvariants); err != nil {
+		t.Fatal(err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := func() {
+		pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		status, err := ssc.UpdateStatefulSet(set, pods)
+		if err != nil {
+			t.Fatalf("a refused expansion should not be an error, got %v", err)
+		}
+		set.Status = *status
+	}
+
+	// the StorageClass of the claims does not allow volume expansion
+	spc.SetUpdateVolumeClaimsError(apierrors.NewForbidden(schema.GroupResource{Resource: "persistentvolumeclaims"}, "",
+		errors.New("only dynamically provisioned pvc can be resized and the storageclass that provisions the pvc must support resize")), 0)
+	set = set.DeepCopy()
+	set.Spec.VolumeClaimUpdateStrategy.Type = apps.InPlaceVolumeClaimUpdateStrategyType
+	set.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[v1.ResourceStorage] = resource.MustParse("2Gi")
+	set.Spec.Template.Spec.Containers[0].Image = "foo:v2"
+	update()
+	update()
+	if spc.updateClaimsTracker.requests != 1 {
+		t.Errorf("a refused expansion should not be tried again, got %d expansions", spc.updateClaimsTracker.requests)
+	}
+	if spc.deletePodTracker.requests != 1 {
+		t.Errorf("the rolling update should not be blocked by a refused expansion, got %d deletions", spc.deletePodTracker.requests)
+	}
+	recorder := ssc.(*defaultStatefulSetControl).recorder.(*record.FakeRecorder)
+	refused := 0
+	for len(recorder.Events) > 0 {
+		if strings.Contains(<-recorder.Events, volumeClaimExpansionRefusedReason) {
+			refused++
+		}
+	}
+	if refused != 1 {
+		t.Errorf("got %d %s events, want 1", refused, volumeClaimExpansionRefusedReason)
+	}
+
+	// the claim is expanded again once the template changes
+	update()
+	if _, err := spc.setPodPending(set, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := spc.setPodRunning(set, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := spc.setPodReady(set, 2); err != nil {
+		t.Fatal(err)
+	}
+	set.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[v1.ResourceStorage] = resource.MustParse("3Gi")
+	update()
+	if spc.updateClaimsTracker.requests != 2 {
+		t.Errorf("the expansion should be tried again once the template changes, got %d expansions", spc.updateClaimsTracker.requests)
+	}
+}
+
+func TestStatefulSetControlRecreateVolumeClaimUpdate(t *testing.T) {
+	set := newStatefulSet(3)
+	client := fake.NewSimpleClientset()
+	pcClient := pcfake.NewSimpleClientset(set)
+	spc, _, ssc, stop := setupController(pcClient, client)
+	defer close(stop)
+	if err := scaleUpStatefulSetControl(set, ssc, spc, assertMonotonicInvariants); err != nil {
+		t.Fatal(err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storageClass := func(ordinal int) string {
+		name := getPersistentVolumeClaimName(set, &set.Spec.VolumeClaimTemplates[0], ordinal)
+		claim, err := spc.claimsLister.PersistentVolumeClaims(set.Namespace).Get(name)
+		if apierrors.IsNotFound(err) {
+			return "<deleted>"
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if claim.Spec.StorageClassName == nil {
+			return ""
+		}
+		return *claim.Spec.StorageClassName
+	}
+	update := func() {
+		pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		status, err := ssc.UpdateStatefulSet(set, pods)
+		if err != nil {
+			t.Fatal(err)
+		}
+		set.Status = *status
+	}
+	podExists := func(ordinal int) bool {
+		_, err := spc.podsLister.Pods(set.Namespace).Get(getPodName(set, ordinal))
+		return err == nil
+	}
+
+	set = set.DeepCopy()
+	set.Spec.VolumeClaimUpdateStrategy.Type = apps.RecreateVolumeClaimUpdateStrategyType
+
+	// a claim expanded beyond its template is kept
+	name := getPersistentVolumeClaimName(set, &set.Spec.VolumeClaimTemplates[0], 0)
+	claim, err := spc.claimsLister.PersistentVolumeClaims(set.Namespace).Get(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claim = claim.DeepCopy()
+	claim.Spec.Resources.Requests[v1.ResourceStorage] = resource.MustParse("100Gi")
+	spc.claimsIndexer.Update(claim)
+	update()
+	if !podExists(0) || storageClass(0) == "<deleted>" {
+		t.Fatalf("pod 0 and its expanded claim should be kept, got pod %v claim %q", podExists(0), storageClass(0))
+	}
+	if set.Status.UpdatedVolumeClaimReplicas != 3 {
+		t.Errorf("got %d updated volume claim replicas, want 3", set.Status.UpdatedVolumeClaimReplicas)
+	}
+
+	newClass := "fast"
+	set.Spec.VolumeClaimTemplates[0].Spec.StorageClassName = &newClass
+
+	// the pod with the largest ordinal and its claims are deleted first
+	update()
+	if podExists(2) || storageClass(2) != "<deleted>" {
+		t.Fatalf("pod 2 and its claim should be deleted, got pod %v claim %q", podExists(2), storageClass(2))
+	}
+	if !podExists(1) || storageClass(1) != "" {
+		t.Fatalf("pod 1 and its claim should be kept, got pod %v claim %q", podExists(1), storageClass(1))
+	}
+
+	// the pod is created again with a claim from the new template
+	update()
+	if !podExists(2) || storageClass(2) != newClass {
+		t.Fatalf("pod 2 should be created with a claim of class %q, got pod %v claim %q", newClass, podExists(2), storageClass(2))
+	}
+	if set.Status.UpdatedVolumeClaimReplicas != 0 {
+		t.Errorf("got %d updated volume claim replicas, want 0", set.Status.UpdatedVolumeClaimReplicas)
+	}
+
+	// the next pod waits until the recreated pod is ready
+	update()
+	if !podExists(1) {
+		t.Fatalf("pod 1 should not be deleted before pod 2 is ready")
+	}
+	if _, err := spc.setPodPending(set, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := spc.setPodRunning(set, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := spc.setPodReady(set, 2); err != nil {
+		t.Fatal(err)
+	}
+	update()
+	if podExists(1) || storageClass(1) != "<deleted>" {
+		t.Fatalf("pod 1 and its claim should be deleted, got pod %v claim %q", podExists(1), storageClass(1))
+	}
+	if set.Status.UpdatedVolumeClaimReplicas != 1 {
+		t.Errorf("got %d updated volume claim replicas, want 1", set.Status.UpdatedVolumeClaimReplicas)
+	}
+}
+
 type requestTracker struct {
 	requests int
 	err      error
@@ -1591,6 +2447,9 @@ type fakeStatefulPodControl struct {
 	createPodTracker requestTracker
 	updatePodTracker requestTracker
 	deletePodTracker requestTracker
+	evictPodTracker  requestTracker
+	// updateClaimsTracker fails all expansions once its error is ready, it is not reset
+	updateClaimsTracker requestTracker
 }
 
 func newFakeStatefulPodControl(podInformer coreinformers.PodInformer, setInformer appsinformers.StatefulSetInformer) *fakeStatefulPodControl {
@@ -1604,6 +2463,8 @@ func newFakeStatefulPodControl(podInformer coreinformers.PodInformer, setInforme
 		setInformer.Informer().GetIndexer(),
 		requestTracker{0, nil, 0},
 		requestTracker{0, nil, 0},
+		requestTracker{0, nil, 0},
+		requestTracker{0, nil, 0},
 		requestTracker{0, nil, 0}}
 }
 
@@ -1622,6 +2483,16 @@ func (spc *fakeStatefulPodControl) SetDeleteStatefulPodError(err error, after in
 	spc.deletePodTracker.after = after
 }
 
+func (spc *fakeStatefulPodControl) SetEvictStatefulPodError(err error, after int) {
+	spc.evictPodTracker.err = err
+	spc.evictPodTracker.after = after
+}
+
+func (spc *fakeStatefulPodControl) SetUpdateVolumeClaimsError(err error, after int) {
+	spc.updateClaimsTracker.err = err
+	spc.updateClaimsTracker.after = after
+}
+
 func (spc *fakeStatefulPodControl) setPodPending(set *apps.StatefulSet, ordinal int) ([]*v1.Pod, error) {
 	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
 	if err != nil {
@@ -1762,6 +2633,75 @@ func (spc *fakeStatefulPodControl) DeleteStatefulPod(set *apps.StatefulSet, pod
 	return nil
 }
 
+func (spc *fakeStatefulPodControl) EvictStatefulPod(set *apps.StatefulSet, pod *v1.Pod) error {
+	defer spc.evictPodTracker.inc()
+	if spc.evictPodTracker.errorReady() {
+		defer spc.evictPodTracker.reset()
+		return spc.evictPodTracker.err
+	}
+	return spc.DeleteStatefulPod(set, pod)
+}
+
+func (spc *fakeStatefulPodControl) UpdateStatefulPodRevision(set *apps.StatefulSet, pod *v1.Pod, revision string) error {
+	defer spc.updatePodTracker.inc()
+	if spc.updatePodTracker.errorReady() {
+		defer spc.updatePodTracker.reset()
+		return spc.updatePodTracker.err
+	}
+	pod = pod.DeepCopy()
+	setPodRevision(pod, revision)
+	spc.podsIndexer.Update(pod)
+	return nil
+}
+
+func (spc *fakeStatefulPodControl) ListPersistentVolumeClaims(set *apps.StatefulSet, pod *v1.Pod) (map[string]*v1.PersistentVolumeClaim, error) {
+	claims := make(map[string]*v1.PersistentVolumeClaim)
+	for name, claim := range getPersistentVolumeClaims(set, pod) {
+		existing, err := spc.claimsLister.PersistentVolumeClaims(claim.Namespace).Get(claim.Name)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		claims[name] = existing
+	}
+	return claims, nil
+}
+
+func (spc *fakeStatefulPodControl) UpdatePersistentVolumeClaims(set *apps.StatefulSet, pod *v1.Pod) error {
+	claims, err := spc.ListPersistentVolumeClaims(set, pod)
+	if err != nil {
+		return err
+	}
+	templates := getPersistentVolumeClaims(set, pod)
+	spc.updateClaimsTracker.inc()
+	if spc.updateClaimsTracker.errorReady() {
+		var errs []error
+		for name, claim := range claims {
+			request := templates[name].Spec.Resources.Requests[v1.ResourceStorage]
+			errs = append(errs, newVolumeClaimExpansionError(claim, request, spc.updateClaimsTracker.err))
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+	for name, claim := range claims {
+		claim = claim.DeepCopy()
+		claim.Spec.Resources.Requests[v1.ResourceStorage] = templates[name].Spec.Resources.Requests[v1.ResourceStorage]
+		spc.claimsIndexer.Update(claim)
+	}
+	return nil
+}
+
+func (spc *fakeStatefulPodControl) DeletePersistentVolumeClaims(set *apps.StatefulSet, pod *v1.Pod) error {
+	claims, err := spc.ListPersistentVolumeClaims(set, pod)
+	if err != nil {
+		return err
+	}
+	for _, claim := range claims {
+		spc.claimsIndexer.Delete(claim)
+	}
+	return nil
+}
+
 var _ StatefulPodControlInterface = &fakeStatefulPodControl{}
 
 type fakeStatefulSetStatusUpdater struct {
@@ -1978,7 +2918,7 @@ func scaleUpStatefulSetControl(set *apps.StatefulSet,
 		}
 
 		// run the controller once and check invariants
-		if err = ssc.UpdateStatefulSet(set, pods); err != nil {
+		if _, err = ssc.UpdateStatefulSet(set, pods); err != nil {
 			return err
 		}
 		set, err = spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
@@ -2004,7 +2944,7 @@ func scaleDownStatefulSetControl(set *apps.StatefulSet, ssc StatefulSetControlIn
 		}
 		sort.Sort(ascendingOrdinal(pods))
 		if ordinal := len(pods) - 1; ordinal >= 0 {
-			if err := ssc.UpdateStatefulSet(set, pods); err != nil {
+			if _, err := ssc.UpdateStatefulSet(set, pods); err != nil {
 				return err
 			}
 			set, err = spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
@@ -2014,7 +2954,7 @@ func scaleDownStatefulSetControl(set *apps.StatefulSet, ssc StatefulSetControlIn
 			if pods, err = spc.addTerminatingPod(set, ordinal); err != nil {
 				return err
 			}
-			if err = ssc.UpdateStatefulSet(set, pods); err != nil {
+			if _, err = ssc.UpdateStatefulSet(set, pods); err != nil {
 				return err
 			}
 			set, err = spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
@@ -2031,7 +2971,7 @@ func scaleDownStatefulSetControl(set *apps.StatefulSet, ssc StatefulSetControlIn
 				spc.podsIndexer.Delete(pods[len(pods)-1])
 			}
 		}
-		if err := ssc.UpdateStatefulSet(set, pods); err != nil {
+		if _, err := ssc.UpdateStatefulSet(set, pods); err != nil {
 			return err
 		}
 		set, err = spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
@@ -2094,7 +3034,7 @@ func updateStatefulSetControl(set *apps.StatefulSet,
 	if err != nil {
 		return err
 	}
-	if err = ssc.UpdateStatefulSet(set, pods); err != nil {
+	if _, err = ssc.UpdateStatefulSet(set, pods); err != nil {
 		return err
 	}
 
@@ -2142,7 +3082,7 @@ func updateStatefulSetControl(set *apps.StatefulSet,
 			}
 		}
 
-		if err = ssc.UpdateStatefulSet(set, pods); err != nil {
+		if _, err = ssc.UpdateStatefulSet(set, pods); err != nil {
 			return err
 		}
 		set, err = spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
@@ -0,0 +1,56 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+)
+
+// deleteSlotsPrunedReason is recorded as an event when the ignored delete slots of a set have been removed from its
+// annotation.
+const deleteSlotsPrunedReason = "DeleteSlotsPruned"
+
+// pruneDeleteSlots removes the ignored delete slots from the delete-slots annotation of a set which has the
+// prune-delete-slots annotation. Ignored slots do not affect the desired ordinals of the set, but would take effect
+// again if it is scaled out. The returned set is the set to sync, which is the updated set if it has been pruned, so
+// that the sync goes on even if the update is not persisted, e.g. in a dry run.
+func (ssc *StatefulSetController) pruneDeleteSlots(set *apps.StatefulSet) (*apps.StatefulSet, error) {
+	if !helper.GetPruneDeleteSlots(set) {
+		return set, nil
+	}
+	deleteSlots := helper.GetDeleteSlots(set)
+	ignored := helper.GetIgnoredDeleteSlots(*set.Spec.Replicas, deleteSlots)
+	if ignored.Len() == 0 {
+		return set, nil
+	}
+	clone := set.DeepCopy()
+	if err := helper.SetDeleteSlots(clone, deleteSlots.Difference(ignored)); err != nil {
+		return nil, err
+	}
+	klog.V(2).Infof("StatefulSet %s/%s pruning ignored delete slots %v", set.Namespace, set.Name, ignored.List())
+	updated, err := ssc.pcClient.AppsV1().StatefulSets(set.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	ssc.recorder.Eventf(set, v1.EventTypeNormal, deleteSlotsPrunedReason,
+		"Removed ignored delete slots %v of StatefulSet %s/%s", ignored.List(), set.Namespace, set.Name)
+	return updated, nil
+}
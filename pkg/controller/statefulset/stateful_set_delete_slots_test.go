@@ -0,0 +1,132 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+
+	pcfake "github.com/pingcap/advanced-statefulset/client/client/clientset/versioned/fake"
+
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+)
+
+func TestStatefulSetControllerPruneDeleteSlots(t *testing.T) {
+	tests := []struct {
+		name            string
+		prune           bool
+		dryRun          bool
+		wantDeleteSlots []int32
+		wantEvent       bool
+	}{
+		{
+			name:            "not opted in",
+			prune:           false,
+			wantDeleteSlots: []int32{1, 5, 7},
+		},
+		{
+			name:            "opted in",
+			prune:           true,
+			wantDeleteSlots: []int32{1},
+			wantEvent:       true,
+		},
+		{
+			name:            "opted in dry run",
+			prune:           true,
+			dryRun:          true,
+			wantDeleteSlots: []int32{1, 5, 7},
+			wantEvent:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := newStatefulSet(3)
+			if err := helper.SetDeleteSlots(set, sets.NewInt32(1, 5, 7)); err != nil {
+				t.Fatal(err)
+			}
+			if tt.prune {
+				set.Annotations[helper.PruneDeleteSlotsAnn] = "true"
+			}
+
+			ssc, spc := newFakeStatefulSetController([]runtime.Object{set}...)
+			recorder := record.NewFakeRecorder(10)
+			ssc.recorder = recorder
+			spc.setsIndexer.Add(set)
+			if tt.dryRun {
+				// a server-side dry run returns the updated set without persisting it
+				ssc.pcClient.(*pcfake.Clientset).PrependReactor("update", "statefulsets", func(action core.Action) (bool, runtime.Object, error) {
+					return true, action.(core.UpdateAction).GetObject(), nil
+				})
+			}
+			if err := ssc.sync(set.Namespace + "/" + set.Name); err != nil {
+				t.Fatalf("sync: %v", err)
+			}
+
+			got, err := ssc.pcClient.AppsV1().StatefulSets(set.Namespace).Get(context.TODO(), set.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleteSlots := helper.GetDeleteSlots(got).List(); !reflect.DeepEqual(deleteSlots, tt.wantDeleteSlots) {
+				t.Errorf("got delete slots %v, want %v", deleteSlots, tt.wantDeleteSlots)
+			}
+			var events []string
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			pruned := len(events) == 1 && strings.Contains(events[0], deleteSlotsPrunedReason)
+			if pruned != tt.wantEvent {
+				t.Errorf("got events %q, want a %s event: %v", events, deleteSlotsPrunedReason, tt.wantEvent)
+			}
+			if spc.createPodTracker.requests == 0 {
+				t.Errorf("the sync should go on after pruning the delete slots")
+			}
+		})
+	}
+}
+
+func TestStatefulSetControlDeleteSlotsStatus(t *testing.T) {
+	set := newStatefulSet(3)
+	if err := helper.SetDeleteSlots(set, sets.NewInt32(1, 5, 7)); err != nil {
+		t.Fatal(err)
+	}
+	spc, _, ssc, stop := setupController(pcfake.NewSimpleClientset(set), fake.NewSimpleClientset())
+	defer close(stop)
+	pods, err := spc.podsLister.Pods(set.Namespace).List(labels.Everything())
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := ssc.UpdateStatefulSet(set, pods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int32{0, 2, 3}; !reflect.DeepEqual(status.Ordinals, want) {
+		t.Errorf("got ordinals %v, want %v", status.Ordinals, want)
+	}
+	if want := []int32{1}; !reflect.DeepEqual(status.DeleteSlots, want) {
+		t.Errorf("got delete slots %v, want %v", status.DeleteSlots, want)
+	}
+	if want := []int32{5, 7}; !reflect.DeepEqual(status.IgnoredDeleteSlots, want) {
+		t.Errorf("got ignored delete slots %v, want %v", status.IgnoredDeleteSlots, want)
+	}
+}
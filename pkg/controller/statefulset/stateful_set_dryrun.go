@@ -0,0 +1,175 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"fmt"
+	"strings"
+
+	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+// NewDryRunStatefulPodControl returns a StatefulPodControlInterface which records the creates, updates and deletes of
+// Pods and PersistentVolumeClaims as logs and events on the StatefulSet instead of performing them. Claims are read
+// from pvcLister.
+func NewDryRunStatefulPodControl(pvcLister corelisters.PersistentVolumeClaimLister, recorder record.EventRecorder) StatefulPodControlInterface {
+	return &dryRunStatefulPodControl{
+		// the real control is only used to list the claims
+		StatefulPodControlInterface: &realStatefulPodControl{pvcLister: pvcLister},
+		recorder:                    recorder,
+	}
+}
+
+// dryRunStatefulPodControl implements StatefulPodControlInterface without writing to the API server. The embedded
+// StatefulPodControlInterface is used for reads.
+type dryRunStatefulPodControl struct {
+	StatefulPodControlInterface
+	recorder record.EventRecorder
+}
+
+func (spc *dryRunStatefulPodControl) CreateStatefulPod(set *apps.StatefulSet, pod *v1.Pod) error {
+	if err := spc.createPersistentVolumeClaims(set, pod); err != nil {
+		return err
+	}
+	spc.recordPod("create", set, pod)
+	return nil
+}
+
+func (spc *dryRunStatefulPodControl) UpdateStatefulPod(set *apps.StatefulSet, pod *v1.Pod) error {
+	if identityMatches(set, pod) && storageMatches(set, pod) {
+		return nil
+	}
+	if !storageMatches(set, pod) {
+		if err := spc.createPersistentVolumeClaims(set, pod); err != nil {
+			return err
+		}
+	}
+	spc.recordPod("update", set, pod)
+	return nil
+}
+
+func (spc *dryRunStatefulPodControl) DeleteStatefulPod(set *apps.StatefulSet, pod *v1.Pod) error {
+	spc.recordPod("delete", set, pod)
+	return nil
+}
+
+func (spc *dryRunStatefulPodControl) EvictStatefulPod(set *apps.StatefulSet, pod *v1.Pod) error {
+	spc.recordPod("evict", set, pod)
+	return nil
+}
+
+func (spc *dryRunStatefulPodControl) UpdateStatefulPodRevision(set *apps.StatefulSet, pod *v1.Pod, revision string) error {
+	klog.InfoS("Dry run", "action", "update", "statefulSet", klog.KObj(set), "pod", klog.KObj(pod), "revision", revision)
+	spc.recorder.Eventf(set, v1.EventTypeNormal, "DryRunUpdate", "would update Pod %s in StatefulSet %s to revision %s", pod.Name, set.Name, revision)
+	return nil
+}
+
+func (spc *dryRunStatefulPodControl) UpdatePersistentVolumeClaims(set *apps.StatefulSet, pod *v1.Pod) error {
+	claims, err := spc.ListPersistentVolumeClaims(set, pod)
+	if err != nil {
+		return err
+	}
+	templates := getPersistentVolumeClaims(set, pod)
+	for name, claim := range claims {
+		template := templates[name]
+		if claimNeedsExpansion(&template, claim) {
+			spc.recordClaim("update", set, pod, claim)
+		}
+	}
+	return nil
+}
+
+func (spc *dryRunStatefulPodControl) DeletePersistentVolumeClaims(set *apps.StatefulSet, pod *v1.Pod) error {
+	claims, err := spc.ListPersistentVolumeClaims(set, pod)
+	if err != nil {
+		return err
+	}
+	for _, claim := range claims {
+		if claim.DeletionTimestamp == nil {
+			spc.recordClaim("delete", set, pod, claim)
+		}
+	}
+	return nil
+}
+
+// createPersistentVolumeClaims records the creates of the PersistentVolumeClaims of pod which do not exist. Like the
+// real control, it fails if a claim is being deleted.
+func (spc *dryRunStatefulPodControl) createPersistentVolumeClaims(set *apps.StatefulSet, pod *v1.Pod) error {
+	existing, err := spc.ListPersistentVolumeClaims(set, pod)
+	if err != nil {
+		return err
+	}
+	for name, claim := range getPersistentVolumeClaims(set, pod) {
+		switch current, ok := existing[name]; {
+		case !ok:
+			spc.recordClaim("create", set, pod, &claim)
+		case current.DeletionTimestamp != nil:
+			// the claim is recreated once it is gone, until then the Pod must not use it
+			return fmt.Errorf("PVC %s is being deleted", claim.Name)
+		}
+	}
+	return nil
+}
+
+// recordPod logs and records an event for verb which would be applied to a Pod in a StatefulSet.
+func (spc *dryRunStatefulPodControl) recordPod(verb string, set *apps.StatefulSet, pod *v1.Pod) {
+	klog.InfoS("Dry run", "action", verb, "statefulSet", klog.KObj(set), "pod", klog.KObj(pod), "revision", getPodRevision(pod))
+	spc.recorder.Eventf(set, v1.EventTypeNormal, dryRunReason(verb), "would %s Pod %s in StatefulSet %s", verb, pod.Name, set.Name)
+}
+
+// recordClaim logs and records an event for verb which would be applied to a PersistentVolumeClaim of a Pod in a
+// StatefulSet.
+func (spc *dryRunStatefulPodControl) recordClaim(verb string, set *apps.StatefulSet, pod *v1.Pod, claim *v1.PersistentVolumeClaim) {
+	klog.InfoS("Dry run", "action", verb, "statefulSet", klog.KObj(set), "pod", klog.KObj(pod), "claim", klog.KObj(claim))
+	spc.recorder.Eventf(set, v1.EventTypeNormal, dryRunReason(verb), "would %s Claim %s for Pod %s in StatefulSet %s", verb, claim.Name, pod.Name, set.Name)
+}
+
+var _ StatefulPodControlInterface = &dryRunStatefulPodControl{}
+
+// NewDryRunStatefulSetStatusUpdater returns a StatefulSetStatusUpdaterInterface which records the changes of the Status
+// of StatefulSets as logs and events instead of updating it.
+func NewDryRunStatefulSetStatusUpdater(recorder record.EventRecorder) StatefulSetStatusUpdaterInterface {
+	return &dryRunStatefulSetStatusUpdater{recorder}
+}
+
+type dryRunStatefulSetStatusUpdater struct {
+	recorder record.EventRecorder
+}
+
+func (ssu *dryRunStatefulSetStatusUpdater) UpdateStatefulSetStatus(set *apps.StatefulSet, status *apps.StatefulSetStatus) error {
+	klog.InfoS("Dry run", "action", "updateStatus", "statefulSet", klog.KObj(set),
+		"observedGeneration", status.ObservedGeneration,
+		"replicas", status.Replicas,
+		"readyReplicas", status.ReadyReplicas,
+		"currentReplicas", status.CurrentReplicas,
+		"updatedReplicas", status.UpdatedReplicas,
+		"currentRevision", status.CurrentRevision,
+		"updateRevision", status.UpdateRevision)
+	ssu.recorder.Eventf(set, v1.EventTypeNormal, dryRunReason("updateStatus"),
+		"would update status of StatefulSet %s: replicas %d->%d, ready %d->%d, updated %d->%d, update revision %q->%q",
+		set.Name, set.Status.Replicas, status.Replicas, set.Status.ReadyReplicas, status.ReadyReplicas,
+		set.Status.UpdatedReplicas, status.UpdatedReplicas, set.Status.UpdateRevision, status.UpdateRevision)
+	return nil
+}
+
+var _ StatefulSetStatusUpdaterInterface = &dryRunStatefulSetStatusUpdater{}
+
+// dryRunReason returns the reason of the events recorded for verb in dry run mode.
+func dryRunReason(verb string) string {
+	return "DryRun" + strings.ToUpper(verb[:1]) + verb[1:]
+}
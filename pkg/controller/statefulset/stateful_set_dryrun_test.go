@@ -0,0 +1,67 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestStatefulSetControlDryRun(t *testing.T) {
+	set := newStatefulSet(3)
+	kubeClient := fake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	recorder := record.NewFakeRecorder(10)
+	control := NewDefaultStatefulSetControl(
+		NewDryRunStatefulPodControl(informerFactory.Core().V1().PersistentVolumeClaims().Lister(), recorder),
+		NewDryRunStatefulSetStatusUpdater(recorder),
+		kubeClient.AppsV1(),
+		recorder)
+
+	status, err := control.UpdateStatefulSet(set, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the Pods are reported as created, so they are counted in the status
+	if status.Replicas != 1 {
+		t.Errorf("got %d replicas in the status, want 1", status.Replicas)
+	}
+	for _, action := range kubeClient.Actions() {
+		if resource := action.GetResource().Resource; resource == "pods" || resource == "persistentvolumeclaims" {
+			t.Errorf("unexpected %s of %s in dry run", action.GetVerb(), resource)
+		}
+	}
+
+	var events []string
+	for len(recorder.Events) > 0 {
+		events = append(events, <-recorder.Events)
+	}
+	want := []string{
+		"Normal DryRunCreate would create Claim datadir-foo-0 for Pod foo-0 in StatefulSet foo",
+		"Normal DryRunCreate would create Pod foo-0 in StatefulSet foo",
+		"Normal DryRunUpdateStatus would update status of StatefulSet foo",
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got events %q, want %d events", events, len(want))
+	}
+	for i := range want {
+		if !strings.HasPrefix(events[i], want[i]) {
+			t.Errorf("got event %q, want %q", events[i], want[i])
+		}
+	}
+}
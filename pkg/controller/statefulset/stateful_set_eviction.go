@@ -0,0 +1,76 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"errors"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+
+	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	"github.com/pingcap/advanced-statefulset/pkg/features"
+)
+
+// evictionBlockedReason is added to the DisruptionBlocked condition when the eviction of a Pod is refused.
+const evictionBlockedReason = "EvictionBlocked"
+
+// errDisruptionBlocked is returned by a sync of a StatefulSet whose DisruptionBlocked condition is true, so that the
+// StatefulSet is requeued with the backoff of the queue's rate limiter. It is not reported as a sync error.
+var errDisruptionBlocked = errors.New("eviction blocked by a PodDisruptionBudget")
+
+// disruptStatefulPod deletes pod, which is condemned by a scale in or stale after an update, according to set's
+// PodDeletionPolicy. If the eviction of pod is refused because it would violate a PodDisruptionBudget, the
+// DisruptionBlocked condition is set on status and the returned bool is false. The returned bool is true if pod is
+// being deleted.
+func (ssc *defaultStatefulSetControl) disruptStatefulPod(set *apps.StatefulSet, status *apps.StatefulSetStatus, pod *v1.Pod) (bool, error) {
+	if set.Spec.PodDeletionPolicy != apps.EvictPodDeletionPolicy || !features.DefaultFeatureGate.Enabled(features.PodEviction) {
+		return true, ssc.podControl.DeleteStatefulPod(set, pod)
+	}
+	err := ssc.podControl.EvictStatefulPod(set, pod)
+	if apierrors.IsTooManyRequests(err) {
+		klog.V(2).Infof("StatefulSet %s/%s eviction of Pod %s is blocked: %v",
+			set.Namespace,
+			set.Name,
+			pod.Name,
+			err)
+		setDisruptionBlockedCondition(set, status, pod, err)
+		return false, nil
+	}
+	// the Pod is already gone
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	return err == nil, err
+}
+
+// setDisruptionBlockedCondition sets the DisruptionBlocked condition of status for the refused eviction of pod. The
+// transition time of the condition is kept if it is already true in set's status.
+func setDisruptionBlockedCondition(set *apps.StatefulSet, status *apps.StatefulSetStatus, pod *v1.Pod, err error) {
+	blocked := newStatefulSetCondition(apps.StatefulSetDisruptionBlocked, v1.ConditionTrue, evictionBlockedReason,
+		fmt.Sprintf("Eviction of Pod %s is blocked: %v", pod.Name, err))
+	if cond := getStatefulSetCondition(set.Status, apps.StatefulSetDisruptionBlocked); cond != nil && cond.Status == v1.ConditionTrue {
+		blocked.LastTransitionTime = cond.LastTransitionTime
+	}
+	status.Conditions = filterOutCondition(status.Conditions, apps.StatefulSetDisruptionBlocked)
+	setStatefulSetCondition(status, blocked)
+}
+
+// isDisruptionBlocked returns true if the DisruptionBlocked condition of status is true.
+func isDisruptionBlocked(status *apps.StatefulSetStatus) bool {
+	cond := getStatefulSetCondition(*status, apps.StatefulSetDisruptionBlocked)
+	return cond != nil && cond.Status == v1.ConditionTrue
+}
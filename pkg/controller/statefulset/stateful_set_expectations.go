@@ -0,0 +1,167 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+// expectationsTimeout is the time after which the creates and deletes of a StatefulSet which have not been observed
+// are forgotten, so that a lost watch event does not block the StatefulSet forever. It matches the timeout of the
+// expectations of the upstream controllers.
+const expectationsTimeout = 5 * time.Minute
+
+// The kinds of objects whose creates and deletes are expected.
+const (
+	podKind   = "Pod"
+	claimKind = "PersistentVolumeClaim"
+)
+
+// expectationKey identifies a Pod or PersistentVolumeClaim.
+type expectationKey struct {
+	// kind is podKind or claimKind.
+	kind string
+	// key is the namespace/name of the object.
+	key string
+}
+
+// expectation is a create or delete issued by the controller which has not been observed in the informer cache yet.
+type expectation struct {
+	// set is the key of the StatefulSet for which the object is created or deleted.
+	set string
+	// create is true for a create and false for a delete.
+	create bool
+	// timestamp is the time the create or delete was issued.
+	timestamp time.Time
+}
+
+// controllerExpectations tracks the creates and deletes of Pods and PersistentVolumeClaims issued by the controller
+// until they are observed by the informers, so that a StatefulSet is not synced from a cache which does not reflect
+// them yet. Unlike the expectations of the upstream controllers, the objects are tracked by name rather than counted,
+// as the Pods and claims of a StatefulSet have stable names. It is safe for concurrent use. A nil
+// *controllerExpectations expects nothing.
+type controllerExpectations struct {
+	clock   clock.Clock
+	lock    sync.Mutex
+	pending map[expectationKey]expectation
+}
+
+func newControllerExpectations(clock clock.Clock) *controllerExpectations {
+	return &controllerExpectations{clock: clock, pending: make(map[expectationKey]expectation)}
+}
+
+func newExpectationKey(kind string, obj metav1.Object) expectationKey {
+	return expectationKey{kind: kind, key: obj.GetNamespace() + "/" + obj.GetName()}
+}
+
+// expect records a create or delete of obj for the StatefulSet with setKey.
+func (e *controllerExpectations) expect(setKey, kind string, obj metav1.Object, create bool) {
+	if e == nil {
+		return
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.pending[newExpectationKey(kind, obj)] = expectation{set: setKey, create: create, timestamp: e.clock.Now()}
+}
+
+// forget forgets the create or delete of obj, it is called when the create or delete failed.
+func (e *controllerExpectations) forget(kind string, obj metav1.Object) {
+	if e == nil {
+		return
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	delete(e.pending, newExpectationKey(kind, obj))
+}
+
+// observed records that a create or delete of obj has been observed. It returns the key of the StatefulSet which
+// expected it and whether it has no other pending creates and deletes. An empty key is returned if the create or
+// delete was not expected.
+func (e *controllerExpectations) observed(kind string, obj metav1.Object, create bool) (string, bool) {
+	if e == nil {
+		return "", false
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	key := newExpectationKey(kind, obj)
+	exp, ok := e.pending[key]
+	if !ok || exp.create != create {
+		return "", false
+	}
+	delete(e.pending, key)
+	for _, other := range e.pending {
+		if other.set == exp.set {
+			return exp.set, false
+		}
+	}
+	return exp.set, true
+}
+
+// satisfied returns true if all creates and deletes of the StatefulSet with setKey have been observed or timed out.
+// Otherwise, the time after which the oldest pending create or delete times out is returned.
+func (e *controllerExpectations) satisfied(setKey string) (bool, time.Duration) {
+	if e == nil {
+		return true, 0
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	now := e.clock.Now()
+	var wait time.Duration
+	for key, exp := range e.pending {
+		if exp.set != setKey {
+			continue
+		}
+		remaining := exp.timestamp.Add(expectationsTimeout).Sub(now)
+		if remaining <= 0 {
+			klog.V(2).Infof("StatefulSet %s expectation of %s %s timed out", setKey, key.kind, key.key)
+			delete(e.pending, key)
+			continue
+		}
+		if wait == 0 || remaining < wait {
+			wait = remaining
+		}
+	}
+	return wait == 0, wait
+}
+
+// deleteExpectations forgets the creates and deletes of the StatefulSet with setKey, it is called when the
+// StatefulSet is deleted.
+func (e *controllerExpectations) deleteExpectations(setKey string) {
+	if e == nil {
+		return
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for key, exp := range e.pending {
+		if exp.set == setKey {
+			delete(e.pending, key)
+		}
+	}
+}
+
+// observePod records the create or delete of pod if it is expected. A Pod is deleted once it is terminating.
+func (e *controllerExpectations) observePod(pod *v1.Pod, create bool) (string, bool) {
+	return e.observed(podKind, pod, create)
+}
+
+// observeClaim records the create or delete of claim if it is expected. A claim is deleted once it is terminating.
+func (e *controllerExpectations) observeClaim(claim *v1.PersistentVolumeClaim, create bool) (string, bool) {
+	return e.observed(claimKind, claim, create)
+}
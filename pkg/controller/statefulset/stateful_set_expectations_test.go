@@ -0,0 +1,126 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestControllerExpectations(t *testing.T) {
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	expectations := newControllerExpectations(fakeClock)
+	set := newStatefulSet(3)
+	key := statefulSetKey(set)
+	pod := newStatefulSetPod(set, 0)
+	claims := getPersistentVolumeClaims(set, pod)
+	claim := claims[set.Spec.VolumeClaimTemplates[0].Name]
+
+	if satisfied, _ := expectations.satisfied(key); !satisfied {
+		t.Errorf("expectations should be satisfied without creates and deletes")
+	}
+	expectations.expect(key, podKind, pod, true)
+	expectations.expect(key, claimKind, &claim, false)
+	if satisfied, wait := expectations.satisfied(key); satisfied || wait != expectationsTimeout {
+		t.Errorf("got satisfied %v after %v, want to wait %v", satisfied, wait, expectationsTimeout)
+	}
+
+	// a delete does not satisfy a create
+	if setKey, _ := expectations.observePod(pod, false); setKey != "" {
+		t.Errorf("delete of pod should not be expected, got %q", setKey)
+	}
+	if setKey, satisfied := expectations.observePod(pod, true); setKey != key || satisfied {
+		t.Errorf("got %q (satisfied: %v), want %q with a pending claim", setKey, satisfied, key)
+	}
+	if setKey, satisfied := expectations.observeClaim(&claim, false); setKey != key || !satisfied {
+		t.Errorf("got %q (satisfied: %v), want %q satisfied", setKey, satisfied, key)
+	}
+	if satisfied, _ := expectations.satisfied(key); !satisfied {
+		t.Errorf("expectations should be satisfied once observed")
+	}
+
+	// failed creates are forgotten
+	expectations.expect(key, podKind, pod, true)
+	expectations.forget(podKind, pod)
+	if satisfied, _ := expectations.satisfied(key); !satisfied {
+		t.Errorf("expectations should be satisfied after a failed create")
+	}
+
+	// expectations time out
+	expectations.expect(key, podKind, pod, false)
+	fakeClock.Step(time.Minute)
+	if satisfied, wait := expectations.satisfied(key); satisfied || wait != expectationsTimeout-time.Minute {
+		t.Errorf("got satisfied %v after %v, want to wait %v", satisfied, wait, expectationsTimeout-time.Minute)
+	}
+	fakeClock.Step(expectationsTimeout)
+	if satisfied, _ := expectations.satisfied(key); !satisfied {
+		t.Errorf("expectations should be satisfied after they timed out")
+	}
+
+	// expectations of deleted sets are forgotten
+	expectations.expect(key, podKind, pod, true)
+	expectations.deleteExpectations(key)
+	if satisfied, _ := expectations.satisfied(key); !satisfied {
+		t.Errorf("expectations should be satisfied after the set is deleted")
+	}
+
+	// a nil expectations expects nothing
+	var none *controllerExpectations
+	none.expect(key, podKind, pod, true)
+	if satisfied, _ := none.satisfied(key); !satisfied {
+		t.Errorf("nil expectations should be satisfied")
+	}
+}
+
+func TestStatefulSetControllerExpectations(t *testing.T) {
+	ssc, spc := newFakeStatefulSetController()
+	set := newStatefulSet(3)
+	spc.setsIndexer.Add(set)
+	key := statefulSetKey(set)
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	countPods := func() int {
+		pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(pods)
+	}
+
+	// the sync is skipped while the create of pod 0 is not observed
+	pod := newStatefulSetPod(set, 0)
+	ssc.expectations.expect(key, podKind, pod, true)
+	if err := ssc.sync(key); err != nil {
+		t.Fatal(err)
+	}
+	if n := countPods(); n != 0 {
+		t.Fatalf("got %d pods while the expectations are not satisfied, want 0", n)
+	}
+
+	ssc.addPod(pod)
+	if ssc.queue.Len() != 1 {
+		t.Errorf("set should be enqueued once the create is observed")
+	}
+	if err := ssc.sync(key); err != nil {
+		t.Fatal(err)
+	}
+	if n := countPods(); n == 0 {
+		t.Errorf("pods should be created once the expectations are satisfied")
+	}
+}
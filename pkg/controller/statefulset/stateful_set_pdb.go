@@ -0,0 +1,157 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	"github.com/pingcap/advanced-statefulset/pkg/features"
+)
+
+// podDisruptionBudgetConflictReason is recorded as an event when a PodDisruptionBudget with the name of a set exists
+// which is not owned by the set.
+const podDisruptionBudgetConflictReason = "PodDisruptionBudgetConflict"
+
+// newPodDisruptionBudget returns the PodDisruptionBudget described by set's spec.podDisruptionBudget. Percentages are
+// resolved against the replicas of set, so that the budget does not depend on the scale subresource. If the returned
+// error is nil, the returned PodDisruptionBudget is valid.
+func newPodDisruptionBudget(set *apps.StatefulSet) (*policyv1.PodDisruptionBudget, error) {
+	budget := set.Spec.PodDisruptionBudget
+	replicas := int(*set.Spec.Replicas)
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            set.Name,
+			Namespace:       set.Namespace,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(set, controllerKind)},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: set.Spec.Selector.DeepCopy(),
+		},
+	}
+	switch {
+	case budget.MinAvailable != nil && budget.MaxUnavailable != nil:
+		return nil, fmt.Errorf("at most one of minAvailable and maxUnavailable may be set")
+	case budget.MinAvailable != nil:
+		minAvailable, err := intstr.GetScaledValueFromIntOrPercent(budget.MinAvailable, replicas, true)
+		if err != nil {
+			return nil, fmt.Errorf("invalid minAvailable: %v", err)
+		}
+		value := intstr.FromInt(minAvailable)
+		pdb.Spec.MinAvailable = &value
+	case budget.MaxUnavailable != nil:
+		maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(budget.MaxUnavailable, replicas, true)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maxUnavailable: %v", err)
+		}
+		value := intstr.FromInt(maxUnavailable)
+		pdb.Spec.MaxUnavailable = &value
+	default:
+		value := intstr.FromInt(1)
+		pdb.Spec.MaxUnavailable = &value
+	}
+	return pdb, nil
+}
+
+// syncPodDisruptionBudget creates, updates or deletes the PodDisruptionBudget owned by set according to set's
+// spec.podDisruptionBudget. A PodDisruptionBudget with the name of set which is not owned by set is left untouched.
+func (ssc *StatefulSetController) syncPodDisruptionBudget(set *apps.StatefulSet) error {
+	if set.DeletionTimestamp != nil || !features.DefaultFeatureGate.Enabled(features.ManagedPodDisruptionBudget) {
+		return nil
+	}
+	pdb, err := ssc.pdbLister.PodDisruptionBudgets(set.Namespace).Get(set.Name)
+	if apierrors.IsNotFound(err) {
+		pdb = nil
+	} else if err != nil {
+		return err
+	}
+	if pdb != nil && !metav1.IsControlledBy(pdb, set) {
+		if set.Spec.PodDisruptionBudget != nil {
+			ssc.recorder.Eventf(set, v1.EventTypeWarning, podDisruptionBudgetConflictReason,
+				"PodDisruptionBudget %s/%s already exists and is not owned by StatefulSet %s",
+				pdb.Namespace, pdb.Name, set.Name)
+		}
+		return nil
+	}
+
+	if set.Spec.PodDisruptionBudget == nil {
+		if pdb == nil {
+			return nil
+		}
+		klog.V(2).Infof("StatefulSet %s/%s deleting PodDisruptionBudget %s", set.Namespace, set.Name, pdb.Name)
+		err := ssc.kubeClient.PolicyV1().PodDisruptionBudgets(pdb.Namespace).Delete(context.TODO(), pdb.Name, metav1.DeleteOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	desired, err := newPodDisruptionBudget(set)
+	if err != nil {
+		ssc.recorder.Eventf(set, v1.EventTypeWarning, "InvalidPodDisruptionBudget", "StatefulSet %s/%s: %v",
+			set.Namespace, set.Name, err)
+		// This is a non-transient error, so don't retry.
+		return nil
+	}
+	if pdb == nil {
+		klog.V(2).Infof("StatefulSet %s/%s creating PodDisruptionBudget %s", set.Namespace, set.Name, desired.Name)
+		_, err := ssc.kubeClient.PolicyV1().PodDisruptionBudgets(desired.Namespace).Create(context.TODO(), desired, metav1.CreateOptions{})
+		return err
+	}
+	if apiequality.Semantic.DeepEqual(pdb.Spec, desired.Spec) {
+		return nil
+	}
+	// Make a deep copy so we don't mutate the shared cache
+	pdb = pdb.DeepCopy()
+	pdb.Spec = desired.Spec
+	klog.V(2).Infof("StatefulSet %s/%s updating PodDisruptionBudget %s", set.Namespace, set.Name, pdb.Name)
+	_, err = ssc.kubeClient.PolicyV1().PodDisruptionBudgets(pdb.Namespace).Update(context.TODO(), pdb, metav1.UpdateOptions{})
+	return err
+}
+
+// enqueuePodDisruptionBudgetOwner enqueues the statefulset which owns the PodDisruptionBudget, accounting for deletion
+// tombstones, so that changes made to the budget by others are reverted.
+func (ssc *StatefulSetController) enqueuePodDisruptionBudgetOwner(obj interface{}) {
+	pdb, ok := obj.(*policyv1.PodDisruptionBudget)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("couldn't get object from tombstone %+v", obj))
+			return
+		}
+		pdb, ok = tombstone.Obj.(*policyv1.PodDisruptionBudget)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("tombstone contained object that is not a PodDisruptionBudget %+v", obj))
+			return
+		}
+	}
+	controllerRef := metav1.GetControllerOf(pdb)
+	if controllerRef == nil {
+		return
+	}
+	if set := ssc.resolveControllerRef(pdb.Namespace, controllerRef); set != nil {
+		ssc.enqueueStatefulSet(set)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"context"
+	"testing"
+
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	policylisters "k8s.io/client-go/listers/policy/v1"
+	"k8s.io/client-go/tools/cache"
+	utilpointer "k8s.io/utils/pointer"
+
+	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+)
+
+func TestNewPodDisruptionBudget(t *testing.T) {
+	percent := intstr.FromString("50%")
+	one := intstr.FromInt(1)
+	two := intstr.FromInt(2)
+	tests := []struct {
+		name               string
+		budget             apps.StatefulSetPodDisruptionBudget
+		wantMinAvailable   *intstr.IntOrString
+		wantMaxUnavailable *intstr.IntOrString
+		wantErr            bool
+	}{
+		{
+			name:               "default",
+			wantMaxUnavailable: &one,
+		},
+		{
+			name:             "min available",
+			budget:           apps.StatefulSetPodDisruptionBudget{MinAvailable: &two},
+			wantMinAvailable: &two,
+		},
+		{
+			name:               "max unavailable percentage rounds up",
+			budget:             apps.StatefulSetPodDisruptionBudget{MaxUnavailable: &percent},
+			wantMaxUnavailable: &two,
+		},
+		{
+			name:    "both",
+			budget:  apps.StatefulSetPodDisruptionBudget{MinAvailable: &two, MaxUnavailable: &two},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := newStatefulSet(3)
+			set.Spec.PodDisruptionBudget = &tt.budget
+			pdb, err := newPodDisruptionBudget(set)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !metav1.IsControlledBy(pdb, set) {
+				t.Errorf("PodDisruptionBudget should be controlled by the set")
+			}
+			if pdb.Name != set.Name || pdb.Spec.Selector.String() != set.Spec.Selector.String() {
+				t.Errorf("got PodDisruptionBudget %s with selector %v", pdb.Name, pdb.Spec.Selector)
+			}
+			if !equalIntOrString(pdb.Spec.MinAvailable, tt.wantMinAvailable) {
+				t.Errorf("got minAvailable %v, want %v", pdb.Spec.MinAvailable, tt.wantMinAvailable)
+			}
+			if !equalIntOrString(pdb.Spec.MaxUnavailable, tt.wantMaxUnavailable) {
+				t.Errorf("got maxUnavailable %v, want %v", pdb.Spec.MaxUnavailable, tt.wantMaxUnavailable)
+			}
+		})
+	}
+}
+
+func equalIntOrString(a, b *intstr.IntOrString) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func TestStatefulSetControllerSyncPodDisruptionBudget(t *testing.T) {
+	set := newStatefulSet(3)
+	percent := intstr.FromString("50%")
+	set.Spec.PodDisruptionBudget = &apps.StatefulSetPodDisruptionBudget{MaxUnavailable: &percent}
+	ssc, _ := newFakeStatefulSetController()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	ssc.pdbLister = policylisters.NewPodDisruptionBudgetLister(indexer)
+	pdbs := ssc.kubeClient.PolicyV1().PodDisruptionBudgets(set.Namespace)
+	sync := func() *policyv1.PodDisruptionBudget {
+		if err := ssc.syncPodDisruptionBudget(set); err != nil {
+			t.Fatal(err)
+		}
+		pdb, err := pdbs.Get(context.TODO(), set.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		indexer.Update(pdb)
+		return pdb
+	}
+
+	if pdb := sync(); pdb == nil || pdb.Spec.MaxUnavailable.IntValue() != 2 {
+		t.Fatalf("got PodDisruptionBudget %v, want maxUnavailable 2", pdb)
+	}
+
+	// the budget follows the replicas
+	set.Spec.Replicas = utilpointer.Int32Ptr(5)
+	if pdb := sync(); pdb.Spec.MaxUnavailable.IntValue() != 3 {
+		t.Errorf("got maxUnavailable %v after scale out, want 3", pdb.Spec.MaxUnavailable)
+	}
+
+	// the budget is deleted with spec.podDisruptionBudget
+	set.Spec.PodDisruptionBudget = nil
+	if pdb := sync(); pdb != nil {
+		t.Errorf("PodDisruptionBudget should be deleted")
+	}
+
+	// a budget which is not owned by the set is left untouched
+	foreign := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: set.Name, Namespace: set.Namespace},
+		Spec:       policyv1.PodDisruptionBudgetSpec{MinAvailable: &percent},
+	}
+	if _, err := pdbs.Create(context.TODO(), foreign, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	indexer.Add(foreign)
+	set.Spec.PodDisruptionBudget = &apps.StatefulSetPodDisruptionBudget{}
+	if pdb := sync(); pdb.Spec.MinAvailable == nil || pdb.Spec.MinAvailable.String() != "50%" {
+		t.Errorf("foreign PodDisruptionBudget should not be changed, got %v", pdb.Spec)
+	}
+}
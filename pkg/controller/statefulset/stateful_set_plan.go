@@ -0,0 +1,617 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	kubeapps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+)
+
+// ActionType is the type of an Action of a Plan.
+type ActionType string
+
+const (
+	// ActionCreatePod creates a Pod and its missing PersistentVolumeClaims.
+	ActionCreatePod ActionType = "CreatePod"
+	// ActionUpdatePod updates the identity and the storage of a Pod to match the StatefulSet.
+	ActionUpdatePod ActionType = "UpdatePod"
+	// ActionUpdatePodRevision moves a Pod whose template does not change to the update revision without recreating
+	// it.
+	ActionUpdatePodRevision ActionType = "UpdatePodRevision"
+	// ActionDeletePod deletes a Pod. Pods which are scaled in or updated are evicted instead if the StatefulSet's
+	// PodDeletionPolicy is Evict.
+	ActionDeletePod ActionType = "DeletePod"
+	// ActionExpandVolumeClaims expands the PersistentVolumeClaims of a Pod to the storage requested by the
+	// volumeClaimTemplates.
+	ActionExpandVolumeClaims ActionType = "ExpandVolumeClaims"
+	// ActionDeleteVolumeClaims deletes the PersistentVolumeClaims of a Pod.
+	ActionDeleteVolumeClaims ActionType = "DeleteVolumeClaims"
+	// ActionWait waits for a Pod, it changes nothing.
+	ActionWait ActionType = "Wait"
+)
+
+// ActionReason explains why an Action is taken.
+type ActionReason string
+
+const (
+	// ReasonMissing is the reason of the creation of a replica which does not exist.
+	ReasonMissing ActionReason = "Missing"
+	// ReasonIdentityMismatch is the reason of the update of a Pod whose identity or storage does not match the
+	// StatefulSet.
+	ReasonIdentityMismatch ActionReason = "IdentityMismatch"
+	// ReasonFailed is the reason of the deletion of a failed Pod, it is created again.
+	ReasonFailed ActionReason = "Failed"
+	// ReasonSucceeded is the reason of the deletion of a Pod which terminated successfully, it is created again.
+	ReasonSucceeded ActionReason = "Succeeded"
+	// ReasonRollbackBlocked is the reason of the deletion of an unready Pod which blocks a rollback, it is created
+	// again at the update revision.
+	ReasonRollbackBlocked ActionReason = "RollbackBlocked"
+	// ReasonScaleIn is the reason of the deletion of a Pod which is not a replica anymore.
+	ReasonScaleIn ActionReason = "ScaleIn"
+	// ReasonUpdate is the reason of the update or the deletion of a Pod which is not at the update revision.
+	ReasonUpdate ActionReason = "Update"
+	// ReasonSurge is the reason of the creation of a surge Pod which replaces a Pod which is not at the update
+	// revision.
+	ReasonSurge ActionReason = "Surge"
+	// ReasonSurgeReplaced is the reason of the deletion of a Pod which is replaced by a Running and Ready surge Pod.
+	ReasonSurgeReplaced ActionReason = "SurgeReplaced"
+	// ReasonSurgeRetired is the reason of the deletion of a surge Pod which is not needed anymore and of its
+	// PersistentVolumeClaims.
+	ReasonSurgeRetired ActionReason = "SurgeRetired"
+	// ReasonVolumeClaimExpansion is the reason of the expansion of PersistentVolumeClaims smaller than requested.
+	ReasonVolumeClaimExpansion ActionReason = "VolumeClaimExpansion"
+	// ReasonFileSystemResize is the reason of the deletion of a Pod whose volumes wait for their file system to be
+	// resized, which happens when they are mounted again.
+	ReasonFileSystemResize ActionReason = "FileSystemResize"
+	// ReasonVolumeClaimRecreate is the reason of the deletion of a Pod and of its PersistentVolumeClaims which do not
+	// match the volumeClaimTemplates, they are created again.
+	ReasonVolumeClaimRecreate ActionReason = "VolumeClaimRecreate"
+	// ReasonRecreateBackoff is the reason of waiting before a terminated Pod is created again.
+	ReasonRecreateBackoff ActionReason = "RecreateBackoff"
+	// ReasonWaitingForReady is the reason of waiting for a Pod to be Running and Ready.
+	ReasonWaitingForReady ActionReason = "WaitingForReady"
+	// ReasonWaitingForTermination is the reason of waiting for a Pod to be gone.
+	ReasonWaitingForTermination ActionReason = "WaitingForTermination"
+	// ReasonWaitingForVolumeClaimResize is the reason of waiting for the PersistentVolumeClaims of a Pod to be
+	// resized.
+	ReasonWaitingForVolumeClaimResize ActionReason = "WaitingForVolumeClaimResize"
+)
+
+// Action is a step of a Plan.
+type Action struct {
+	Type   ActionType
+	Reason ActionReason
+	// Pod is the Pod the Action applies to. For ActionCreatePod, it is the Pod to create.
+	Pod *v1.Pod
+	// Ordinal is the ordinal of Pod.
+	Ordinal int
+}
+
+// String returns a description of a such as "DeletePod web-2 (ScaleIn)".
+func (a Action) String() string {
+	return fmt.Sprintf("%s %s (%s)", a.Type, a.Pod.Name, a.Reason)
+}
+
+// Plan is the ordered list of the Actions of a sync of a StatefulSet, assuming that each of them succeeds. The
+// controller stops at the first Action which fails and computes a new Plan in the next sync.
+type Plan struct {
+	Actions []Action
+	// Surges are the surge Pods of a rolling update with maxSurge which are tracked in the status of the StatefulSet
+	// once the Plan is executed.
+	Surges []apps.StatefulSetSurge
+}
+
+// Blocked returns the Wait Action the Plan ends with, or nil if it does not end with one. The StatefulSet makes no
+// further progress until the Pod of this Action changes.
+func (p *Plan) Blocked() *Action {
+	if len(p.Actions) == 0 || p.Actions[len(p.Actions)-1].Type != ActionWait {
+		return nil
+	}
+	return &p.Actions[len(p.Actions)-1]
+}
+
+// PlanInput is the state of a StatefulSet a Plan is computed for.
+type PlanInput struct {
+	// Set is the StatefulSet.
+	Set *apps.StatefulSet
+	// Pods are the Pods owned by Set.
+	Pods []*v1.Pod
+	// CurrentRevision and UpdateRevision are the current and update revisions of Set.
+	CurrentRevision *kubeapps.ControllerRevision
+	UpdateRevision  *kubeapps.ControllerRevision
+	// VolumeClaims are the existing PersistentVolumeClaims of Pods keyed by Pod name and by the name of their
+	// volumeClaimTemplate. They are only used if Set has a VolumeClaimUpdateStrategy.
+	VolumeClaims map[string]map[string]*v1.PersistentVolumeClaim
+	// VolumeClaimUpdates enables the updates of the PersistentVolumeClaims according to the VolumeClaimUpdateStrategy
+	// of Set, it is the VolumeClaimUpdates feature gate of the controller.
+	VolumeClaimUpdates bool
+	// RecreateBackoff returns the time to wait before the terminated Pod of ordinal may be created again, and true
+	// if it must not be created again because it reached Set's MaxRecreateAttempts. Terminated Pods are created again
+	// at once if it is nil.
+	RecreateBackoff func(ordinal int) (time.Duration, bool)
+	// VolumeClaimExpansionRefused returns true if the expansion of claim to request has been refused by the API
+	// server. Such claims are not expanded again, so that they do not block the rollout. All claims are expanded if
+	// it is nil.
+	VolumeClaimExpansionRefused func(claim *v1.PersistentVolumeClaim, request resource.Quantity) bool
+}
+
+// PlanStatefulSet returns the Plan of the next sync of input.Set. It has no side effects, so it can be used to
+// preview or explain what the controller does.
+func PlanStatefulSet(input *PlanInput) (*Plan, error) {
+	currentSet, err := ApplyRevision(input.Set, input.CurrentRevision)
+	if err != nil {
+		return nil, err
+	}
+	updateSet, err := ApplyRevision(input.Set, input.UpdateRevision)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateOrdinalOverrides(updateSet); err != nil {
+		return nil, err
+	}
+	return planStatefulSet(input, currentSet, updateSet), nil
+}
+
+// planStatefulSet returns the Plan of input, whose StatefulSet is currentSet at the current revision and updateSet
+// at the update revision. The Pods of the replicas are created and kept Running and Ready first, then the Pods which
+// are not replicas anymore are deleted, then the PersistentVolumeClaims are updated and finally the Pods are updated
+// according to the update strategy.
+func planStatefulSet(input *PlanInput, currentSet, updateSet *apps.StatefulSet) *Plan {
+	p := newPlanner(input, currentSet, updateSet)
+	// If the StatefulSet is being deleted, don't do anything other than updating status.
+	if input.Set.DeletionTimestamp != nil {
+		return p.plan
+	}
+	if p.planReplicas() || p.planScaleIn() || p.planVolumeClaims() {
+		return p.plan
+	}
+	// for the OnDelete strategy we short circuit. Pods will be updated when they are manually deleted.
+	if input.Set.Spec.UpdateStrategy.Type == apps.OnDeleteStatefulSetStrategyType {
+		return p.plan
+	}
+	// with maxSurge, stale Pods are only deleted once a surge Pod replaces them.
+	if getMaxSurge(input.Set) > 0 {
+		p.planSurgeUpdate()
+		return p.plan
+	}
+	p.planRollingUpdate()
+	return p.plan
+}
+
+// planner computes a Plan. Its plan methods return true if the Plan ends, because the sync is blocked or because
+// the Actions must be observed before the next ones are taken.
+type planner struct {
+	set             *apps.StatefulSet
+	currentSet      *apps.StatefulSet
+	updateSet       *apps.StatefulSet
+	currentRevision string
+	updateRevision  *kubeapps.ControllerRevision
+	input           *PlanInput
+	plan            *Plan
+
+	// replicas are the Pods of the replicas of set indexed by ordinal, Pods which do not exist are new Pods. Delete
+	// slots and frozen ordinals are nil, so they are neither created, updated nor deleted and do not block the
+	// other replicas.
+	replicas []*v1.Pod
+	// condemned are the Pods which are not replicas anymore sorted by ordinal. Frozen Pods are not scaled in.
+	condemned []*v1.Pod
+	// surgePods are the existing surge Pods keyed by ordinal.
+	surgePods map[int]*v1.Pod
+	// firstUnhealthyPod is the unhealthy Pod with the smallest ordinal among replicas and condemned.
+	firstUnhealthyPod *v1.Pod
+	monotonic         bool
+	batchSize         int
+}
+
+func newPlanner(input *PlanInput, currentSet, updateSet *apps.StatefulSet) *planner {
+	set := input.Set
+	p := &planner{
+		set:             set,
+		currentSet:      currentSet,
+		updateSet:       updateSet,
+		currentRevision: input.CurrentRevision.Name,
+		updateRevision:  input.UpdateRevision,
+		input:           input,
+		plan:            &Plan{},
+		surgePods:       make(map[int]*v1.Pod),
+		monotonic:       !allowsBurst(set),
+		batchSize:       getScaleBatchSize(set),
+	}
+	// desired replica slots: [0, replicaCount) - [delete slots]
+	_replicaCount, deleteSlots := helper.GetMaxReplicaCountAndDeleteSlots(*set.Spec.Replicas, helper.GetDeleteSlots(set))
+	replicaCount := int(_replicaCount)
+
+	// the surge Pods of a rolling update with maxSurge are tracked in status and are not replicas, unless a scale out
+	// reached their ordinals, then they are adopted as replicas and their targets are replaced by new surges
+	if getMaxSurge(set) > 0 {
+		for _, surge := range set.Status.Surges {
+			if ord := int(surge.Ordinal); ord < replicaCount && !deleteSlots.Has(surge.Ordinal) {
+				continue
+			}
+			p.plan.Surges = append(p.plan.Surges, surge)
+		}
+	}
+	frozen := getFrozenOrdinals(set, input.Pods, p.plan.Surges)
+
+	// First we partition pods into two lists valid replicas and condemned Pods
+	p.replicas = make([]*v1.Pod, replicaCount)
+	for _, pod := range input.Pods {
+		ord := getOrdinal(pod)
+		if isSurgeOrdinal(p.plan.Surges, ord) {
+			p.surgePods[ord] = pod
+			continue
+		}
+		if 0 <= ord && ord < replicaCount && !deleteSlots.Has(int32(ord)) {
+			// if the ordinal of the pod is within the range of the current number of replicas,
+			// insert it at the indirection of its ordinal
+			p.replicas[ord] = pod
+		} else if ord >= replicaCount || deleteSlots.Has(int32(ord)) {
+			// if the ordinal is greater than the number of replicas add it to the condemned list, unless it is frozen
+			if !frozen.Has(int32(ord)) {
+				p.condemned = append(p.condemned, pod)
+			}
+		}
+		// If the ordinal could not be parsed (ord < 0), ignore the Pod.
+	}
+	// for any empty indices in the sequence [0,set.Spec.Replicas) and do not exist in deleteSlots create a new Pod at the correct revision
+	for ord := 0; ord < replicaCount; ord++ {
+		switch {
+		case deleteSlots.Has(int32(ord)):
+		case frozen.Has(int32(ord)):
+			p.replicas[ord] = nil
+		case p.replicas[ord] == nil:
+			p.replicas[ord] = newVersionedStatefulSetPod(currentSet, updateSet, p.currentRevision, p.updateRevision.Name, ord)
+		}
+	}
+	// sort the condemned Pods by their ordinals
+	sort.Sort(ascendingOrdinal(p.condemned))
+
+	// find the first unhealthy Pod
+	for _, pod := range append(append([]*v1.Pod(nil), p.replicas...), p.condemned...) {
+		if pod != nil && !isHealthy(pod) && (p.firstUnhealthyPod == nil || getOrdinal(pod) < getOrdinal(p.firstUnhealthyPod)) {
+			p.firstUnhealthyPod = pod
+		}
+	}
+	return p
+}
+
+// add appends an Action to the plan.
+func (p *planner) add(actionType ActionType, reason ActionReason, pod *v1.Pod) {
+	p.plan.Actions = append(p.plan.Actions, Action{Type: actionType, Reason: reason, Pod: pod, Ordinal: getOrdinal(pod)})
+}
+
+// waitingReason returns the reason of waiting for pod to be healthy.
+func waitingReason(pod *v1.Pod) ActionReason {
+	if isTerminating(pod) {
+		return ReasonWaitingForTermination
+	}
+	return ReasonWaitingForReady
+}
+
+// planReplicas plans the creation of the missing replicas, the recreation of the terminated ones and the updates of
+// their identity, in ordinal order. In monotonic mode, it waits for each replica to be Running and Ready.
+func (p *planner) planReplicas() bool {
+	// the number of Pods which are being created. In burst mode, Pods which are not Running and Ready yet count as
+	// well, in monotonic mode we never get past such a Pod.
+	creating := 0
+	if !p.monotonic {
+		for _, pod := range p.replicas {
+			if pod != nil && isCreated(pod) && !isTerminating(pod) && !isRunningAndReady(pod) {
+				creating++
+			}
+		}
+	}
+
+	// Examine each replica with respect to its ordinal
+	for i, pod := range p.replicas {
+		if pod == nil {
+			continue
+		}
+		// Delete and recreate pods which finished running.
+		//
+		// Note that pods with phase Succeeded will also trigger this event. This is
+		// because final pod phase of evicted or otherwise forcibly stopped pods
+		// (e.g. terminated on node reboot) is determined by the exit code of the
+		// container, not by the reason for pod termination. We should restart the pod
+		// regardless of the exit code.
+		//
+		// While rolling back, Pods which are blocking the rollback are recreated as well.
+		rollbackBlocked := blocksRollback(p.set, pod, p.updateRevision)
+		terminated := isFailed(pod) || isSucceeded(pod)
+		// Pods which terminate again after they have been recreated are recreated with an exponential backoff.
+		if terminated && !rollbackBlocked && p.input.RecreateBackoff != nil {
+			if wait, exceeded := p.input.RecreateBackoff(i); exceeded || wait > 0 {
+				p.add(ActionWait, ReasonRecreateBackoff, pod)
+				if p.monotonic {
+					return true
+				}
+				continue
+			}
+		}
+		if terminated || rollbackBlocked {
+			reason := ReasonSucceeded
+			if rollbackBlocked {
+				reason = ReasonRollbackBlocked
+			} else if isFailed(pod) {
+				reason = ReasonFailed
+			}
+			p.add(ActionDeletePod, reason, pod)
+			pod = newVersionedStatefulSetPod(p.currentSet, p.updateSet, p.currentRevision, p.updateRevision.Name, i)
+			p.replicas[i] = pod
+		}
+		// If we find a Pod that has not been created we create the Pod
+		if !isCreated(pod) {
+			// in burst mode, wait for a batch of Pods to be Running and Ready before the next batch is created
+			if p.batchSize > 0 && creating >= p.batchSize {
+				continue
+			}
+			p.add(ActionCreatePod, ReasonMissing, pod)
+			creating++
+			// if the set does not allow bursting, return once a batch of Pods has been created
+			if p.monotonic && creating >= p.batchSize {
+				return true
+			}
+			continue
+		}
+		// If we find a Pod that is currently terminating, we must wait until graceful deletion
+		// completes before we continue to make progress.
+		if isTerminating(pod) && p.monotonic {
+			p.add(ActionWait, ReasonWaitingForTermination, pod)
+			return true
+		}
+		// If we have a Pod that has been created but is not running and ready we can not make progress.
+		// We must ensure that all for each Pod, when we create it, all of its predecessors, with respect to its
+		// ordinal, are Running and Ready.
+		if !isRunningAndReady(pod) && p.monotonic {
+			p.add(ActionWait, ReasonWaitingForReady, pod)
+			return true
+		}
+		// Enforce the StatefulSet invariants
+		if identityMatches(p.set, pod) && storageMatches(p.set, pod) {
+			continue
+		}
+		p.add(ActionUpdatePod, ReasonIdentityMismatch, pod)
+	}
+	// in monotonic mode, the created batch must be Running and Ready before we continue
+	return p.monotonic && creating > 0
+}
+
+// planScaleIn plans the deletion of the condemned Pods in decreasing ordinal order. All replicas are Running and
+// Ready at this point in monotonic mode. Note that we do not resurrect Pods in this interval. Also note that scaling
+// will take precedence over updates.
+func (p *planner) planScaleIn() bool {
+	// the number of condemned Pods which are terminating, at most batchSize Pods are terminated at once
+	terminating := 0
+	for target := len(p.condemned) - 1; target >= 0; target-- {
+		pod := p.condemned[target]
+		// wait for terminating pods to expire
+		if isTerminating(pod) {
+			p.add(ActionWait, ReasonWaitingForTermination, pod)
+			terminating++
+			// block if we are in monotonic mode and the batch is complete
+			if p.monotonic && terminating >= p.batchSize {
+				return true
+			}
+			continue
+		}
+		if p.batchSize > 0 && terminating >= p.batchSize {
+			return true
+		}
+		// if we are in monotonic mode and the condemned target is not the first unhealthy Pod block
+		if !isRunningAndReady(pod) && p.monotonic && pod != p.firstUnhealthyPod {
+			p.add(ActionWait, ReasonWaitingForReady, p.firstUnhealthyPod)
+			return true
+		}
+		p.add(ActionDeletePod, ReasonScaleIn, pod)
+		terminating++
+		if p.monotonic && terminating >= p.batchSize {
+			return true
+		}
+	}
+	// in monotonic mode, scaling takes precedence over updates
+	return p.monotonic && terminating > 0
+}
+
+// planVolumeClaims plans the updates of the PersistentVolumeClaims of the replicas according to the
+// VolumeClaimUpdateStrategy. The claims of the Pod with the largest ordinal whose claims are not updated are processed
+// first, and no other Pod is processed until they are updated.
+func (p *planner) planVolumeClaims() bool {
+	if !p.input.VolumeClaimUpdates {
+		return false
+	}
+	strategy := p.set.Spec.VolumeClaimUpdateStrategy
+	if strategy.Type != apps.InPlaceVolumeClaimUpdateStrategyType &&
+		strategy.Type != apps.RecreateVolumeClaimUpdateStrategyType {
+		return false
+	}
+	for target := len(p.replicas) - 1; target >= 0; target-- {
+		pod := p.replicas[target]
+		if pod == nil || !isCreated(pod) || isTerminating(pod) {
+			continue
+		}
+		claims := p.input.VolumeClaims[pod.Name]
+		if strategy.Type == apps.InPlaceVolumeClaimUpdateStrategyType {
+			claims = p.expandableVolumeClaims(pod, claims)
+		}
+		if volumeClaimsUpdated(p.set, pod, claims) {
+			continue
+		}
+		switch strategy.Type {
+		case apps.InPlaceVolumeClaimUpdateStrategyType:
+			switch {
+			case volumeClaimsNeedExpansion(p.set, pod, claims):
+				// the claims of a Pod which is not Running and Ready are expanded once it is
+				if !isRunningAndReady(pod) {
+					continue
+				}
+				p.add(ActionExpandVolumeClaims, ReasonVolumeClaimExpansion, pod)
+			case strategy.RestartPodOnFileSystemResizePending && volumeClaimsFileSystemResizePending(claims):
+				// the file system of a volume is resized when the volume is mounted again
+				p.add(ActionDeletePod, ReasonFileSystemResize, pod)
+			default:
+				p.add(ActionWait, ReasonWaitingForVolumeClaimResize, pod)
+			}
+			return true
+		case apps.RecreateVolumeClaimUpdateStrategyType:
+			// the Pod is unavailable until it is recreated, so all replicas must be Running and Ready first
+			for _, replica := range p.replicas {
+				if replica != nil && !isRunningAndReady(replica) {
+					p.add(ActionWait, ReasonWaitingForReady, replica)
+					return true
+				}
+			}
+			// the claims are deleted first, they are only removed once the Pod is gone and the Pod is not created
+			// again before that
+			p.add(ActionDeleteVolumeClaims, ReasonVolumeClaimRecreate, pod)
+			p.add(ActionDeletePod, ReasonVolumeClaimRecreate, pod)
+			return true
+		}
+	}
+	return false
+}
+
+// expandableVolumeClaims returns claims, the claims of pod, without the claims whose expansion to the storage request
+// of their volumeClaimTemplate has been refused.
+func (p *planner) expandableVolumeClaims(pod *v1.Pod, claims map[string]*v1.PersistentVolumeClaim) map[string]*v1.PersistentVolumeClaim {
+	if p.input.VolumeClaimExpansionRefused == nil {
+		return claims
+	}
+	templates := getPersistentVolumeClaims(p.set, pod)
+	expandable := make(map[string]*v1.PersistentVolumeClaim, len(claims))
+	for name, claim := range claims {
+		template := templates[name]
+		if claimNeedsExpansion(&template, claim) &&
+			p.input.VolumeClaimExpansionRefused(claim, template.Spec.Resources.Requests[v1.ResourceStorage]) {
+			continue
+		}
+		expandable[name] = claim
+	}
+	return expandable
+}
+
+// planSurgeUpdate plans a rolling update with maxSurge. For each stale Pod, starting with the largest ordinal, a surge
+// Pod with an unused ordinal beyond the replicas is created at the update revision. The stale Pod is deleted once the
+// surge Pod is Running and Ready, and the surge Pod is deleted once the stale Pod has been recreated at the update
+// revision and is Running and Ready. The surges in progress are tracked in status, so that they are resumed after a
+// restart of the controller.
+func (p *planner) planSurgeUpdate() {
+	var surges []apps.StatefulSetSurge
+	targets := sets.NewInt()
+	for _, surge := range p.plan.Surges {
+		pod := p.surgePods[int(surge.Ordinal)]
+		var target *v1.Pod
+		if int(surge.Target) < len(p.replicas) {
+			target = p.replicas[surge.Target]
+		}
+		// the surge Pod is retired once its target is replaced or is not a replica anymore
+		if target == nil || (isCreated(target) && !isTerminating(target) &&
+			getPodRevision(target) == p.updateRevision.Name && isRunningAndReady(target)) {
+			if pod == nil {
+				continue
+			}
+			surges = append(surges, surge)
+			// the claims of a surge Pod are not used by any replica, they are deleted first and removed once the
+			// surge Pod is gone
+			p.add(ActionDeleteVolumeClaims, ReasonSurgeRetired, pod)
+			// surge Pods are in excess of the replicas, so they are deleted directly
+			if !isTerminating(pod) {
+				p.add(ActionDeletePod, ReasonSurgeRetired, pod)
+			}
+			continue
+		}
+		surges = append(surges, surge)
+		targets.Insert(int(surge.Target))
+		switch {
+		case pod == nil:
+			p.add(ActionCreatePod, ReasonSurge, newSurgePod(p.updateSet, p.updateRevision.Name, int(surge.Ordinal), int(surge.Target)))
+		case !isRunningAndReady(pod) || isTerminating(pod):
+			p.add(ActionWait, waitingReason(pod), pod)
+		case isCreated(target) && !isTerminating(target) && getPodRevision(target) != p.updateRevision.Name:
+			p.add(ActionDeletePod, ReasonSurgeReplaced, target)
+		}
+	}
+	p.plan.Surges = surges
+	if len(p.plan.Surges) >= getMaxSurge(p.set) {
+		return
+	}
+
+	// start to replace the stale Pod with the largest ordinal which is not replaced yet
+	updateMin := int(getPartition(p.set))
+	for target := len(p.replicas) - 1; target >= updateMin; target-- {
+		if p.replicas[target] == nil || !isCreated(p.replicas[target]) || isTerminating(p.replicas[target]) ||
+			getPodRevision(p.replicas[target]) == p.updateRevision.Name || targets.Has(target) {
+			continue
+		}
+		used := sets.NewInt()
+		for _, pod := range p.input.Pods {
+			used.Insert(getOrdinal(pod))
+		}
+		ordinal := len(p.replicas)
+		for used.Has(ordinal) || isSurgeOrdinal(p.plan.Surges, ordinal) {
+			ordinal++
+		}
+		p.add(ActionCreatePod, ReasonSurge, newSurgePod(p.updateSet, p.updateRevision.Name, ordinal, target))
+		p.plan.Surges = append(p.plan.Surges, apps.StatefulSetSurge{Ordinal: int32(ordinal), Target: int32(target)})
+		return
+	}
+}
+
+// planRollingUpdate plans the update of the Pod with the largest ordinal at or beyond the partition which does not
+// match the update revision, once the Pods with larger ordinals are healthy.
+func (p *planner) planRollingUpdate() {
+	updateMin := int(getPartition(p.set))
+	for target := len(p.replicas) - 1; target >= updateMin; target-- {
+		pod := p.replicas[target]
+		if pod == nil {
+			continue
+		}
+		if getPodRevision(pod) != p.updateRevision.Name && !isTerminating(pod) {
+			// a Pod at the current revision whose template does not change, e.g. because only the ordinal overrides
+			// of other Pods changed, is moved to the update revision without being recreated.
+			if getPodRevision(pod) == p.currentRevision && podTemplateUnchanged(p.currentSet, p.updateSet, target) {
+				p.add(ActionUpdatePodRevision, ReasonUpdate, pod)
+			} else {
+				// delete the Pod if it is not already terminating and does not match the update revision.
+				p.add(ActionDeletePod, ReasonUpdate, pod)
+				return
+			}
+		}
+		// wait for unhealthy Pods on update
+		if !isHealthy(pod) {
+			p.add(ActionWait, waitingReason(pod), pod)
+			return
+		}
+	}
+}
+
+// getFrozenOrdinals returns the frozen ordinals of set, which are the ordinals of its frozen-ordinals annotation and
+// of its Pods annotated as frozen. surges are the surge Pods of set, which are never frozen.
+func getFrozenOrdinals(set *apps.StatefulSet, pods []*v1.Pod, surges []apps.StatefulSetSurge) sets.Int32 {
+	frozen := helper.GetFrozenOrdinals(set)
+	for _, pod := range pods {
+		if ord := getOrdinal(pod); ord >= 0 && !isSurgeOrdinal(surges, ord) && helper.IsFrozen(pod) {
+			frozen.Insert(int32(ord))
+		}
+	}
+	return frozen
+}
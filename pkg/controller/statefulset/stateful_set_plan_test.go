@@ -0,0 +1,327 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilpointer "k8s.io/utils/pointer"
+
+	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+)
+
+// newPlanPod returns the Pod of ordinal of set at revision in phase. Running Pods are Ready.
+func newPlanPod(set *apps.StatefulSet, ordinal int, revision string, phase v1.PodPhase) *v1.Pod {
+	pod := newStatefulSetPod(set, ordinal)
+	setPodRevision(pod, revision)
+	pod.Status.Phase = phase
+	if phase == v1.PodRunning {
+		pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+	}
+	return pod
+}
+
+func TestPlanStatefulSet(t *testing.T) {
+	set := newStatefulSet(3)
+	current := newRevisionOrDie(set, 1)
+	updated := set.DeepCopy()
+	updated.Spec.Template.Spec.Containers[0].Image = "foo:v2"
+	update := newRevisionOrDie(updated, 2)
+	ready := func(set *apps.StatefulSet, ordinals ...int) []*v1.Pod {
+		var pods []*v1.Pod
+		for _, ord := range ordinals {
+			pods = append(pods, newPlanPod(set, ord, current.Name, v1.PodRunning))
+		}
+		return pods
+	}
+	terminating := func(pod *v1.Pod) *v1.Pod {
+		now := metav1.Now()
+		pod.DeletionTimestamp = &now
+		return pod
+	}
+
+	tests := []struct {
+		name            string
+		mutate          func(set *apps.StatefulSet)
+		pods            func(set *apps.StatefulSet) []*v1.Pod
+		updateRevision  bool
+		recreateBackoff func(ordinal int) (time.Duration, bool)
+		want            []string
+	}{
+		{
+			name: "create the first missing replica",
+			pods: func(set *apps.StatefulSet) []*v1.Pod { return ready(set, 0) },
+			want: []string{"CreatePod foo-1 (Missing)"},
+		},
+		{
+			name: "create all replicas in parallel",
+			mutate: func(set *apps.StatefulSet) {
+				set.Spec.PodManagementPolicy = apps.ParallelPodManagement
+			},
+			pods: func(set *apps.StatefulSet) []*v1.Pod { return nil },
+			// the update waits for the created Pods
+			want: []string{"CreatePod foo-0 (Missing)", "CreatePod foo-1 (Missing)", "CreatePod foo-2 (Missing)", "Wait foo-2 (WaitingForReady)"},
+		},
+		{
+			name: "create replicas in batches",
+			mutate: func(set *apps.StatefulSet) {
+				set.Spec.ScaleBatchSize = utilpointer.Int32(2)
+			},
+			pods: func(set *apps.StatefulSet) []*v1.Pod { return nil },
+			want: []string{"CreatePod foo-0 (Missing)", "CreatePod foo-1 (Missing)"},
+		},
+		{
+			name: "wait for an unready replica",
+			pods: func(set *apps.StatefulSet) []*v1.Pod {
+				return append(ready(set, 0), newPlanPod(set, 1, current.Name, v1.PodPending))
+			},
+			want: []string{"Wait foo-1 (WaitingForReady)"},
+		},
+		{
+			name: "recreate a failed replica",
+			pods: func(set *apps.StatefulSet) []*v1.Pod {
+				return append(ready(set, 0, 2), newPlanPod(set, 1, current.Name, v1.PodFailed))
+			},
+			want: []string{"DeletePod foo-1 (Failed)", "CreatePod foo-1 (Missing)"},
+		},
+		{
+			name: "back off recreating a failed replica",
+			pods: func(set *apps.StatefulSet) []*v1.Pod {
+				return append(ready(set, 0, 2), newPlanPod(set, 1, current.Name, v1.PodFailed))
+			},
+			recreateBackoff: func(ordinal int) (time.Duration, bool) {
+				return time.Minute, false
+			},
+			want: []string{"Wait foo-1 (RecreateBackoff)"},
+		},
+		{
+			name: "scale in from the largest ordinal",
+			pods: func(set *apps.StatefulSet) []*v1.Pod { return ready(set, 0, 1, 2, 3, 4) },
+			want: []string{"DeletePod foo-4 (ScaleIn)"},
+		},
+		{
+			name: "wait for a scaled in Pod to terminate",
+			pods: func(set *apps.StatefulSet) []*v1.Pod {
+				return append(ready(set, 0, 1, 2, 3), terminating(newPlanPod(set, 4, current.Name, v1.PodRunning)))
+			},
+			want: []string{"Wait foo-4 (WaitingForTermination)"},
+		},
+		{
+			name: "scale in a delete slot",
+			mutate: func(set *apps.StatefulSet) {
+				set.Annotations = map[string]string{"delete-slots": "[1]"}
+			},
+			pods: func(set *apps.StatefulSet) []*v1.Pod { return ready(set, 0, 1, 2, 3) },
+			want: []string{"DeletePod foo-1 (ScaleIn)"},
+		},
+		{
+			name:           "update from the largest ordinal",
+			pods:           func(set *apps.StatefulSet) []*v1.Pod { return ready(set, 0, 1, 2) },
+			updateRevision: true,
+			want:           []string{"DeletePod foo-2 (Update)"},
+		},
+		{
+			name: "wait for an updated Pod",
+			pods: func(set *apps.StatefulSet) []*v1.Pod {
+				return append(ready(set, 0, 1), newPlanPod(set, 2, update.Name, v1.PodPending))
+			},
+			updateRevision: true,
+			// the Pending Pod blocks the replicas in monotonic mode
+			want: []string{"Wait foo-2 (WaitingForReady)"},
+		},
+		{
+			name: "do not update with OnDelete",
+			mutate: func(set *apps.StatefulSet) {
+				set.Spec.UpdateStrategy = apps.StatefulSetUpdateStrategy{Type: apps.OnDeleteStatefulSetStrategyType}
+			},
+			pods:           func(set *apps.StatefulSet) []*v1.Pod { return ready(set, 0, 1, 2) },
+			updateRevision: true,
+		},
+		{
+			name: "do not change a StatefulSet being deleted",
+			mutate: func(set *apps.StatefulSet) {
+				now := metav1.Now()
+				set.DeletionTimestamp = &now
+			},
+			pods: func(set *apps.StatefulSet) []*v1.Pod { return nil },
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			set := set.DeepCopy()
+			if test.mutate != nil {
+				test.mutate(set)
+			}
+			updateRevision := current
+			if test.updateRevision {
+				updateRevision = update
+			}
+			plan, err := PlanStatefulSet(&PlanInput{
+				Set:             set,
+				Pods:            test.pods(set),
+				CurrentRevision: current,
+				UpdateRevision:  updateRevision,
+				RecreateBackoff: test.recreateBackoff,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, action := range plan.Actions {
+				got = append(got, action.String())
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got plan %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPlanStatefulSetSurge(t *testing.T) {
+	set := newStatefulSet(3)
+	set.Spec.UpdateStrategy.RollingUpdate = &apps.RollingUpdateStatefulSetStrategy{MaxSurge: utilpointer.Int32(1)}
+	current := newRevisionOrDie(set, 1)
+	updated := set.DeepCopy()
+	updated.Spec.Template.Spec.Containers[0].Image = "foo:v2"
+	update := newRevisionOrDie(updated, 2)
+	var pods []*v1.Pod
+	for ord := 0; ord < 3; ord++ {
+		pods = append(pods, newPlanPod(set, ord, current.Name, v1.PodRunning))
+	}
+
+	plan, err := PlanStatefulSet(&PlanInput{Set: set, Pods: pods, CurrentRevision: current, UpdateRevision: update})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Actions) != 1 || plan.Actions[0].String() != "CreatePod foo-3 (Surge)" {
+		t.Fatalf("got plan %v, want the creation of surge Pod foo-3", plan.Actions)
+	}
+	want := []apps.StatefulSetSurge{{Ordinal: 3, Target: 2}}
+	if !reflect.DeepEqual(plan.Surges, want) {
+		t.Errorf("got surges %v, want %v", plan.Surges, want)
+	}
+
+	// the stale Pod is deleted once the surge Pod is Running and Ready
+	set.Status.Surges = want
+	pods = append(pods, newPlanPod(updated, 3, update.Name, v1.PodRunning))
+	plan, err = PlanStatefulSet(&PlanInput{Set: set, Pods: pods, CurrentRevision: current, UpdateRevision: update})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Actions) != 1 || plan.Actions[0].String() != "DeletePod foo-2 (SurgeReplaced)" {
+		t.Errorf("got plan %v, want the deletion of the replaced Pod foo-2", plan.Actions)
+	}
+
+	// the surge Pod and its claims are deleted once the stale Pod is replaced
+	pods[2] = newPlanPod(updated, 2, update.Name, v1.PodRunning)
+	plan, err = PlanStatefulSet(&PlanInput{Set: set, Pods: pods, CurrentRevision: current, UpdateRevision: update})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Actions) != 2 || plan.Actions[0].String() != "DeleteVolumeClaims foo-3 (SurgeRetired)" ||
+		plan.Actions[1].String() != "DeletePod foo-3 (SurgeRetired)" {
+		t.Errorf("got plan %v, want the deletion of the claims of surge Pod foo-3 and of foo-3", plan.Actions)
+	}
+}
+
+func TestPlanStatefulSetSurgeScaleOut(t *testing.T) {
+	// the partition of a rolling update with maxSurge is not defaulted by the controller
+	set := newStatefulSet(3)
+	set.Spec.UpdateStrategy.RollingUpdate = &apps.RollingUpdateStatefulSetStrategy{MaxSurge: utilpointer.Int32(1)}
+	current := newRevisionOrDie(set, 1)
+	updated := set.DeepCopy()
+	updated.Spec.Template.Spec.Containers[0].Image = "foo:v2"
+	update := newRevisionOrDie(updated, 2)
+	var pods []*v1.Pod
+	for ord := 0; ord < 3; ord++ {
+		pods = append(pods, newPlanPod(set, ord, current.Name, v1.PodRunning))
+	}
+	pods = append(pods, newPlanPod(updated, 3, update.Name, v1.PodRunning))
+	set.Status.Surges = []apps.StatefulSetSurge{{Ordinal: 3, Target: 2}}
+
+	// the surge Pod is adopted as the new replica and a new surge replaces its target
+	set.Spec.Replicas = utilpointer.Int32(4)
+	plan, err := PlanStatefulSet(&PlanInput{Set: set, Pods: pods, CurrentRevision: current, UpdateRevision: update})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Actions) != 1 || plan.Actions[0].String() != "CreatePod foo-4 (Surge)" {
+		t.Errorf("got plan %v, want the creation of surge Pod foo-4", plan.Actions)
+	}
+	want := []apps.StatefulSetSurge{{Ordinal: 4, Target: 2}}
+	if !reflect.DeepEqual(plan.Surges, want) {
+		t.Errorf("got surges %v, want %v", plan.Surges, want)
+	}
+
+	// a missing replica is created at the update revision
+	plan, err = PlanStatefulSet(&PlanInput{Set: set, Pods: pods[:3], CurrentRevision: current, UpdateRevision: update})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Actions) == 0 || plan.Actions[0].String() != "CreatePod foo-3 (Missing)" {
+		t.Fatalf("got plan %v, want the creation of foo-3", plan.Actions)
+	}
+	if revision := getPodRevision(plan.Actions[0].Pod); revision != update.Name {
+		t.Errorf("got revision %s, want %s", revision, update.Name)
+	}
+}
+
+func TestPlanStatefulSetVolumeClaimUpdates(t *testing.T) {
+	set := newStatefulSet(3)
+	set.Spec.VolumeClaimUpdateStrategy = apps.StatefulSetVolumeClaimUpdateStrategy{Type: apps.RecreateVolumeClaimUpdateStrategyType}
+	revision := newRevisionOrDie(set, 1)
+	var pods []*v1.Pod
+	claims := make(map[string]map[string]*v1.PersistentVolumeClaim)
+	for ord := 0; ord < 3; ord++ {
+		pod := newPlanPod(set, ord, revision.Name, v1.PodRunning)
+		pods = append(pods, pod)
+		claims[pod.Name] = make(map[string]*v1.PersistentVolumeClaim)
+		for name, claim := range getPersistentVolumeClaims(set, pod) {
+			claim := claim
+			claims[pod.Name][name] = &claim
+		}
+	}
+	// the claims are smaller than their template
+	set.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests = v1.ResourceList{v1.ResourceStorage: resource.MustParse("2Gi")}
+
+	for _, enabled := range []bool{false, true} {
+		plan, err := PlanStatefulSet(&PlanInput{
+			Set:                set,
+			Pods:               pods,
+			CurrentRevision:    revision,
+			UpdateRevision:     revision,
+			VolumeClaims:       claims,
+			VolumeClaimUpdates: enabled,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, action := range plan.Actions {
+			got = append(got, action.String())
+		}
+		var want []string
+		if enabled {
+			want = []string{"DeleteVolumeClaims foo-2 (VolumeClaimRecreate)", "DeletePod foo-2 (VolumeClaimRecreate)"}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("volume claim updates enabled %v: got plan %v, want %v", enabled, got, want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+	"github.com/pingcap/advanced-statefulset/pkg/features"
+)
+
+const (
+	// rollingUpdateProgressingReason is added to the Progressing condition when a rolling update has started or
+	// made progress.
+	rollingUpdateProgressingReason = "RollingUpdateProgressing"
+	// rolloutCompleteReason is added to the Progressing condition when no rolling update is in flight or all replicas
+	// are updated and ready.
+	rolloutCompleteReason = "RolloutComplete"
+	// progressDeadlineExceededReason is added to the Progressing condition and recorded as an event when a rolling
+	// update did not make progress within spec.progressDeadlineSeconds.
+	progressDeadlineExceededReason = "ProgressDeadlineExceeded"
+)
+
+// rolloutComplete returns true if set has its desired number of replicas, all of them are ready and all replicas
+// which are subject to the update strategy run the update revision.
+func rolloutComplete(set *apps.StatefulSet, status *apps.StatefulSetStatus) bool {
+	if status.Replicas != *set.Spec.Replicas || status.ReadyReplicas != status.Replicas {
+		return false
+	}
+	if set.Spec.UpdateStrategy.Type != apps.RollingUpdateStatefulSetStrategyType {
+		return true
+	}
+	return status.UpdatedReplicas >= replicasToUpdate(set)
+}
+
+// rolloutInProgress returns true if a rolling update of set is in flight, which is when its update revision differs
+// from its current revision or fewer replicas than are subject to the update strategy run the update revision.
+func rolloutInProgress(set *apps.StatefulSet, status *apps.StatefulSetStatus) bool {
+	if set.Spec.UpdateStrategy.Type != apps.RollingUpdateStatefulSetStrategyType {
+		return false
+	}
+	return status.UpdateRevision != status.CurrentRevision || status.UpdatedReplicas < replicasToUpdate(set)
+}
+
+// replicasToUpdate returns the number of replicas of set at or beyond the partition of its rolling update.
+func replicasToUpdate(set *apps.StatefulSet) int32 {
+	partition := getPartition(set)
+	toUpdate := int32(0)
+	for ord := range helper.GetPodOrdinals(*set.Spec.Replicas, set) {
+		if ord >= partition {
+			toUpdate++
+		}
+	}
+	return toUpdate
+}
+
+// hasProgressed returns true if status shows progress compared to the last recorded status of set.
+func hasProgressed(set *apps.StatefulSet, status *apps.StatefulSetStatus) bool {
+	return status.UpdateRevision != set.Status.UpdateRevision ||
+		status.UpdatedReplicas > set.Status.UpdatedReplicas ||
+		status.ReadyReplicas > set.Status.ReadyReplicas
+}
+
+// updateProgressingCondition maintains the Progressing condition of status if set has a progress deadline. The
+// condition is RolloutComplete unless a rolling update is in flight. The returned bool is true if the progress deadline has been exceeded in this call.
+func updateProgressingCondition(set *apps.StatefulSet, status *apps.StatefulSetStatus, now time.Time) bool {
+	if set.Spec.ProgressDeadlineSeconds == nil {
+		status.Conditions = filterOutCondition(status.Conditions, apps.StatefulSetProgressing)
+		return false
+	}
+	cond := getStatefulSetCondition(*status, apps.StatefulSetProgressing)
+	// progress is only tracked while a rolling update is in flight, an unready replica alone does not time out
+	if !rolloutInProgress(set, status) || rolloutComplete(set, status) {
+		setStatefulSetCondition(status, newStatefulSetCondition(apps.StatefulSetProgressing, v1.ConditionTrue,
+			rolloutCompleteReason, fmt.Sprintf("StatefulSet %s/%s has successfully progressed", set.Namespace, set.Name)))
+		return false
+	}
+	if cond == nil || cond.Reason == rolloutCompleteReason || hasProgressed(set, status) {
+		// the condition is always replaced so that its last update time records the latest progress
+		progressing := newStatefulSetCondition(apps.StatefulSetProgressing, v1.ConditionTrue,
+			rollingUpdateProgressingReason, fmt.Sprintf("StatefulSet %s/%s is progressing to revision %s",
+				set.Namespace, set.Name, status.UpdateRevision))
+		if cond != nil && cond.Status == v1.ConditionTrue {
+			progressing.LastTransitionTime = cond.LastTransitionTime
+		}
+		status.Conditions = filterOutCondition(status.Conditions, apps.StatefulSetProgressing)
+		setStatefulSetCondition(status, progressing)
+		return false
+	}
+	if cond.Status != v1.ConditionTrue {
+		return false
+	}
+	deadline := time.Duration(*set.Spec.ProgressDeadlineSeconds) * time.Second
+	if cond.LastUpdateTime.Add(deadline).After(now) {
+		return false
+	}
+	setStatefulSetCondition(status, newStatefulSetCondition(apps.StatefulSetProgressing, v1.ConditionFalse,
+		progressDeadlineExceededReason, fmt.Sprintf("StatefulSet %s/%s has timed out progressing to revision %s",
+			set.Namespace, set.Name, status.UpdateRevision)))
+	return true
+}
+
+// progressDeadlineRequeueAfter returns the duration after which set needs to be synced again to check whether its
+// rolling update exceeded the progress deadline. Zero is returned if no check is needed.
+func progressDeadlineRequeueAfter(set *apps.StatefulSet, status *apps.StatefulSetStatus, now time.Time) time.Duration {
+	if set.Spec.ProgressDeadlineSeconds == nil {
+		return 0
+	}
+	cond := getStatefulSetCondition(*status, apps.StatefulSetProgressing)
+	if cond == nil || cond.Status != v1.ConditionTrue || cond.Reason != rollingUpdateProgressingReason {
+		return 0
+	}
+	deadline := time.Duration(*set.Spec.ProgressDeadlineSeconds) * time.Second
+	after := cond.LastUpdateTime.Add(deadline).Sub(now)
+	if after < 0 {
+		after = 0
+	}
+	// add a second to avoid a sync right before the deadline
+	return after + time.Second
+}
+
+// needsAutoRollback returns true if set should be rolled back to its current revision because its rolling update
+// exceeded the progress deadline.
+func needsAutoRollback(set *apps.StatefulSet, status *apps.StatefulSetStatus) bool {
+	if !set.Spec.AutoRollback || set.Spec.ProgressDeadlineSeconds == nil ||
+		!features.DefaultFeatureGate.Enabled(features.AutomaticRollback) {
+		return false
+	}
+	if status.CurrentRevision == status.UpdateRevision {
+		return false
+	}
+	if _, ok := helper.GetRollbackTo(set); ok {
+		return false
+	}
+	cond := getStatefulSetCondition(*status, apps.StatefulSetProgressing)
+	return cond != nil && cond.Status == v1.ConditionFalse && cond.Reason == progressDeadlineExceededReason
+}
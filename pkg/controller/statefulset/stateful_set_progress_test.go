@@ -0,0 +1,206 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilpointer "k8s.io/utils/pointer"
+
+	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+)
+
+func TestUpdateProgressingCondition(t *testing.T) {
+	now := time.Now()
+	progressing := func(updated time.Duration) *apps.StatefulSetCondition {
+		cond := newStatefulSetCondition(apps.StatefulSetProgressing, v1.ConditionTrue, rollingUpdateProgressingReason, "")
+		cond.LastUpdateTime = metav1.NewTime(now.Add(-updated))
+		return &cond
+	}
+	inProgress := apps.StatefulSetStatus{
+		Replicas:        3,
+		ReadyReplicas:   2,
+		UpdatedReplicas: 1,
+		CurrentRevision: "foo-1",
+		UpdateRevision:  "foo-2",
+	}
+	tests := []struct {
+		name         string
+		deadline     *int32
+		lastStatus   apps.StatefulSetStatus
+		status       apps.StatefulSetStatus
+		cond         *apps.StatefulSetCondition
+		wantCond     *apps.StatefulSetCondition
+		wantExceeded bool
+		wantRequeue  bool
+	}{
+		{
+			name:       "no deadline",
+			lastStatus: inProgress,
+			status:     inProgress,
+			cond:       progressing(0),
+		},
+		{
+			name:       "rollout complete",
+			deadline:   utilpointer.Int32Ptr(60),
+			lastStatus: inProgress,
+			status: apps.StatefulSetStatus{
+				Replicas:        3,
+				ReadyReplicas:   3,
+				UpdatedReplicas: 3,
+				CurrentRevision: "foo-2",
+				UpdateRevision:  "foo-2",
+			},
+			cond:     progressing(0),
+			wantCond: &apps.StatefulSetCondition{Status: v1.ConditionTrue, Reason: rolloutCompleteReason},
+		},
+		{
+			name:     "rollout started",
+			deadline: utilpointer.Int32Ptr(60),
+			lastStatus: apps.StatefulSetStatus{
+				Replicas:        3,
+				ReadyReplicas:   3,
+				UpdatedReplicas: 3,
+				CurrentRevision: "foo-1",
+				UpdateRevision:  "foo-1",
+			},
+			status:      inProgress,
+			wantCond:    &apps.StatefulSetCondition{Status: v1.ConditionTrue, Reason: rollingUpdateProgressingReason},
+			wantRequeue: true,
+		},
+		{
+			name:        "no progress within deadline",
+			deadline:    utilpointer.Int32Ptr(60),
+			lastStatus:  inProgress,
+			status:      inProgress,
+			cond:        progressing(30 * time.Second),
+			wantCond:    &apps.StatefulSetCondition{Status: v1.ConditionTrue, Reason: rollingUpdateProgressingReason},
+			wantRequeue: true,
+		},
+		{
+			name:         "no progress after deadline",
+			deadline:     utilpointer.Int32Ptr(60),
+			lastStatus:   inProgress,
+			status:       inProgress,
+			cond:         progressing(90 * time.Second),
+			wantCond:     &apps.StatefulSetCondition{Status: v1.ConditionFalse, Reason: progressDeadlineExceededReason},
+			wantExceeded: true,
+		},
+		{
+			name:       "progress after deadline",
+			deadline:   utilpointer.Int32Ptr(60),
+			lastStatus: inProgress,
+			status: apps.StatefulSetStatus{
+				Replicas:        3,
+				ReadyReplicas:   3,
+				UpdatedReplicas: 1,
+				CurrentRevision: "foo-1",
+				UpdateRevision:  "foo-2",
+			},
+			cond:        progressing(90 * time.Second),
+			wantCond:    &apps.StatefulSetCondition{Status: v1.ConditionTrue, Reason: rollingUpdateProgressingReason},
+			wantRequeue: true,
+		},
+		{
+			name:     "unready replica without update",
+			deadline: utilpointer.Int32Ptr(60),
+			lastStatus: apps.StatefulSetStatus{
+				Replicas:        3,
+				ReadyReplicas:   3,
+				UpdatedReplicas: 3,
+				CurrentRevision: "foo-2",
+				UpdateRevision:  "foo-2",
+			},
+			status: apps.StatefulSetStatus{
+				Replicas:        3,
+				ReadyReplicas:   2,
+				UpdatedReplicas: 3,
+				CurrentRevision: "foo-2",
+				UpdateRevision:  "foo-2",
+			},
+			cond:     progressing(90 * time.Second),
+			wantCond: &apps.StatefulSetCondition{Status: v1.ConditionTrue, Reason: rolloutCompleteReason},
+		},
+		{
+			name:     "updated replica unready",
+			deadline: utilpointer.Int32Ptr(60),
+			lastStatus: apps.StatefulSetStatus{
+				Replicas:        3,
+				ReadyReplicas:   2,
+				UpdatedReplicas: 3,
+				CurrentRevision: "foo-1",
+				UpdateRevision:  "foo-2",
+			},
+			status: apps.StatefulSetStatus{
+				Replicas:        3,
+				ReadyReplicas:   2,
+				UpdatedReplicas: 3,
+				CurrentRevision: "foo-1",
+				UpdateRevision:  "foo-2",
+			},
+			cond:         progressing(90 * time.Second),
+			wantCond:     &apps.StatefulSetCondition{Status: v1.ConditionFalse, Reason: progressDeadlineExceededReason},
+			wantExceeded: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := newStatefulSet(3)
+			set.Spec.ProgressDeadlineSeconds = tt.deadline
+			set.Status = tt.lastStatus
+			status := tt.status.DeepCopy()
+			if tt.cond != nil {
+				setStatefulSetCondition(status, *tt.cond)
+			}
+			if exceeded := updateProgressingCondition(set, status, now); exceeded != tt.wantExceeded {
+				t.Errorf("got exceeded %v, want %v", exceeded, tt.wantExceeded)
+			}
+			cond := getStatefulSetCondition(*status, apps.StatefulSetProgressing)
+			if tt.wantCond == nil {
+				if cond != nil {
+					t.Errorf("got condition %v, want none", cond)
+				}
+			} else if cond == nil || cond.Status != tt.wantCond.Status || cond.Reason != tt.wantCond.Reason {
+				t.Errorf("got condition %v, want %s/%s", cond, tt.wantCond.Status, tt.wantCond.Reason)
+			}
+			if requeue := progressDeadlineRequeueAfter(set, status, now) > 0; requeue != tt.wantRequeue {
+				t.Errorf("got requeue %v, want %v", requeue, tt.wantRequeue)
+			}
+		})
+	}
+}
+
+func TestNeedsAutoRollback(t *testing.T) {
+	set := newStatefulSet(3)
+	set.Spec.ProgressDeadlineSeconds = utilpointer.Int32Ptr(60)
+	status := &apps.StatefulSetStatus{
+		CurrentRevision: "foo-1",
+		UpdateRevision:  "foo-2",
+	}
+	setStatefulSetCondition(status, newStatefulSetCondition(apps.StatefulSetProgressing, v1.ConditionFalse, progressDeadlineExceededReason, ""))
+	if needsAutoRollback(set, status) {
+		t.Errorf("set without autoRollback should not be rolled back")
+	}
+	set.Spec.AutoRollback = true
+	if !needsAutoRollback(set, status) {
+		t.Errorf("set with autoRollback should be rolled back")
+	}
+	status.UpdateRevision = status.CurrentRevision
+	if needsAutoRollback(set, status) {
+		t.Errorf("set without rolling update should not be rolled back")
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// QueueConfig configures the work queue of the StatefulSets.
+type QueueConfig struct {
+	// BaseDelay is the delay of the first retry of a StatefulSet whose sync failed, it is doubled on each failure.
+	BaseDelay time.Duration
+	// MaxDelay is the maximum delay of the retries of a StatefulSet.
+	MaxDelay time.Duration
+	// QPS is the rate of the retries of all StatefulSets.
+	QPS float64
+	// Burst is the number of retries of all StatefulSets allowed above QPS.
+	Burst int
+	// Fair dispatches the StatefulSets to the workers in a round robin across namespaces, so that the StatefulSets
+	// of a namespace cannot starve the other namespaces. Otherwise, they are dispatched in the order they are
+	// queued.
+	Fair bool
+}
+
+// DefaultQueueConfig returns the QueueConfig of the default rate limiter of controllers, with fair dispatching.
+func DefaultQueueConfig() QueueConfig {
+	return QueueConfig{
+		BaseDelay: 5 * time.Millisecond,
+		MaxDelay:  1000 * time.Second,
+		QPS:       10,
+		Burst:     100,
+		Fair:      true,
+	}
+}
+
+// newStatefulSetQueue creates the work queue of the StatefulSets from config.
+func newStatefulSetQueue(config QueueConfig) workqueue.RateLimitingInterface {
+	rateLimiter := workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(config.BaseDelay, config.MaxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(config.QPS), config.Burst)},
+	)
+	var queue workqueue.Interface
+	if config.Fair {
+		queue = newFairQueue()
+	}
+	return workqueue.NewRateLimitingQueueWithConfig(rateLimiter, workqueue.RateLimitingQueueConfig{
+		Name: "statefulset",
+		DelayingQueue: workqueue.NewDelayingQueueWithConfig(workqueue.DelayingQueueConfig{
+			Name:  "statefulset",
+			Queue: queue,
+		}),
+	})
+}
+
+// fairQueue is a workqueue.Interface which hands out the queued keys in a round robin across their namespaces, and in
+// the order they are queued within a namespace. As with the queue of client-go, a key is queued at most once and is
+// never processed concurrently, a key added while it is processed is queued again once it is done.
+type fairQueue struct {
+	cond *sync.Cond
+
+	// queues are the queued keys by namespace.
+	queues map[string][]interface{}
+	// namespaces are the namespaces with queued keys, in the order they are handed out.
+	namespaces []string
+	// dirty are the keys which need to be processed.
+	dirty map[interface{}]struct{}
+	// processing are the keys being processed.
+	processing map[interface{}]struct{}
+
+	shuttingDown bool
+	drain        bool
+}
+
+var _ workqueue.Interface = &fairQueue{}
+
+func newFairQueue() *fairQueue {
+	return &fairQueue{
+		cond:       sync.NewCond(&sync.Mutex{}),
+		queues:     make(map[string][]interface{}),
+		dirty:      make(map[interface{}]struct{}),
+		processing: make(map[interface{}]struct{}),
+	}
+}
+
+// queueNamespace returns the namespace of a namespace/name key.
+func queueNamespace(item interface{}) string {
+	key, _ := item.(string)
+	if i := strings.Index(key, "/"); i >= 0 {
+		return key[:i]
+	}
+	return ""
+}
+
+// push queues item after the other keys of its namespace.
+func (q *fairQueue) push(item interface{}) {
+	namespace := queueNamespace(item)
+	if len(q.queues[namespace]) == 0 {
+		q.namespaces = append(q.namespaces, namespace)
+	}
+	q.queues[namespace] = append(q.queues[namespace], item)
+}
+
+func (q *fairQueue) Add(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if q.shuttingDown {
+		return
+	}
+	if _, ok := q.dirty[item]; ok {
+		return
+	}
+	q.dirty[item] = struct{}{}
+	if _, ok := q.processing[item]; ok {
+		return
+	}
+	q.push(item)
+	q.cond.Signal()
+}
+
+func (q *fairQueue) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	n := 0
+	for _, queue := range q.queues {
+		n += len(queue)
+	}
+	return n
+}
+
+// Get hands out the first key of the next namespace, the namespace is then moved to the end of the round robin.
+func (q *fairQueue) Get() (interface{}, bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	for len(q.namespaces) == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+	if len(q.namespaces) == 0 {
+		return nil, true
+	}
+	namespace := q.namespaces[0]
+	q.namespaces = q.namespaces[1:]
+	queue := q.queues[namespace]
+	item := queue[0]
+	queue[0] = nil
+	if len(queue) > 1 {
+		q.queues[namespace] = queue[1:]
+		q.namespaces = append(q.namespaces, namespace)
+	} else {
+		delete(q.queues, namespace)
+	}
+	q.processing[item] = struct{}{}
+	delete(q.dirty, item)
+	return item, false
+}
+
+func (q *fairQueue) Done(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	delete(q.processing, item)
+	if _, ok := q.dirty[item]; ok {
+		q.push(item)
+		q.cond.Signal()
+	} else if len(q.processing) == 0 {
+		// wake up ShutDownWithDrain
+		q.cond.Broadcast()
+	}
+}
+
+func (q *fairQueue) ShutDown() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.drain = false
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
+
+// ShutDownWithDrain shuts down the queue and waits for the keys being processed to be done.
+func (q *fairQueue) ShutDownWithDrain() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.drain = true
+	q.shuttingDown = true
+	q.cond.Broadcast()
+	for len(q.processing) != 0 && q.drain {
+		q.cond.Wait()
+	}
+}
+
+func (q *fairQueue) ShuttingDown() bool {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.shuttingDown
+}
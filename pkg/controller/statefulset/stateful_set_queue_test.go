@@ -0,0 +1,97 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFairQueue(t *testing.T) {
+	q := newFairQueue()
+	for _, key := range []string{"a/1", "a/2", "a/3", "b/1", "a/1", "c/1"} {
+		q.Add(key)
+	}
+	if q.Len() != 5 {
+		t.Errorf("got %d queued keys, want 5 as keys are queued once", q.Len())
+	}
+	get := func() string {
+		item, shutdown := q.Get()
+		if shutdown {
+			t.Fatalf("queue should not be shut down")
+		}
+		return item.(string)
+	}
+
+	var got []string
+	got = append(got, get())
+	// a key added while it is processed is queued again once it is done
+	q.Add("a/1")
+	for i := 0; i < 4; i++ {
+		got = append(got, get())
+	}
+	if q.Len() != 0 {
+		t.Errorf("a key being processed should not be queued, got %d queued keys", q.Len())
+	}
+	q.Done("a/1")
+	got = append(got, get())
+	want := []string{"a/1", "b/1", "c/1", "a/2", "a/3", "a/1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got keys %v, want %v", got, want)
+	}
+
+	// shutting down with drain waits for the keys being processed
+	drained := make(chan struct{})
+	go func() {
+		q.ShutDownWithDrain()
+		close(drained)
+	}()
+	for _, key := range []string{"b/1", "c/1", "a/2", "a/3"} {
+		q.Done(key)
+	}
+	select {
+	case <-drained:
+		t.Fatalf("queue should not be drained while a/1 is processed")
+	case <-time.After(100 * time.Millisecond):
+	}
+	q.Done("a/1")
+	<-drained
+	if _, shutdown := q.Get(); !shutdown {
+		t.Errorf("queue should be shut down")
+	}
+}
+
+func TestStatefulSetQueueRateLimiting(t *testing.T) {
+	config := DefaultQueueConfig()
+	config.BaseDelay = time.Millisecond
+	config.MaxDelay = 2 * time.Millisecond
+	q := newStatefulSetQueue(config)
+	defer q.ShutDown()
+	for i := 0; i < 3; i++ {
+		q.AddRateLimited("a/1")
+	}
+	if n := q.NumRequeues("a/1"); n != 3 {
+		t.Errorf("got %d requeues, want 3", n)
+	}
+	item, _ := q.Get()
+	if item != "a/1" {
+		t.Errorf("got %v, want a/1", item)
+	}
+	q.Forget(item)
+	q.Done(item)
+	if n := q.NumRequeues("a/1"); n != 0 {
+		t.Errorf("got %d requeues after forget, want 0", n)
+	}
+}
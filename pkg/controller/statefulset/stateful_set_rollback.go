@@ -0,0 +1,124 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	kubeapps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+)
+
+const (
+	// rollbackStartedReason is added to the Rollback condition and recorded as an event when the pod template of a
+	// set has been restored from a previous revision.
+	rollbackStartedReason = "RollbackStarted"
+	// rollbackCompleteReason is added to the Rollback condition when all replicas run the restored revision.
+	rollbackCompleteReason = "RollbackComplete"
+	// rollbackRevisionNotFoundReason is added to the Rollback condition and recorded as an event when the requested
+	// revision does not exist.
+	rollbackRevisionNotFoundReason = "RollbackRevisionNotFound"
+)
+
+// findRevision returns the revision in revisions named target. If no revision has that name and target is a number,
+// the revision with that Revision number is returned. nil is returned if no revision matches.
+func findRevision(revisions []*kubeapps.ControllerRevision, target string) *kubeapps.ControllerRevision {
+	for i := range revisions {
+		if revisions[i].Name == target {
+			return revisions[i]
+		}
+	}
+	number, err := strconv.ParseInt(target, 10, 64)
+	if err != nil {
+		return nil
+	}
+	for i := range revisions {
+		if revisions[i].Revision == number {
+			return revisions[i]
+		}
+	}
+	return nil
+}
+
+// blocksRollback returns true if pod blocks the rollback of set. During a rollback the update revision is a revision
+// that is known to work, Pods which are not Running and Ready at another revision are replaced instead of waiting for
+// them to become Running and Ready.
+func blocksRollback(set *apps.StatefulSet, pod *v1.Pod, updateRevision *kubeapps.ControllerRevision) bool {
+	if set.Spec.UpdateStrategy.Type != apps.RollingUpdateStatefulSetStrategyType {
+		return false
+	}
+	cond := getStatefulSetCondition(set.Status, apps.StatefulSetRollback)
+	if cond == nil || cond.Status != v1.ConditionTrue {
+		return false
+	}
+	return isCreated(pod) && !isTerminating(pod) && !isRunningAndReady(pod) &&
+		getPodRevision(pod) != updateRevision.Name && int32(getOrdinal(pod)) >= getPartition(set)
+}
+
+// rollback handles a rollback requested with the rollback-to annotation. The pod template of set is restored from the
+// requested ControllerRevision and the annotation is removed in the same update, so the next sync starts a normal
+// rolling update to the restored template. The Rollback condition is written first, so that it is not lost once the
+// annotation is removed. The returned bool is true if set has been updated and the sync should be stopped until the
+// update is observed.
+func (ssc *StatefulSetController) rollback(set *apps.StatefulSet) (bool, error) {
+	target, ok := helper.GetRollbackTo(set)
+	if !ok {
+		return false, nil
+	}
+	revisions, err := ssc.control.ListRevisions(set)
+	if err != nil {
+		return false, err
+	}
+
+	var condition apps.StatefulSetCondition
+	var restored *apps.StatefulSet
+	eventType := v1.EventTypeNormal
+	revision := findRevision(revisions, target)
+	if revision == nil {
+		eventType = v1.EventTypeWarning
+		condition = newStatefulSetCondition(apps.StatefulSetRollback, v1.ConditionFalse, rollbackRevisionNotFoundReason,
+			fmt.Sprintf("Unable to find revision %q to roll back to", target))
+	} else {
+		if restored, err = ApplyRevision(set, revision); err != nil {
+			return false, err
+		}
+		condition = newStatefulSetCondition(apps.StatefulSetRollback, v1.ConditionTrue, rollbackStartedReason,
+			fmt.Sprintf("Rolling back to revision %s (%d)", revision.Name, revision.Revision))
+	}
+
+	klog.V(2).Infof("StatefulSet %s/%s rollback to %q: %s", set.Namespace, set.Name, target, condition.Message)
+	clone := set.DeepCopy()
+	setStatefulSetCondition(&clone.Status, condition)
+	clone, err = ssc.pcClient.AppsV1().StatefulSets(set.Namespace).UpdateStatus(context.TODO(), clone, metav1.UpdateOptions{})
+	if err != nil {
+		return false, err
+	}
+	helper.ClearRollbackTo(clone)
+	if restored != nil {
+		clone.Spec.Template = restored.Spec.Template
+		clone.Spec.OrdinalOverrides = restored.Spec.OrdinalOverrides
+	}
+	if _, err := ssc.pcClient.AppsV1().StatefulSets(set.Namespace).Update(context.TODO(), clone, metav1.UpdateOptions{}); err != nil {
+		return false, err
+	}
+	ssc.recorder.Event(set, eventType, condition.Reason, condition.Message)
+	return true, nil
+}
@@ -0,0 +1,270 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+
+	pcfake "github.com/pingcap/advanced-statefulset/client/client/clientset/versioned/fake"
+
+	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
+)
+
+func TestStatefulSetControllerRollback(t *testing.T) {
+	tests := []struct {
+		name       string
+		rollbackTo string
+		wantImage  string
+		wantStatus v1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "by revision name",
+			rollbackTo: "",
+			wantImage:  "foo:v1",
+			wantStatus: v1.ConditionTrue,
+			wantReason: rollbackStartedReason,
+		},
+		{
+			name:       "by revision number",
+			rollbackTo: "1",
+			wantImage:  "foo:v1",
+			wantStatus: v1.ConditionTrue,
+			wantReason: rollbackStartedReason,
+		},
+		{
+			name:       "revision not found",
+			rollbackTo: "5",
+			wantImage:  "foo:v2",
+			wantStatus: v1.ConditionFalse,
+			wantReason: rollbackRevisionNotFoundReason,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := newStatefulSet(3)
+			set.Spec.Template.Spec.Containers[0].Image = "foo:v1"
+			revision, err := newRevision(set, 1, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			revision.Namespace = set.Namespace
+			set.Spec.Template.Spec.Containers[0].Image = "foo:v2"
+			rollbackTo := tt.rollbackTo
+			if rollbackTo == "" {
+				rollbackTo = revision.Name
+			}
+			helper.SetRollbackTo(set, rollbackTo)
+
+			ssc, spc := newFakeStatefulSetController([]runtime.Object{set, revision}...)
+			spc.setsIndexer.Add(set)
+			if err := ssc.sync(set.Namespace + "/" + set.Name); err != nil {
+				t.Fatalf("sync: %v", err)
+			}
+
+			got, err := ssc.pcClient.AppsV1().StatefulSets(set.Namespace).Get(context.TODO(), set.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := helper.GetRollbackTo(got); ok {
+				t.Errorf("rollback-to annotation should be removed")
+			}
+			if image := got.Spec.Template.Spec.Containers[0].Image; image != tt.wantImage {
+				t.Errorf("got image %q, want %q", image, tt.wantImage)
+			}
+			cond := getStatefulSetCondition(got.Status, apps.StatefulSetRollback)
+			if cond == nil {
+				t.Fatalf("Rollback condition should be set")
+			}
+			if cond.Status != tt.wantStatus || cond.Reason != tt.wantReason {
+				t.Errorf("got condition %s/%s, want %s/%s", cond.Status, cond.Reason, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestStatefulSetControllerRollbackUpdateFailure(t *testing.T) {
+	for name, subresource := range map[string]string{"status update fails": "status", "update fails": ""} {
+		t.Run(name, func(t *testing.T) {
+			set := newStatefulSet(3)
+			set.Spec.Template.Spec.Containers[0].Image = "foo:v1"
+			revision, err := newRevision(set, 1, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			revision.Namespace = set.Namespace
+			set.Spec.Template.Spec.Containers[0].Image = "foo:v2"
+			helper.SetRollbackTo(set, revision.Name)
+
+			ssc, spc := newFakeStatefulSetController([]runtime.Object{set, revision}...)
+			recorder := record.NewFakeRecorder(10)
+			ssc.recorder = recorder
+			spc.setsIndexer.Add(set)
+			failed := false
+			ssc.pcClient.(*pcfake.Clientset).PrependReactor("update", "statefulsets", func(action core.Action) (bool, runtime.Object, error) {
+				if failed || action.GetSubresource() != subresource {
+					return false, nil, nil
+				}
+				failed = true
+				return true, nil, apierrors.NewInternalError(errors.New("API server failed"))
+			})
+			if err := ssc.sync(statefulSetKey(set)); err == nil {
+				t.Fatalf("sync should fail")
+			}
+			if len(recorder.Events) != 0 {
+				t.Errorf("got event %q for a failed rollback", <-recorder.Events)
+			}
+			got, err := ssc.pcClient.AppsV1().StatefulSets(set.Namespace).Get(context.TODO(), set.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := helper.GetRollbackTo(got); !ok {
+				t.Fatalf("rollback-to annotation should be kept until the rollback is done")
+			}
+
+			// the rollback is retried by the next sync
+			if err := ssc.sync(statefulSetKey(set)); err != nil {
+				t.Fatalf("sync: %v", err)
+			}
+			if got, err = ssc.pcClient.AppsV1().StatefulSets(set.Namespace).Get(context.TODO(), set.Name, metav1.GetOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := helper.GetRollbackTo(got); ok {
+				t.Errorf("rollback-to annotation should be removed")
+			}
+			if image := got.Spec.Template.Spec.Containers[0].Image; image != "foo:v1" {
+				t.Errorf("got image %q, want foo:v1", image)
+			}
+			if cond := getStatefulSetCondition(got.Status, apps.StatefulSetRollback); cond == nil || cond.Reason != rollbackStartedReason {
+				t.Errorf("got condition %v, want %s", cond, rollbackStartedReason)
+			}
+			if len(recorder.Events) != 1 || !strings.Contains(<-recorder.Events, rollbackStartedReason) {
+				t.Errorf("want a single %s event", rollbackStartedReason)
+			}
+		})
+	}
+}
+
+func TestCompleteRollback(t *testing.T) {
+	status := &apps.StatefulSetStatus{
+		Replicas:        3,
+		ReadyReplicas:   3,
+		UpdatedReplicas: 2,
+		CurrentRevision: "foo-1",
+		UpdateRevision:  "foo-2",
+	}
+	setStatefulSetCondition(status, newStatefulSetCondition(apps.StatefulSetRollback, v1.ConditionTrue, rollbackStartedReason, ""))
+	completeRollback(status)
+	if cond := getStatefulSetCondition(*status, apps.StatefulSetRollback); cond.Status != v1.ConditionTrue {
+		t.Errorf("rollback should be in progress, got condition %s", cond.Status)
+	}
+	status.UpdatedReplicas = 3
+	status.CurrentRevision = status.UpdateRevision
+	completeRollback(status)
+	if cond := getStatefulSetCondition(*status, apps.StatefulSetRollback); cond.Status != v1.ConditionFalse || cond.Reason != rollbackCompleteReason {
+		t.Errorf("rollback should be complete, got condition %s/%s", cond.Status, cond.Reason)
+	}
+}
+
+func TestStatefulSetControlRollbackReplacesUnreadyPods(t *testing.T) {
+	for _, rollingBack := range []bool{false, true} {
+		set := newStatefulSet(3)
+		spc, _, ssc, stop := setupController(pcfake.NewSimpleClientset(set), fake.NewSimpleClientset())
+		defer close(stop)
+		if err := scaleUpStatefulSetControl(set, ssc, spc, assertMonotonicInvariants); err != nil {
+			t.Fatal(err)
+		}
+		selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		set, err = spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		revisions, err := ssc.ListRevisions(set)
+		if err != nil {
+			t.Fatal(err)
+		}
+		goodSet, err := ApplyRevision(set, revisions[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// update to a revision whose pods never become ready
+		for i := 0; i < 2; i++ {
+			set, err = spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			set = set.DeepCopy()
+			set.Spec.Template.Spec.Containers[0].Image = "bad"
+			pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ssc.UpdateStatefulSet(set, pods); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := spc.setPodPending(set, 2); err != nil {
+			t.Fatal(err)
+		}
+		set, err = spc.setsLister.StatefulSets(set.Namespace).Get(set.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		badRevision := set.Status.UpdateRevision
+
+		// roll back to the previous template
+		set = set.DeepCopy()
+		set.Spec.Template = goodSet.Spec.Template
+		if rollingBack {
+			setStatefulSetCondition(&set.Status, newStatefulSetCondition(apps.StatefulSetRollback, v1.ConditionTrue, rollbackStartedReason, ""))
+		}
+		pods, err := spc.podsLister.Pods(set.Namespace).List(selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		status, err := ssc.UpdateStatefulSet(set, pods)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pods, err = spc.podsLister.Pods(set.Namespace).List(selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Sort(ascendingOrdinal(pods))
+		revision := getPodRevision(pods[2])
+		if rollingBack && revision != status.UpdateRevision {
+			t.Errorf("rolling back: got pod revision %s, want %s", revision, status.UpdateRevision)
+		}
+		if !rollingBack && revision != badRevision {
+			t.Errorf("not rolling back: got pod revision %s, want %s", revision, badRevision)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+)
+
+// getMaxSurge returns the maximum number of surge Pods of set. Zero is returned if set does not use a rolling update
+// with maxSurge.
+func getMaxSurge(set *apps.StatefulSet) int {
+	if set.Spec.UpdateStrategy.Type != apps.RollingUpdateStatefulSetStrategyType ||
+		set.Spec.UpdateStrategy.RollingUpdate == nil ||
+		set.Spec.UpdateStrategy.RollingUpdate.MaxSurge == nil {
+		return 0
+	}
+	return int(*set.Spec.UpdateStrategy.RollingUpdate.MaxSurge)
+}
+
+// isSurgeOrdinal returns true if ordinal is the ordinal of one of surges.
+func isSurgeOrdinal(surges []apps.StatefulSetSurge, ordinal int) bool {
+	for i := range surges {
+		if int(surges[i].Ordinal) == ordinal {
+			return true
+		}
+	}
+	return false
+}
+
+// newSurgePod returns a new Pod with ordinal at the update revision which replaces the Pod with target. It is created
+// from the pod template of target.
+func newSurgePod(updateSet *apps.StatefulSet, updateRevision string, ordinal, target int) *v1.Pod {
+	pod := newStatefulSetPod(updateSet, target)
+	pod.Name = getPodName(updateSet, ordinal)
+	initIdentity(updateSet, pod)
+	updateStorage(updateSet, pod)
+	setPodRevision(pod, updateRevision)
+	return pod
+}
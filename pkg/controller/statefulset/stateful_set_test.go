@@ -17,15 +17,20 @@ limitations under the License.
 package statefulset
 
 import (
+	"fmt"
 	"sort"
 	"testing"
+	"time"
 
 	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
 	"github.com/pingcap/advanced-statefulset/client/client/clientset/versioned/fake"
 	informers "github.com/pingcap/advanced-statefulset/client/client/informers/externalversions"
+	"github.com/pingcap/advanced-statefulset/pkg/controller/sharding"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	kubeinformers "k8s.io/client-go/informers"
 	kubefake "k8s.io/client-go/kubernetes/fake"
@@ -572,6 +577,161 @@ func TestGetPodsForStatefulSetRelease(t *testing.T) {
 	}
 }
 
+func TestStatefulSetControllerInformerSelector(t *testing.T) {
+	set := newStatefulSet(3)
+	ssc, spc := newFakeStatefulSetController(set)
+	spc.setsIndexer.Add(set)
+	selector, err := labels.Parse("foo=baz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ssc.informerSelector = selector
+	recorder := record.NewFakeRecorder(10)
+	ssc.recorder = recorder
+	for i := 0; i < 2; i++ {
+		if err := ssc.sync(statefulSetKey(set)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setSelector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pods, err := spc.podsLister.Pods(set.Namespace).List(setSelector); err != nil {
+		t.Fatal(err)
+	} else if len(pods) != 0 {
+		t.Errorf("got %d pods for a set outside of the informer selector, want 0", len(pods))
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("got %d events, want a single warning for the generation of the set", len(recorder.Events))
+	}
+
+	// a new generation of the set is warned about again
+	set = set.DeepCopy()
+	set.Generation++
+	spc.setsIndexer.Update(set)
+	if err := ssc.sync(statefulSetKey(set)); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Events) != 2 {
+		t.Errorf("got %d events, want a warning for the new generation of the set", len(recorder.Events))
+	}
+}
+
+func TestStatefulSetControllerShards(t *testing.T) {
+	set := newStatefulSet(3)
+	ssc, spc := newFakeStatefulSetController(set)
+	spc.setsIndexer.Add(set)
+	shards, err := sharding.NewManager(sharding.Config{
+		Client:        kubefake.NewSimpleClientset().CoordinationV1(),
+		Namespace:     "default",
+		Name:          "controller",
+		Identity:      "replica",
+		Shards:        2,
+		LeaseDuration: 15 * time.Second,
+		RenewDeadline: 10 * time.Second,
+		RetryPeriod:   2 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// no shard is acquired before the manager runs
+	ssc.shards = shards
+	if err := ssc.sync(statefulSetKey(set)); err != nil {
+		t.Fatal(err)
+	}
+	setSelector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pods, err := spc.podsLister.Pods(set.Namespace).List(setSelector); err != nil {
+		t.Fatal(err)
+	} else if len(pods) != 0 {
+		t.Errorf("got %d pods for a set of a shard owned by another replica, want 0", len(pods))
+	}
+}
+
+func TestListPodsForStatefulSet(t *testing.T) {
+	set := newStatefulSet(3)
+	other := newStatefulSet(3)
+	other.Name = "bar"
+	other.UID = "other"
+	ssc, spc := newFakeStatefulSetController(set, other)
+	owned := newStatefulSetPod(set, 0)
+	// orphan has the name of set and may be adopted
+	orphan := newStatefulSetPod(set, 1)
+	orphan.OwnerReferences = nil
+	// renamed is owned by set but has the name of other
+	renamed := newStatefulSetPod(set, 2)
+	renamed.Name = getPodName(other, 2)
+	// the pods of other and its orphans are not listed for set
+	otherOwned := newStatefulSetPod(other, 0)
+	otherOrphan := newStatefulSetPod(other, 1)
+	otherOrphan.OwnerReferences = nil
+	for _, pod := range []*v1.Pod{owned, orphan, renamed, otherOwned, otherOrphan} {
+		spc.podsIndexer.Add(pod)
+	}
+	pods, err := ssc.listPodsForStatefulSet(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := sets.NewString()
+	for _, pod := range pods {
+		got.Insert(pod.Name)
+	}
+	want := sets.NewString(owned.Name, orphan.Name, renamed.Name)
+	if !got.Equal(want) {
+		t.Errorf("listPodsForStatefulSet() = %v, want %v", got, want)
+	}
+}
+
+func BenchmarkGetPodsForStatefulSet(b *testing.B) {
+	const numSets, replicas = 200, 20
+	var objs []runtime.Object
+	var statefulSets []*apps.StatefulSet
+	for i := 0; i < numSets; i++ {
+		set := newStatefulSet(replicas)
+		set.Name = fmt.Sprintf("set-%d", i)
+		set.UID = types.UID(set.Name)
+		set.Spec.Selector.MatchLabels = map[string]string{"set": set.Name}
+		set.Spec.Template.Labels = map[string]string{"set": set.Name}
+		statefulSets = append(statefulSets, set)
+		objs = append(objs, set)
+	}
+	ssc, spc := newFakeStatefulSetController(objs...)
+	for _, set := range statefulSets {
+		for i := 0; i < replicas; i++ {
+			spc.podsIndexer.Add(newStatefulSetPod(set, i))
+		}
+	}
+	set := statefulSets[numSets/2]
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
+	if err != nil {
+		b.Fatal(err)
+	}
+	podIndexer := ssc.podIndexer
+	for _, bm := range []struct {
+		name       string
+		podIndexer cache.Indexer
+	}{
+		{"namespace list", nil},
+		{"indexed", podIndexer},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			ssc.podIndexer = bm.podIndexer
+			for i := 0; i < b.N; i++ {
+				pods, err := ssc.getPodsForStatefulSet(set, selector)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(pods) != replicas {
+					b.Fatalf("got %d pods, want %d", len(pods), replicas)
+				}
+			}
+		})
+	}
+}
+
 func newFakeStatefulSetController(initialObjects ...runtime.Object) (*StatefulSetController, *fakeStatefulPodControl) {
 	coreObjs := []runtime.Object{}
 	stsObjs := []runtime.Object{}
@@ -592,7 +752,12 @@ func newFakeStatefulSetController(initialObjects ...runtime.Object) (*StatefulSe
 		kubeInformerFactory.Core().V1().Pods(),
 		informerFactory.Apps().V1().StatefulSets(),
 		kubeInformerFactory.Core().V1().PersistentVolumeClaims(),
-		kubeInformerFactory.Apps().V1().ControllerRevisions(),
+		kubeInformerFactory.Apps().V1().ControllerRevisions().Informer(),
+		kubeInformerFactory.Policy().V1().PodDisruptionBudgets(),
+		labels.Everything(),
+		nil,
+		DefaultQueueConfig(),
+		false,
 		kubeClient,
 		client,
 	)
@@ -21,13 +21,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 
 	kubeapps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/tools/cache"
 
 	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
 	"github.com/pingcap/advanced-statefulset/client/client/clientset/versioned/scheme"
@@ -155,6 +160,113 @@ func getPersistentVolumeClaims(set *apps.StatefulSet, pod *v1.Pod) map[string]v1
 	return claims
 }
 
+// claimNeedsExpansion returns true if the storage request of claim is smaller than the storage request of template.
+func claimNeedsExpansion(template, claim *v1.PersistentVolumeClaim) bool {
+	want, ok := template.Spec.Resources.Requests[v1.ResourceStorage]
+	if !ok {
+		return false
+	}
+	got := claim.Spec.Resources.Requests[v1.ResourceStorage]
+	return want.Cmp(got) > 0
+}
+
+// hasClaimCondition returns true if claim has a condition of type condType with status true.
+func hasClaimCondition(claim *v1.PersistentVolumeClaim, condType v1.PersistentVolumeClaimConditionType) bool {
+	for _, c := range claim.Status.Conditions {
+		if c.Type == condType && c.Status == v1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// isClaimResizing returns true if claim is bound and its capacity is smaller than its storage request or a resize of
+// claim is in progress.
+func isClaimResizing(claim *v1.PersistentVolumeClaim) bool {
+	// an unbound claim gets a volume of the requested size when it is bound
+	if claim.Status.Phase != v1.ClaimBound {
+		return false
+	}
+	if hasClaimCondition(claim, v1.PersistentVolumeClaimResizing) ||
+		hasClaimCondition(claim, v1.PersistentVolumeClaimFileSystemResizePending) {
+		return true
+	}
+	request := claim.Spec.Resources.Requests[v1.ResourceStorage]
+	capacity := claim.Status.Capacity[v1.ResourceStorage]
+	return request.Cmp(capacity) > 0
+}
+
+// volumeClaimsNeedExpansion returns true if any of claims, which are the claims of pod, needs to be expanded to the
+// storage request of its volumeClaimTemplate.
+func volumeClaimsNeedExpansion(set *apps.StatefulSet, pod *v1.Pod, claims map[string]*v1.PersistentVolumeClaim) bool {
+	templates := getPersistentVolumeClaims(set, pod)
+	for name, claim := range claims {
+		template := templates[name]
+		if claimNeedsExpansion(&template, claim) {
+			return true
+		}
+	}
+	return false
+}
+
+// claimMatchesTemplate returns true if the storage class, access modes and volume mode of claim match the ones of
+// template, and claim requests at least the storage of template. A claim which has been expanded beyond its template
+// matches it, recreating it would lose its data without shrinking it. Fields which are not set in template are not
+// compared.
+func claimMatchesTemplate(template, claim *v1.PersistentVolumeClaim) bool {
+	if template.Spec.StorageClassName != nil &&
+		(claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName != *template.Spec.StorageClassName) {
+		return false
+	}
+	if len(template.Spec.AccessModes) > 0 && !apiequality.Semantic.DeepEqual(template.Spec.AccessModes, claim.Spec.AccessModes) {
+		return false
+	}
+	if template.Spec.VolumeMode != nil &&
+		(claim.Spec.VolumeMode == nil || *claim.Spec.VolumeMode != *template.Spec.VolumeMode) {
+		return false
+	}
+	if want, ok := template.Spec.Resources.Requests[v1.ResourceStorage]; ok {
+		got := claim.Spec.Resources.Requests[v1.ResourceStorage]
+		return want.Cmp(got) <= 0
+	}
+	return true
+}
+
+// volumeClaimsUpdated returns true if all of claims, which are the claims of pod, are updated according to set's
+// VolumeClaimUpdateStrategy. With the Recreate strategy the claims must match set's volumeClaimTemplates, otherwise
+// they must provide the storage requested by set's volumeClaimTemplates.
+func volumeClaimsUpdated(set *apps.StatefulSet, pod *v1.Pod, claims map[string]*v1.PersistentVolumeClaim) bool {
+	if set.Spec.VolumeClaimUpdateStrategy.Type == apps.RecreateVolumeClaimUpdateStrategyType {
+		templates := getPersistentVolumeClaims(set, pod)
+		for name, claim := range claims {
+			template := templates[name]
+			if claim.DeletionTimestamp != nil || !claimMatchesTemplate(&template, claim) {
+				return false
+			}
+		}
+		return true
+	}
+	if volumeClaimsNeedExpansion(set, pod, claims) {
+		return false
+	}
+	for _, claim := range claims {
+		if isClaimResizing(claim) {
+			return false
+		}
+	}
+	return true
+}
+
+// volumeClaimsFileSystemResizePending returns true if any of claims waits for its file system to be resized.
+func volumeClaimsFileSystemResizePending(claims map[string]*v1.PersistentVolumeClaim) bool {
+	for _, claim := range claims {
+		if hasClaimCondition(claim, v1.PersistentVolumeClaimFileSystemResizePending) {
+			return true
+		}
+	}
+	return false
+}
+
 // updateStorage updates pod's Volumes to conform with the PersistentVolumeClaim of set's templates. If pod has
 // conflicting local Volumes these are replaced with Volumes that conform to the set's templates.
 func updateStorage(set *apps.StatefulSet, pod *v1.Pod) {
@@ -234,6 +346,18 @@ func allowsBurst(set *apps.StatefulSet) bool {
 	return set.Spec.PodManagementPolicy == apps.ParallelPodManagement
 }
 
+// getScaleBatchSize returns the maximum number of Pods of set which are created or deleted at once. Zero is returned
+// if the number is not limited.
+func getScaleBatchSize(set *apps.StatefulSet) int {
+	if set.Spec.ScaleBatchSize != nil && *set.Spec.ScaleBatchSize > 0 {
+		return int(*set.Spec.ScaleBatchSize)
+	}
+	if allowsBurst(set) {
+		return 0
+	}
+	return 1
+}
+
 // setPodRevision sets the revision of Pod to revision by adding the StatefulSetRevisionLabel
 func setPodRevision(pod *v1.Pod, revision string) {
 	if pod.Labels == nil {
@@ -253,13 +377,82 @@ func getPodRevision(pod *v1.Pod) string {
 
 // newStatefulSetPod returns a new Pod conforming to the set's Spec with an identity generated from ordinal.
 func newStatefulSetPod(set *apps.StatefulSet, ordinal int) *v1.Pod {
-	pod, _ := k8s.GetPodFromTemplate(&set.Spec.Template, set, metav1.NewControllerRef(set, controllerKind))
+	template, err := getPodTemplate(set, ordinal)
+	if err != nil {
+		// ordinal overrides are validated before Pods are created, this should not happen
+		utilruntime.HandleError(fmt.Errorf("StatefulSet %s/%s: %v", set.Namespace, set.Name, err))
+		template = &set.Spec.Template
+	}
+	pod, _ := k8s.GetPodFromTemplate(template, set, metav1.NewControllerRef(set, controllerKind))
 	pod.Name = getPodName(set, ordinal)
 	initIdentity(set, pod)
 	updateStorage(set, pod)
 	return pod
 }
 
+// overrideContains returns true if ordinal is in the range of override.
+func overrideContains(override *apps.OrdinalOverride, ordinal int) bool {
+	end := override.Start
+	if override.End != nil {
+		end = *override.End
+	}
+	return int(override.Start) <= ordinal && ordinal <= int(end)
+}
+
+// getPodTemplate returns the pod template of the Pod with ordinal in set. It is set's template with the patches of all
+// OrdinalOverrides which contain ordinal applied in order. If the returned error is nil, the returned template is
+// valid.
+func getPodTemplate(set *apps.StatefulSet, ordinal int) (*v1.PodTemplateSpec, error) {
+	template := &set.Spec.Template
+	for i := range set.Spec.OrdinalOverrides {
+		override := &set.Spec.OrdinalOverrides[i]
+		if !overrideContains(override, ordinal) || len(override.Patch.Raw) == 0 {
+			continue
+		}
+		original, err := json.Marshal(template)
+		if err != nil {
+			return nil, err
+		}
+		patched, err := strategicpatch.StrategicMergePatch(original, override.Patch.Raw, v1.PodTemplateSpec{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply ordinalOverrides[%d]: %v", i, err)
+		}
+		template = &v1.PodTemplateSpec{}
+		if err := json.Unmarshal(patched, template); err != nil {
+			return nil, fmt.Errorf("failed to apply ordinalOverrides[%d]: %v", i, err)
+		}
+	}
+	return template, nil
+}
+
+// validateOrdinalOverrides returns an error if the range of one of set's OrdinalOverrides is invalid or if its patch
+// can not be applied to set's template.
+func validateOrdinalOverrides(set *apps.StatefulSet) error {
+	for i := range set.Spec.OrdinalOverrides {
+		override := &set.Spec.OrdinalOverrides[i]
+		if override.Start < 0 || (override.End != nil && *override.End < override.Start) {
+			return fmt.Errorf("ordinalOverrides[%d] has an invalid range", i)
+		}
+		if _, err := getPodTemplate(set, int(override.Start)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// podTemplateUnchanged returns true if the Pod with ordinal has the same pod template in currentSet and updateSet.
+func podTemplateUnchanged(currentSet, updateSet *apps.StatefulSet, ordinal int) bool {
+	current, err := getPodTemplate(currentSet, ordinal)
+	if err != nil {
+		return false
+	}
+	update, err := getPodTemplate(updateSet, ordinal)
+	if err != nil {
+		return false
+	}
+	return apiequality.Semantic.DeepEqual(current, update)
+}
+
 // newVersionedStatefulSetPod creates a new Pod for a StatefulSet. currentSet is the representation of the set at the
 // current revision. updateSet is the representation of the set at the updateRevision. currentRevision is the name of
 // the current revision. updateRevision is the name of the update revision. ordinal is the ordinal of the Pod. If the
@@ -267,7 +460,7 @@ func newStatefulSetPod(set *apps.StatefulSet, ordinal int) *v1.Pod {
 func newVersionedStatefulSetPod(currentSet, updateSet *apps.StatefulSet, currentRevision, updateRevision string, ordinal int) *v1.Pod {
 	if currentSet.Spec.UpdateStrategy.Type == apps.RollingUpdateStatefulSetStrategyType &&
 		(currentSet.Spec.UpdateStrategy.RollingUpdate == nil && ordinal < int(currentSet.Status.CurrentReplicas)) ||
-		(currentSet.Spec.UpdateStrategy.RollingUpdate != nil && ordinal < int(*currentSet.Spec.UpdateStrategy.RollingUpdate.Partition)) {
+		ordinal < int(getPartition(currentSet)) {
 		pod := newStatefulSetPod(currentSet, ordinal)
 		setPodRevision(pod, currentRevision)
 		return pod
@@ -303,6 +496,11 @@ func getPatch(set *apps.StatefulSet) ([]byte, error) {
 	template := spec["template"].(map[string]interface{})
 	specCopy["template"] = template
 	template["$patch"] = "replace"
+	// ordinal overrides are only recorded when they are set, so that the revisions of sets without overrides do not
+	// change
+	if overrides, ok := spec["ordinalOverrides"]; ok {
+		specCopy["ordinalOverrides"] = overrides
+	}
 	objCopy["spec"] = specCopy
 	patch, err := json.Marshal(objCopy)
 	return patch, err
@@ -348,6 +546,16 @@ func ApplyRevision(set *apps.StatefulSet, revision *kubeapps.ControllerRevision)
 	if err != nil {
 		return nil, err
 	}
+	// revisions without ordinal overrides have been recorded for sets without overrides
+	var recorded struct {
+		Spec struct {
+			OrdinalOverrides []apps.OrdinalOverride `json:"ordinalOverrides"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(revision.Data.Raw, &recorded); err != nil {
+		return nil, err
+	}
+	restoredSet.Spec.OrdinalOverrides = recorded.Spec.OrdinalOverrides
 	return restoredSet, nil
 }
 
@@ -370,8 +578,48 @@ func inconsistentStatus(set *apps.StatefulSet, status *apps.StatefulSetStatus) b
 		status.CurrentReplicas != set.Status.CurrentReplicas ||
 		status.ReadyReplicas != set.Status.ReadyReplicas ||
 		status.UpdatedReplicas != set.Status.UpdatedReplicas ||
+		status.UpdatedVolumeClaimReplicas != set.Status.UpdatedVolumeClaimReplicas ||
+		!apiequality.Semantic.DeepEqual(status.FrozenOrdinals, set.Status.FrozenOrdinals) ||
+		!apiequality.Semantic.DeepEqual(status.RecreateBackoffs, set.Status.RecreateBackoffs) ||
+		!apiequality.Semantic.DeepEqual(status.Surges, set.Status.Surges) ||
+		!apiequality.Semantic.DeepEqual(status.BlockedBy, set.Status.BlockedBy) ||
+		!apiequality.Semantic.DeepEqual(status.Pods, set.Status.Pods) ||
+		!apiequality.Semantic.DeepEqual(status.Ordinals, set.Status.Ordinals) ||
+		!apiequality.Semantic.DeepEqual(status.DeleteSlots, set.Status.DeleteSlots) ||
+		!apiequality.Semantic.DeepEqual(status.IgnoredDeleteSlots, set.Status.IgnoredDeleteSlots) ||
 		status.CurrentRevision != set.Status.CurrentRevision ||
-		status.UpdateRevision != set.Status.UpdateRevision
+		status.UpdateRevision != set.Status.UpdateRevision ||
+		!apiequality.Semantic.DeepEqual(status.Conditions, set.Status.Conditions)
+}
+
+// getPodSummaries returns the summaries of pods sorted by ordinal. Pods whose ordinal can not be parsed are omitted.
+func getPodSummaries(pods []*v1.Pod) []apps.StatefulSetPodSummary {
+	var summaries []apps.StatefulSetPodSummary
+	for _, pod := range pods {
+		ord := getOrdinal(pod)
+		if ord < 0 {
+			continue
+		}
+		summaries = append(summaries, apps.StatefulSetPodSummary{
+			Ordinal:     int32(ord),
+			Revision:    getPodRevision(pod),
+			Ready:       isRunningAndReady(pod),
+			Terminating: isTerminating(pod),
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Ordinal < summaries[j].Ordinal
+	})
+	return summaries
+}
+
+// newBlockedBy returns the blockedBy status of a StatefulSet which waits for pod for reason.
+func newBlockedBy(pod *v1.Pod, reason string) *apps.StatefulSetBlockedBy {
+	return &apps.StatefulSetBlockedBy{
+		Pod:     pod.Name,
+		Ordinal: int32(getOrdinal(pod)),
+		Reason:  reason,
+	}
 }
 
 // completeRollingUpdate completes a rolling update when all of set's replica Pods have been updated
@@ -387,6 +635,146 @@ func completeRollingUpdate(set *apps.StatefulSet, status *apps.StatefulSetStatus
 	}
 }
 
+// getPartition returns the ordinal from which Pods of set are updated by a rolling update.
+func getPartition(set *apps.StatefulSet) int32 {
+	if set.Spec.UpdateStrategy.RollingUpdate == nil || set.Spec.UpdateStrategy.RollingUpdate.Partition == nil {
+		return 0
+	}
+	return *set.Spec.UpdateStrategy.RollingUpdate.Partition
+}
+
+// completeRollback marks an in progress rollback as complete once all of set's replica Pods run the restored
+// revision and are ready.
+func completeRollback(status *apps.StatefulSetStatus) {
+	cond := getStatefulSetCondition(*status, apps.StatefulSetRollback)
+	if cond == nil || cond.Status != v1.ConditionTrue {
+		return
+	}
+	if status.CurrentRevision == status.UpdateRevision &&
+		status.UpdatedReplicas == status.Replicas &&
+		status.ReadyReplicas == status.Replicas {
+		setStatefulSetCondition(status, newStatefulSetCondition(apps.StatefulSetRollback, v1.ConditionFalse,
+			rollbackCompleteReason, fmt.Sprintf("Rolled back to revision %s", status.UpdateRevision)))
+	}
+}
+
+// newStatefulSetCondition creates a new statefulset condition.
+func newStatefulSetCondition(condType apps.StatefulSetConditionType, status v1.ConditionStatus, reason, message string) apps.StatefulSetCondition {
+	now := metav1.Now()
+	return apps.StatefulSetCondition{
+		Type:               condType,
+		Status:             status,
+		LastUpdateTime:     now,
+		LastTransitionTime: now,
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+const (
+	// podControllerUIDIndex is the name of the index of pods by the UID of their controller. Pods without a
+	// controller are not indexed.
+	podControllerUIDIndex = "podControllerUID"
+	// orphanPodParentIndex is the name of the index of pods without a controller by their namespace and parent name,
+	// which is the name of the StatefulSet which may adopt them.
+	orphanPodParentIndex = "orphanPodParent"
+)
+
+// addPodIndexers adds the podControllerUIDIndex and orphanPodParentIndex indexes to informer unless they exist. It
+// must be called before informer is started.
+func addPodIndexers(informer cache.SharedIndexInformer) error {
+	indexers := cache.Indexers{}
+	existing := informer.GetIndexer().GetIndexers()
+	if _, ok := existing[podControllerUIDIndex]; !ok {
+		indexers[podControllerUIDIndex] = func(obj interface{}) ([]string, error) {
+			pod, ok := obj.(*v1.Pod)
+			if !ok {
+				return nil, nil
+			}
+			if controllerRef := metav1.GetControllerOf(pod); controllerRef != nil {
+				return []string{string(controllerRef.UID)}, nil
+			}
+			return nil, nil
+		}
+	}
+	if _, ok := existing[orphanPodParentIndex]; !ok {
+		indexers[orphanPodParentIndex] = func(obj interface{}) ([]string, error) {
+			pod, ok := obj.(*v1.Pod)
+			if !ok || metav1.GetControllerOf(pod) != nil {
+				return nil, nil
+			}
+			if parent := getParentName(pod); parent != "" {
+				return []string{pod.Namespace + "/" + parent}, nil
+			}
+			return nil, nil
+		}
+	}
+	if len(indexers) == 0 {
+		return nil
+	}
+	return informer.AddIndexers(indexers)
+}
+
+// informerSelectorMatches returns true if the Pods and PersistentVolumeClaims of set are labeled so that they are
+// seen by informers restricted to selector. A nil selector matches all sets.
+func informerSelectorMatches(set *apps.StatefulSet, selector labels.Selector) bool {
+	if selector == nil || selector.Empty() {
+		return true
+	}
+	if !selector.Matches(labels.Set(set.Spec.Template.Labels)) {
+		return false
+	}
+	for i := range set.Spec.VolumeClaimTemplates {
+		if !selector.Matches(labels.Merge(set.Spec.VolumeClaimTemplates[i].Labels, set.Spec.Selector.MatchLabels)) {
+			return false
+		}
+	}
+	return true
+}
+
+// statefulSetKey returns the namespace/name key of set, which is also the key of set in the queue of the controller.
+func statefulSetKey(set *apps.StatefulSet) string {
+	return set.Namespace + "/" + set.Name
+}
+
+// getStatefulSetCondition returns the condition with the provided type.
+func getStatefulSetCondition(status apps.StatefulSetStatus, condType apps.StatefulSetConditionType) *apps.StatefulSetCondition {
+	for i := range status.Conditions {
+		c := status.Conditions[i]
+		if c.Type == condType {
+			return &c
+		}
+	}
+	return nil
+}
+
+// setStatefulSetCondition updates the statefulset status to include the provided condition. If the condition that
+// we are about to add already exists and has the same status and reason then we are not going to update.
+func setStatefulSetCondition(status *apps.StatefulSetStatus, condition apps.StatefulSetCondition) {
+	currentCond := getStatefulSetCondition(*status, condition.Type)
+	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason {
+		return
+	}
+	// Do not update lastTransitionTime if the status of the condition doesn't change.
+	if currentCond != nil && currentCond.Status == condition.Status {
+		condition.LastTransitionTime = currentCond.LastTransitionTime
+	}
+	newConditions := filterOutCondition(status.Conditions, condition.Type)
+	status.Conditions = append(newConditions, condition)
+}
+
+// filterOutCondition returns a new slice of statefulset conditions without conditions with the provided type.
+func filterOutCondition(conditions []apps.StatefulSetCondition, condType apps.StatefulSetConditionType) []apps.StatefulSetCondition {
+	var newConditions []apps.StatefulSetCondition
+	for _, c := range conditions {
+		if c.Type == condType {
+			continue
+		}
+		newConditions = append(newConditions, c)
+	}
+	return newConditions
+}
+
 // ascendingOrdinal is a sort.Interface that Sorts a list of Pods based on the ordinals extracted
 // from the Pod. Pod's that have not been constructed by StatefulSet's have an ordinal of -1, and are therefore pushed
 // to the front of the list.
@@ -22,13 +22,17 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	utilpointer "k8s.io/utils/pointer"
 
 	apps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
 	k8s "github.com/pingcap/advanced-statefulset/pkg/third_party/k8s"
@@ -321,6 +325,99 @@ func TestRollingUpdateApplyRevision(t *testing.T) {
 	}
 }
 
+func TestOrdinalOverridesApplyRevision(t *testing.T) {
+	set := newStatefulSet(3)
+	set.Status.CollisionCount = new(int32)
+	currentRevision, err := newRevision(set, 1, set.Status.CollisionCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(currentRevision.Data.Raw), "ordinalOverrides") {
+		t.Errorf("revision of a set without overrides should not record overrides: %s", currentRevision.Data.Raw)
+	}
+
+	set.Spec.OrdinalOverrides = []apps.OrdinalOverride{{
+		Start: 1,
+		Patch: runtime.RawExtension{Raw: []byte(`{"metadata":{"labels":{"role":"leader"}}}`)},
+	}}
+	updateRevision, err := newRevision(set, 2, set.Status.CollisionCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k8s.EqualRevision(currentRevision, updateRevision) {
+		t.Errorf("changing ordinal overrides should change the revision")
+	}
+
+	restoredCurrentSet, err := ApplyRevision(set, currentRevision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restoredCurrentSet.Spec.OrdinalOverrides) != 0 {
+		t.Errorf("got %d ordinal overrides for the current revision, want 0", len(restoredCurrentSet.Spec.OrdinalOverrides))
+	}
+	restoredUpdateSet, err := ApplyRevision(restoredCurrentSet, updateRevision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restoredUpdateSet.Spec.OrdinalOverrides, set.Spec.OrdinalOverrides) {
+		t.Errorf("got ordinal overrides %v, want %v", restoredUpdateSet.Spec.OrdinalOverrides, set.Spec.OrdinalOverrides)
+	}
+}
+
+func TestNewStatefulSetPodOrdinalOverrides(t *testing.T) {
+	set := newStatefulSet(4)
+	set.Spec.OrdinalOverrides = []apps.OrdinalOverride{
+		{
+			Start: 1,
+			End:   utilpointer.Int32Ptr(2),
+			Patch: runtime.RawExtension{Raw: []byte(`{"spec":{"containers":[{"name":"nginx","image":"nginx:debug"}]}}`)},
+		},
+		{
+			Start: 2,
+			Patch: runtime.RawExtension{Raw: []byte(`{"metadata":{"labels":{"role":"leader"}}}`)},
+		},
+	}
+	if err := validateOrdinalOverrides(set); err != nil {
+		t.Fatal(err)
+	}
+	for ord, want := range []struct {
+		image string
+		role  string
+	}{
+		{image: "nginx"},
+		{image: "nginx:debug"},
+		{image: "nginx:debug", role: "leader"},
+		{image: "nginx"},
+	} {
+		pod := newStatefulSetPod(set, ord)
+		if image := pod.Spec.Containers[0].Image; image != want.image {
+			t.Errorf("pod %d: got image %q, want %q", ord, image, want.image)
+		}
+		if role := pod.Labels["role"]; role != want.role {
+			t.Errorf("pod %d: got role %q, want %q", ord, role, want.role)
+		}
+		if !identityMatches(set, pod) {
+			t.Errorf("pod %d: identity should match", ord)
+		}
+	}
+
+	set.Spec.OrdinalOverrides = append(set.Spec.OrdinalOverrides, apps.OrdinalOverride{
+		Start: 3,
+		End:   utilpointer.Int32Ptr(2),
+		Patch: runtime.RawExtension{Raw: []byte(`{}`)},
+	})
+	if err := validateOrdinalOverrides(set); err == nil {
+		t.Errorf("an override with an invalid range should be rejected")
+	}
+	set.Spec.OrdinalOverrides[2] = apps.OrdinalOverride{
+		Start: 3,
+		Patch: runtime.RawExtension{Raw: []byte(`{"spec":{"containers":"invalid"}}`)},
+	}
+	if err := validateOrdinalOverrides(set); err == nil {
+		t.Errorf("an override with an invalid patch should be rejected")
+	}
+}
+
 func TestGetPersistentVolumeClaims(t *testing.T) {
 
 	// nil inherits statefulset labels
@@ -383,6 +480,67 @@ func newPod() *v1.Pod {
 	}
 }
 
+func TestClaimMatchesTemplate(t *testing.T) {
+	fast, slow := "fast", "slow"
+	block := v1.PersistentVolumeBlock
+	newClaim := func(size string, mutate func(claim *v1.PersistentVolumeClaim)) *v1.PersistentVolumeClaim {
+		claim := newPVC("datadir")
+		claim.Spec.StorageClassName = &fast
+		claim.Spec.AccessModes = []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce}
+		claim.Spec.Resources.Requests[v1.ResourceStorage] = resource.MustParse(size)
+		if mutate != nil {
+			mutate(&claim)
+		}
+		return &claim
+	}
+	template := newClaim("10Gi", nil)
+	tests := []struct {
+		name  string
+		claim *v1.PersistentVolumeClaim
+		want  bool
+	}{
+		{
+			name:  "same claim",
+			claim: newClaim("10Gi", nil),
+			want:  true,
+		},
+		{
+			name:  "claim expanded beyond the template",
+			claim: newClaim("20Gi", nil),
+			want:  true,
+		},
+		{
+			name:  "claim smaller than the template",
+			claim: newClaim("5Gi", nil),
+			want:  false,
+		},
+		{
+			name:  "storage class mismatch",
+			claim: newClaim("10Gi", func(claim *v1.PersistentVolumeClaim) { claim.Spec.StorageClassName = &slow }),
+			want:  false,
+		},
+		{
+			name: "access modes mismatch",
+			claim: newClaim("10Gi", func(claim *v1.PersistentVolumeClaim) {
+				claim.Spec.AccessModes = []v1.PersistentVolumeAccessMode{v1.ReadWriteMany}
+			}),
+			want: false,
+		},
+		{
+			name:  "volume mode not set in the template",
+			claim: newClaim("10Gi", func(claim *v1.PersistentVolumeClaim) { claim.Spec.VolumeMode = &block }),
+			want:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := claimMatchesTemplate(template, test.claim); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
 func newPVC(name string) v1.PersistentVolumeClaim {
 	return v1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
@@ -468,3 +626,61 @@ func newStatefulSet(replicas int) *apps.StatefulSet {
 	}
 	return newStatefulSetWithVolumes(replicas, "foo", petMounts, podMounts)
 }
+
+func TestInformerSelectorMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector string
+		mutate   func(set *apps.StatefulSet)
+		want     bool
+	}{
+		{
+			name: "everything",
+			want: true,
+		},
+		{
+			name:     "selector labels",
+			selector: "foo=bar",
+			want:     true,
+		},
+		{
+			name:     "other labels",
+			selector: "foo=baz",
+			want:     false,
+		},
+		{
+			name:     "pod labels only",
+			selector: "tier=db",
+			mutate: func(set *apps.StatefulSet) {
+				set.Spec.Template.Labels["tier"] = "db"
+			},
+			want: false,
+		},
+		{
+			name:     "pod and claim labels",
+			selector: "tier=db",
+			mutate: func(set *apps.StatefulSet) {
+				set.Spec.Template.Labels["tier"] = "db"
+				for i := range set.Spec.VolumeClaimTemplates {
+					set.Spec.VolumeClaimTemplates[i].Labels = map[string]string{"tier": "db"}
+				}
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := newStatefulSet(3)
+			if tt.mutate != nil {
+				tt.mutate(set)
+			}
+			selector, err := labels.Parse(tt.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := informerSelectorMatches(set, selector); got != tt.want {
+				t.Errorf("informerSelectorMatches(%q) = %v, want %v", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
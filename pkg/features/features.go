@@ -0,0 +1,54 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package features defines the feature gates of the advanced behaviors of the controller.
+package features
+
+import (
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/component-base/featuregate"
+)
+
+const (
+	// AutomaticRollback rolls back a StatefulSet whose rolling update exceeded its progress deadline when
+	// spec.autoRollback is set.
+	AutomaticRollback featuregate.Feature = "AutomaticRollback"
+
+	// VolumeClaimUpdates expands or recreates the PersistentVolumeClaims of the Pods of a StatefulSet according
+	// to spec.volumeClaimUpdateStrategy.
+	VolumeClaimUpdates featuregate.Feature = "VolumeClaimUpdates"
+
+	// ManagedPodDisruptionBudget creates and updates a PodDisruptionBudget for a StatefulSet with
+	// spec.podDisruptionBudget.
+	ManagedPodDisruptionBudget featuregate.Feature = "ManagedPodDisruptionBudget"
+
+	// PodEviction deletes Pods with the Eviction API when spec.podDeletionPolicy is Evict.
+	PodEviction featuregate.Feature = "PodEviction"
+)
+
+// DefaultMutableFeatureGate is the feature gate of the controller, set from the configuration.
+var DefaultMutableFeatureGate featuregate.MutableFeatureGate = featuregate.NewFeatureGate()
+
+// DefaultFeatureGate is the read-only view of DefaultMutableFeatureGate checked by the controller.
+var DefaultFeatureGate featuregate.FeatureGate = DefaultMutableFeatureGate
+
+var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
+	AutomaticRollback:          {Default: true, PreRelease: featuregate.Beta},
+	VolumeClaimUpdates:         {Default: true, PreRelease: featuregate.Beta},
+	ManagedPodDisruptionBudget: {Default: true, PreRelease: featuregate.Beta},
+	PodEviction:                {Default: true, PreRelease: featuregate.Beta},
+}
+
+func init() {
+	utilruntime.Must(DefaultMutableFeatureGate.Add(defaultFeatureGates))
+}
@@ -199,7 +199,12 @@ func scSetup(t *testing.T) (CloseFunc, *statefulset.StatefulSetController, infor
 		informers.Core().V1().Pods(),
 		pcinformers.Apps().V1().StatefulSets(),
 		informers.Core().V1().PersistentVolumeClaims(),
-		informers.Apps().V1().ControllerRevisions(),
+		informers.Apps().V1().ControllerRevisions().Informer(),
+		informers.Policy().V1().PodDisruptionBudgets(),
+		labels.Everything(),
+		nil,
+		statefulset.DefaultQueueConfig(),
+		false,
 		clientset.NewForConfigOrDie(restclient.AddUserAgent(&config, "statefulset-controller")),
 		pcclientset.NewForConfigOrDie(restclient.AddUserAgent(&pcConfig, "statefulset-controller")),
 	)